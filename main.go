@@ -2,50 +2,109 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"gnostream/src/cli"
 	"gnostream/src/config"
+	"gnostream/src/ffmpeg"
+	"gnostream/src/logging"
+	"gnostream/src/nostr"
 	"gnostream/src/rtmp"
 	"gnostream/src/stream"
 	"gnostream/src/web"
 )
 
 func main() {
+	// --config (or $GNOSTREAM_CONFIG) selects the config file for every mode
+	configPath, args, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	// Check if this is a CLI command (anything other than server mode)
-	if len(os.Args) > 1 && os.Args[1] != "server" {
+	if len(args) > 0 && args[0] != "server" && !isServerFlag(args[0]) {
 		// Run CLI mode
-		cli := cli.NewCLI()
-		if err := cli.Run(); err != nil {
+		cli := cli.NewCLI(configPath)
+		if err := cli.Run(args); err != nil {
 			log.Fatalf("CLI error: %v", err)
 		}
 		return
 	}
 
 	// Default to server mode (or explicit "server" command)
+	serverArgs := args
+	if len(serverArgs) > 0 && serverArgs[0] == "server" {
+		serverArgs = serverArgs[1:]
+	}
+	serverFlags := flag.NewFlagSet("server", flag.ExitOnError)
+	profile := serverFlags.String("profile", "", "stream info profile to use (stream-info.<name>.yml)")
+	serverFlags.StringVar(&configPath, "config", configPath, "config file to use (default: $GNOSTREAM_CONFIG, then config.yml)")
+	serverFlags.Parse(serverArgs)
+
 	log.Println("🎬 Starting Live Streaming Server...")
 
 	// Load configuration
-	cfg, err := config.Load("config.yml")
+	log.Printf("⚙️ Using config %s", configPath)
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// --profile overrides the profile saved in config.yml for this run
+	if *profile != "" {
+		if err := cfg.UseProfile(*profile); err != nil {
+			log.Fatalf("Failed to select profile: %v", err)
+		}
+	}
+	log.Printf("🗂️ Using profile %q (%s)", cfg.ActiveProfile(), cfg.StreamInfoPath)
+
+	// Configure leveled logging
+	if err := logging.Setup(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		log.Printf("⚠️ Invalid logging config, using defaults: %v", err)
+	}
+
 	log.Printf("Server will run on %s:%d", cfg.Server.Host, cfg.Server.Port)
 
+	// Verify the configured FFmpeg/ffprobe binaries are usable - nothing streams without them
+	ffmpegVersion, ffprobeVersion, err := checkMediaTools(cfg)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		log.Printf("💡 Install FFmpeg: %s", ffmpeg.InstallHint())
+		log.Printf("💡 Or point ffmpeg.binary / ffprobe.binary in %s at an existing build", configPath)
+		os.Exit(1)
+	}
+	log.Printf("🎞️ FFmpeg %s, ffprobe %s", ffmpegVersion, ffprobeVersion)
+
 	// Ensure required directories exist
 	if err := ensureDirectories(cfg); err != nil {
 		log.Fatalf("Failed to create required directories: %v", err)
 	}
 
+	// Encode the offline placeholder in the background; the live URL serves it once ready
+	if cfg.Stream.Offline.Enabled() {
+		go func() {
+			if err := stream.GenerateOfflinePlaceholder(cfg); err != nil {
+				log.Printf("⚠️ Offline placeholder unavailable: %v", err)
+			}
+		}()
+	}
+
+	// Initialize the Nostr client the stream monitor broadcasts through
+	nostrClient, err := nostr.NewClient(&cfg.Nostr)
+	if err != nil {
+		log.Fatalf("Failed to initialize nostr client: %v", err)
+	}
+
 	// Initialize stream monitor
-	monitor, err := stream.NewMonitor(cfg)
+	monitor, err := stream.NewMonitor(cfg, nostrClient)
 	if err != nil {
 		log.Fatalf("Failed to initialize stream monitor: %v", err)
 	}
@@ -72,6 +131,7 @@ func main() {
 			monitor.HandleStreamStart, // Called when stream starts
 			monitor.HandleStreamStop,  // Called when stream stops
 		)
+		monitor.SetStreamStopper(rtmpServer.StopStream)
 
 		// Start RTMP server
 		go func() {
@@ -83,7 +143,7 @@ func main() {
 	}
 
 	// Initialize web server
-	webServer := web.NewServer(cfg, monitor)
+	webServer := web.NewServer(cfg, monitor, rtmpServer)
 
 	// Setup HTTP server
 	server := &http.Server{
@@ -109,6 +169,14 @@ func main() {
 
 	log.Println("🛑 Shutting down server...")
 
+	// Drain in order: let FFmpeg finish its output, end the live stream on Nostr
+	// (and archive it), then disconnect viewers before the HTTP server goes away
+	if rtmpServer != nil {
+		rtmpServer.Stop()
+	}
+	monitor.Shutdown(15 * time.Second)
+	webServer.Shutdown()
+
 	// Cancel monitor context
 	cancel()
 
@@ -123,6 +191,35 @@ func main() {
 	log.Println("✅ Server gracefully stopped")
 }
 
+// isServerFlag reports whether an argument is a server-mode flag given without the "server" command
+func isServerFlag(arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	if name == arg {
+		return false
+	}
+	for _, serverFlag := range []string{"profile", "config"} {
+		if name == serverFlag || strings.HasPrefix(name, serverFlag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMediaTools verifies the configured ffmpeg and ffprobe binaries and returns their versions
+func checkMediaTools(cfg *config.Config) (string, string, error) {
+	ffmpegVersion, err := ffmpeg.Check(cfg.FFmpegBinary())
+	if err != nil {
+		return "", "", err
+	}
+
+	ffprobeVersion, err := ffmpeg.Check(cfg.FFprobeBinary())
+	if err != nil {
+		return "", "", err
+	}
+
+	return ffmpegVersion, ffprobeVersion, nil
+}
+
 // ensureDirectories creates required directories if they don't exist
 func ensureDirectories(cfg *config.Config) error {
 	streamDefaults := cfg.GetStreamDefaults()
@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BlockedIP is one denylist entry
+type BlockedIP struct {
+	IP        string    `json:"ip"`
+	SessionID string    `json:"session_id,omitempty"` // Session the block was issued from
+	UserAgent string    `json:"user_agent,omitempty"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// IPBlocklist is a denylist of viewer IPs refused HLS content. HLS is stateless, so a viewer
+// can't be disconnected; blocking their IP makes every further playlist and segment request fail.
+// With a path the list is saved as JSON and survives restarts.
+type IPBlocklist struct {
+	path    string
+	entries map[string]BlockedIP
+	mutex   sync.RWMutex
+}
+
+// NewIPBlocklist creates a denylist, loading path if it exists. An empty path keeps it in memory.
+func NewIPBlocklist(path string) (*IPBlocklist, error) {
+	bl := &IPBlocklist{path: path, entries: make(map[string]BlockedIP)}
+	if path == "" {
+		return bl, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return bl, nil
+	}
+	if err != nil {
+		return bl, fmt.Errorf("failed to read viewer blocklist: %w", err)
+	}
+
+	var entries []BlockedIP
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return bl, fmt.Errorf("failed to parse viewer blocklist %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		bl.entries[entry.IP] = entry
+	}
+	return bl, nil
+}
+
+// Block adds a session's IP to the denylist. It returns false if the IP was already blocked.
+func (bl *IPBlocklist) Block(session ViewerSession) (bool, error) {
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+
+	if _, exists := bl.entries[session.IPAddress]; exists {
+		return false, nil
+	}
+	bl.entries[session.IPAddress] = BlockedIP{
+		IP:        session.IPAddress,
+		SessionID: session.ID,
+		UserAgent: session.UserAgent,
+		BlockedAt: time.Now(),
+	}
+	return true, bl.saveLocked()
+}
+
+// Unblock removes an IP from the denylist. It returns false if the IP wasn't blocked.
+func (bl *IPBlocklist) Unblock(ip string) (bool, error) {
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+
+	if _, exists := bl.entries[ip]; !exists {
+		return false, nil
+	}
+	delete(bl.entries, ip)
+	return true, bl.saveLocked()
+}
+
+// IsBlocked reports whether an IP is on the denylist
+func (bl *IPBlocklist) IsBlocked(ip string) bool {
+	bl.mutex.RLock()
+	defer bl.mutex.RUnlock()
+	_, blocked := bl.entries[ip]
+	return blocked
+}
+
+// List returns the denylist, oldest block first
+func (bl *IPBlocklist) List() []BlockedIP {
+	bl.mutex.RLock()
+	defer bl.mutex.RUnlock()
+
+	entries := make([]BlockedIP, 0, len(bl.entries))
+	for _, entry := range bl.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].BlockedAt.Before(entries[j].BlockedAt) })
+	return entries
+}
+
+// saveLocked writes the denylist to its file, if it has one
+func (bl *IPBlocklist) saveLocked() error {
+	if bl.path == "" {
+		return nil
+	}
+
+	entries := make([]BlockedIP, 0, len(bl.entries))
+	for _, entry := range bl.entries {
+		entries = append(entries, entry)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode viewer blocklist: %w", err)
+	}
+	if err := os.WriteFile(bl.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save viewer blocklist: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIP returns the IP a request came from. X-Forwarded-For and X-Real-IP can be set by
+// anyone, so they are only believed when the connection itself comes from a trusted proxy;
+// otherwise, and without trusted proxies, the connection's address is used.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote := remoteIP(r.RemoteAddr)
+	if !isTrustedProxy(remote, trustedProxies) {
+		return remote
+	}
+
+	// Walk X-Forwarded-For back from the proxy: the first address not itself a trusted proxy is
+	// the client, as anything further left was supplied by the client
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if i == 0 || !isTrustedProxy(hop, trustedProxies) {
+				if hop != "" {
+					return hop
+				}
+				break
+			}
+		}
+	}
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); xri != "" {
+		return xri
+	}
+	return remote
+}
+
+// remoteIP strips the port from a connection's address
+func remoteIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// isTrustedProxy reports whether ip is inside one of the trusted proxy ranges
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	if len(trustedProxies) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(strings.Trim(ip, "[]"))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("::1/128"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		xRealIP    string
+		trusted    []netip.Prefix
+		want       string
+	}{
+		{name: "no proxies configured", remoteAddr: "203.0.113.7:5000", xff: "198.51.100.1", want: "203.0.113.7"},
+		{name: "spoofed header from untrusted peer", remoteAddr: "203.0.113.7:5000", xff: "10.1.1.1", xRealIP: "10.1.1.1", trusted: proxies, want: "203.0.113.7"},
+		{name: "trusted proxy forwards client", remoteAddr: "10.0.0.2:5000", xff: "198.51.100.1", trusted: proxies, want: "198.51.100.1"},
+		{name: "client-supplied hops are skipped", remoteAddr: "10.0.0.2:5000", xff: "1.2.3.4, 198.51.100.1", trusted: proxies, want: "198.51.100.1"},
+		{name: "chain of trusted proxies", remoteAddr: "10.0.0.2:5000", xff: "198.51.100.1, 10.0.0.3, 10.0.0.4", trusted: proxies, want: "198.51.100.1"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.2:5000", xff: "10.0.0.3, 10.0.0.4", trusted: proxies, want: "10.0.0.3"},
+		{name: "X-Real-IP from trusted proxy", remoteAddr: "10.0.0.2:5000", xRealIP: "198.51.100.9", trusted: proxies, want: "198.51.100.9"},
+		{name: "trusted proxy without headers", remoteAddr: "10.0.0.2:5000", trusted: proxies, want: "10.0.0.2"},
+		{name: "IPv6 trusted proxy", remoteAddr: "[::1]:5000", xff: "2001:db8::1", trusted: proxies, want: "2001:db8::1"},
+		{name: "IPv6 untrusted peer", remoteAddr: "[2001:db8::2]:5000", xff: "198.51.100.1", trusted: proxies, want: "2001:db8::2"},
+		{name: "IPv4-mapped trusted proxy", remoteAddr: "[::ffff:10.0.0.2]:5000", xff: "198.51.100.1", trusted: proxies, want: "198.51.100.1"},
+		{name: "remote address without port", remoteAddr: "203.0.113.7", want: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/live/output.m3u8", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+
+			if got := ClientIP(req, tt.trusted); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -20,6 +21,8 @@ type ViewerSession struct {
 	RequestCount  int       `json:"request_count"`
 	PlaylistReqs  int       `json:"playlist_requests"`
 	SegmentReqs   int       `json:"segment_requests"`
+	SegmentBytes  int64     `json:"segment_bytes"` // Bytes of segments served, for bandwidth
+	LastPlaylist  time.Time `json:"last_playlist"` // Last live playlist fetch, used for presence
 	IsActive      bool      `json:"is_active"`
 }
 
@@ -38,15 +41,18 @@ type ViewerTracker struct {
 	sessions       map[string]*ViewerSession
 	metrics        ViewerMetrics
 	mutex          sync.RWMutex
-	sessionTimeout time.Duration
+	presenceWindow time.Duration // A viewer is present if they fetched the live playlist within this window
 	cleanupTicker  *time.Ticker
+	trustedProxies []netip.Prefix // Proxies whose forwarded client IP is believed, as in the blocklist check
 }
 
-// NewViewerTracker creates a new viewer tracker
-func NewViewerTracker() *ViewerTracker {
+// NewViewerTracker creates a new viewer tracker. Client IPs are read with ClientIP, so a
+// session's IP is the one the HLS blocklist is checked against.
+func NewViewerTracker(trustedProxies []netip.Prefix) *ViewerTracker {
 	tracker := &ViewerTracker{
 		sessions:       make(map[string]*ViewerSession),
-		sessionTimeout: 30 * time.Second, // Consider inactive after 30s
+		trustedProxies: trustedProxies,
+		presenceWindow: 15 * time.Second, // hls.js refreshes the live playlist every target duration
 		cleanupTicker:  time.NewTicker(10 * time.Second),
 	}
 
@@ -56,9 +62,10 @@ func NewViewerTracker() *ViewerTracker {
 	return tracker
 }
 
-// generateSessionID creates a unique session ID from IP and User-Agent
+// generateSessionID creates a unique session ID from IP and User-Agent.
+// No time bucket is mixed in, so a reconnecting viewer keeps the same session instead of counting twice.
 func (vt *ViewerTracker) generateSessionID(ip, userAgent string) string {
-	hash := sha256.Sum256([]byte(ip + "|" + userAgent + "|" + fmt.Sprint(time.Now().Unix()/300))) // 5-min buckets
+	hash := sha256.Sum256([]byte(ip + "|" + userAgent))
 	return fmt.Sprintf("%x", hash[:8]) // Use first 8 bytes for shorter ID
 }
 
@@ -68,7 +75,7 @@ func (vt *ViewerTracker) TrackRequest(r *http.Request) {
 	defer vt.mutex.Unlock()
 
 	// Extract client info
-	ip := vt.getClientIP(r)
+	ip := ClientIP(r, vt.trustedProxies)
 	userAgent := r.UserAgent()
 	
 	// Generate session ID
@@ -96,7 +103,10 @@ func (vt *ViewerTracker) TrackRequest(r *http.Request) {
 	path := strings.ToLower(r.URL.Path)
 	if strings.HasSuffix(path, ".m3u8") {
 		session.PlaylistReqs++
-	} else if strings.HasSuffix(path, ".ts") || strings.HasSuffix(path, ".mp4") {
+		if IsLivePlaylist(path) {
+			session.LastPlaylist = session.LastSeen
+		}
+	} else if isSegment(path) {
 		session.SegmentReqs++
 	}
 
@@ -104,22 +114,24 @@ func (vt *ViewerTracker) TrackRequest(r *http.Request) {
 	vt.updateMetrics()
 }
 
-// getClientIP extracts the real client IP
-func (vt *ViewerTracker) getClientIP(r *http.Request) string {
-	// Check for forwarded IP headers
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
+// TrackSegmentBytes adds the bytes served for a segment request to its viewer's session
+func (vt *ViewerTracker) TrackSegmentBytes(r *http.Request, bytes int64) {
+	if bytes <= 0 || !isSegment(strings.ToLower(r.URL.Path)) {
+		return
 	}
-	// Fall back to remote address
-	ip := r.RemoteAddr
-	if colon := strings.LastIndex(ip, ":"); colon != -1 {
-		ip = ip[:colon]
+
+	vt.mutex.Lock()
+	defer vt.mutex.Unlock()
+
+	sessionID := vt.generateSessionID(ClientIP(r, vt.trustedProxies), r.UserAgent())
+	if session, exists := vt.sessions[sessionID]; exists {
+		session.SegmentBytes += bytes
 	}
-	return ip
+}
+
+// isSegment checks if a lowercased request path is a media segment
+func isSegment(path string) bool {
+	return strings.HasSuffix(path, ".ts") || strings.HasSuffix(path, ".m4s") || strings.HasSuffix(path, ".mp4")
 }
 
 // updateMetrics recalculates current metrics
@@ -128,12 +140,11 @@ func (vt *ViewerTracker) updateMetrics() {
 	activeCount := 0
 	totalCount := len(vt.sessions)
 
-	// Count active sessions
+	// Count present viewers by playlist cadence
 	for _, session := range vt.sessions {
-		if now.Sub(session.LastSeen) <= vt.sessionTimeout {
+		session.IsActive = IsPresent(session, now, vt.presenceWindow)
+		if session.IsActive {
 			activeCount++
-		} else {
-			session.IsActive = false
 		}
 	}
 
@@ -155,8 +166,9 @@ func (vt *ViewerTracker) updateMetrics() {
 
 // GetMetrics returns current viewer metrics
 func (vt *ViewerTracker) GetMetrics() ViewerMetrics {
-	vt.mutex.RLock()
-	defer vt.mutex.RUnlock()
+	// updateMetrics writes the sessions and metrics, so a read lock isn't enough
+	vt.mutex.Lock()
+	defer vt.mutex.Unlock()
 	
 	// Update active status before returning
 	vt.updateMetrics()
@@ -173,7 +185,7 @@ func (vt *ViewerTracker) GetActiveViewerCount() int {
 	activeCount := 0
 	
 	for _, session := range vt.sessions {
-		if now.Sub(session.LastSeen) <= vt.sessionTimeout {
+		if IsPresent(session, now, vt.presenceWindow) {
 			activeCount++
 		}
 	}
@@ -212,12 +224,30 @@ func (vt *ViewerTracker) ResetMetrics() {
 	vt.metrics.PeakViewers = vt.metrics.ActiveViewers
 }
 
+// IsPresent reports whether a session is a concurrent viewer: they must have fetched the
+// live playlist within the window. Segment requests alone (e.g. a paused or archive player
+// draining its buffer) don't count as presence.
+func IsPresent(session *ViewerSession, now time.Time, window time.Duration) bool {
+	if session.LastPlaylist.IsZero() {
+		return false
+	}
+	return now.Sub(session.LastPlaylist) <= window
+}
+
+// IsLivePlaylist checks if a request path is the live HLS playlist rather than an archived one
+func IsLivePlaylist(path string) bool {
+	path = strings.ToLower(path)
+	return strings.HasPrefix(path, "/live/") &&
+		strings.HasSuffix(path, ".m3u8") &&
+		!strings.Contains(path, "/archive/")
+}
+
 // IsHLSRequest checks if the request is for HLS content
 func IsHLSRequest(r *http.Request) bool {
 	path := strings.ToLower(r.URL.Path)
 	ext := filepath.Ext(path)
 	
-	return ext == ".m3u8" || ext == ".ts" || ext == ".mp4"
+	return ext == ".m3u8" || ext == ".ts" || ext == ".m4s" || ext == ".mp4"
 }
 
 // Stop stops the viewer tracker
@@ -225,4 +255,17 @@ func (vt *ViewerTracker) Stop() {
 	if vt.cleanupTicker != nil {
 		vt.cleanupTicker.Stop()
 	}
-}
\ No newline at end of file
+}
+// GetSession returns a copy of one viewer session
+func (vt *ViewerTracker) GetSession(id string) (ViewerSession, bool) {
+	vt.mutex.RLock()
+	defer vt.mutex.RUnlock()
+
+	session, exists := vt.sessions[id]
+	if !exists {
+		return ViewerSession{}, false
+	}
+	copied := *session
+	copied.IsActive = IsPresent(session, time.Now(), vt.presenceWindow)
+	return copied, true
+}
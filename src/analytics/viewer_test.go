@@ -0,0 +1,207 @@
+package analytics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const hlsjsUserAgent = "Mozilla/5.0 (X11; Linux x86_64) hls.js"
+
+// newTestTracker returns a tracker without the cleanup routine
+func newTestTracker() *ViewerTracker {
+	return &ViewerTracker{
+		sessions:       make(map[string]*ViewerSession),
+		presenceWindow: 15 * time.Second,
+	}
+}
+
+// hlsRequest builds a request as hls.js would send it from the given address
+func hlsRequest(remoteAddr, path string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("User-Agent", hlsjsUserAgent)
+	return req
+}
+
+// backdatePlaylist moves every session's last live playlist fetch into the past
+func backdatePlaylist(vt *ViewerTracker, by time.Duration) {
+	vt.mutex.Lock()
+	defer vt.mutex.Unlock()
+	for _, session := range vt.sessions {
+		if !session.LastPlaylist.IsZero() {
+			session.LastPlaylist = session.LastPlaylist.Add(-by)
+		}
+	}
+}
+
+func TestPollingViewerCountsOnce(t *testing.T) {
+	vt := newTestTracker()
+
+	// hls.js refreshes the playlist every target duration and fetches each new segment
+	for i := 0; i < 10; i++ {
+		vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/output.m3u8"))
+		vt.TrackRequest(hlsRequest("203.0.113.7:50000", fmt.Sprintf("/live/segment%03d.ts", i)))
+	}
+
+	metrics := vt.GetMetrics()
+	if metrics.ActiveViewers != 1 || metrics.TotalViewers != 1 {
+		t.Fatalf("active/total viewers = %d/%d, want 1/1", metrics.ActiveViewers, metrics.TotalViewers)
+	}
+	session := metrics.Sessions[0]
+	if session.PlaylistReqs != 10 || session.SegmentReqs != 10 || session.RequestCount != 20 {
+		t.Errorf("playlist/segment/total requests = %d/%d/%d, want 10/10/20",
+			session.PlaylistReqs, session.SegmentReqs, session.RequestCount)
+	}
+
+	// A reconnect from a new source port is the same viewer
+	vt.TrackRequest(hlsRequest("203.0.113.7:50001", "/live/output.m3u8"))
+	if got := vt.GetActiveViewerCount(); got != 1 {
+		t.Errorf("active viewers after reconnect = %d, want 1", got)
+	}
+
+	// A second viewer is counted separately
+	vt.TrackRequest(hlsRequest("198.51.100.1:40000", "/live/output.m3u8"))
+	if got := vt.GetActiveViewerCount(); got != 2 {
+		t.Errorf("active viewers with a second viewer = %d, want 2", got)
+	}
+}
+
+func TestPausedViewerDropsOutAfterPresenceWindow(t *testing.T) {
+	vt := newTestTracker()
+
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/output.m3u8"))
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/segment001.ts"))
+	if got := vt.GetActiveViewerCount(); got != 1 {
+		t.Fatalf("active viewers while playing = %d, want 1", got)
+	}
+
+	// Paused: the playlist stops refreshing, only buffered segments trickle in
+	backdatePlaylist(vt, vt.presenceWindow+time.Second)
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/segment002.ts"))
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/segment003.ts"))
+
+	metrics := vt.GetMetrics()
+	if metrics.ActiveViewers != 0 {
+		t.Errorf("active viewers after pausing = %d, want 0", metrics.ActiveViewers)
+	}
+	if metrics.TotalViewers != 1 {
+		t.Errorf("total viewers after pausing = %d, want 1 (session kept until cleanup)", metrics.TotalViewers)
+	}
+	if metrics.PeakViewers != 1 {
+		t.Errorf("peak viewers = %d, want 1", metrics.PeakViewers)
+	}
+
+	// Resuming refreshes the playlist and counts again
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/output.m3u8"))
+	if got := vt.GetActiveViewerCount(); got != 1 {
+		t.Errorf("active viewers after resuming = %d, want 1", got)
+	}
+}
+
+func TestArchivePlaylistIsNotLivePresence(t *testing.T) {
+	vt := newTestTracker()
+
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/archive/2025-01-01_12-00-00/output.m3u8"))
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/archive/2025-01-01_12-00-00/segment000.ts"))
+
+	metrics := vt.GetMetrics()
+	if metrics.ActiveViewers != 0 {
+		t.Errorf("active viewers for an archive player = %d, want 0", metrics.ActiveViewers)
+	}
+	if metrics.TotalViewers != 1 || metrics.Sessions[0].PlaylistReqs != 1 {
+		t.Errorf("archive request should still be tracked: total %d, sessions %+v", metrics.TotalViewers, metrics.Sessions)
+	}
+}
+
+func TestTrackSegmentBytes(t *testing.T) {
+	vt := newTestTracker()
+
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/output.m3u8"))
+	vt.TrackSegmentBytes(hlsRequest("203.0.113.7:50000", "/live/output.m3u8"), 512)
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/segment001.ts"))
+	vt.TrackSegmentBytes(hlsRequest("203.0.113.7:50000", "/live/segment001.ts"), 1000)
+	vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/720p/segment001.m4s"))
+	vt.TrackSegmentBytes(hlsRequest("203.0.113.7:50000", "/live/720p/segment001.m4s"), 2000)
+
+	// Bytes for a viewer that was never tracked are ignored
+	vt.TrackSegmentBytes(hlsRequest("198.51.100.1:40000", "/live/segment001.ts"), 4000)
+
+	metrics := vt.GetMetrics()
+	if len(metrics.Sessions) != 1 {
+		t.Fatalf("sessions = %d, want 1", len(metrics.Sessions))
+	}
+	if got := metrics.Sessions[0].SegmentBytes; got != 3000 {
+		t.Errorf("segment bytes = %d, want 3000 (playlist bytes excluded)", got)
+	}
+}
+
+func TestIsPresent(t *testing.T) {
+	now := time.Now()
+	window := 15 * time.Second
+
+	tests := []struct {
+		name         string
+		lastPlaylist time.Time
+		want         bool
+	}{
+		{name: "never fetched the live playlist", want: false},
+		{name: "fetched just now", lastPlaylist: now, want: true},
+		{name: "at the window edge", lastPlaylist: now.Add(-window), want: true},
+		{name: "outside the window", lastPlaylist: now.Add(-window - time.Second), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &ViewerSession{LastSeen: now, LastPlaylist: tt.lastPlaylist}
+			if got := IsPresent(session, now, window); got != tt.want {
+				t.Errorf("IsPresent() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLivePlaylist(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/live/output.m3u8", want: true},
+		{path: "/live/720p/output.m3u8", want: true},
+		{path: "/LIVE/OUTPUT.M3U8", want: true},
+		{path: "/live/archive/2025-01-01_12-00-00/output.m3u8", want: false},
+		{path: "/live/segment001.ts", want: false},
+		{path: "/archive/output.m3u8", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsLivePlaylist(tt.path); got != tt.want {
+			t.Errorf("IsLivePlaylist(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGetMetricsConcurrentWithTracking(t *testing.T) {
+	vt := newTestTracker()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				vt.TrackRequest(hlsRequest("203.0.113.7:50000", "/live/output.m3u8"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				vt.GetMetrics()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -3,7 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
-	"os"
+	"strings"
 
 	"gnostream/src/cli/commands"
 	"gnostream/src/config"
@@ -18,22 +18,25 @@ var (
 
 // CLI represents the command line interface
 type CLI struct {
-	config *config.Config
+	config     *config.Config
+	configPath string
+	args       []string // Command line after the global flags, starting with the command
 }
 
-// NewCLI creates a new CLI instance
-func NewCLI() *CLI {
-	return &CLI{}
+// NewCLI creates a new CLI instance using the config file at configPath
+func NewCLI(configPath string) *CLI {
+	return &CLI{configPath: configPath}
 }
 
-// Run executes the CLI based on command line arguments
-func (cli *CLI) Run() error {
-	if len(os.Args) < 2 {
+// Run executes the CLI for args (the command line after global flags, see ParseGlobalFlags)
+func (cli *CLI) Run(args []string) error {
+	if len(args) < 1 {
 		cli.printUsage()
 		return nil
 	}
 
-	command := os.Args[1]
+	cli.args = args
+	command := args[0]
 
 	switch command {
 	case "server":
@@ -46,6 +49,12 @@ func (cli *CLI) Run() error {
 		return cli.runStream()
 	case "cleanup":
 		return cli.runCleanup()
+	case "backup":
+		return cli.runBackup()
+	case "restore":
+		return cli.runRestore()
+	case "doctor":
+		return cli.runDoctor()
 	case "version":
 		return cli.runVersion()
 	case "help", "-h", "--help":
@@ -63,7 +72,10 @@ func (cli *CLI) printUsage() {
 	fmt.Println(`🎬 GNOSTREAM CLI
 
 USAGE:
-    gnostream <COMMAND> [OPTIONS]
+    gnostream [--config <path>] <COMMAND> [OPTIONS]
+
+GLOBAL OPTIONS:
+    --config <path> Config file to use (default: $GNOSTREAM_CONFIG, then config.yml)
 
 COMMANDS:
     server          Start the streaming server (default mode)
@@ -71,6 +83,9 @@ COMMANDS:
     events          Manage Nostr stream events
     stream          Stream management and debugging
     cleanup         Clean up stale streams and events  
+    backup          Back up config and stream info to a tarball
+    restore         Restore config and stream info from a backup
+    doctor          Check the setup for common problems
     version         Show version information
     help            Show this help message
 
@@ -82,6 +97,8 @@ EXAMPLES:
     gnostream events delete <id>        # Delete specific event
     gnostream stream status             # Show current stream status
     gnostream cleanup stale             # Clean up stale live events
+    gnostream backup setup.tar.gz       # Snapshot config for a new machine
+    gnostream doctor                    # Diagnose FFmpeg, keys, relays, ports and dirs
     
 For more information on a specific command, use:
     gnostream <COMMAND> --help`)
@@ -93,7 +110,7 @@ func (cli *CLI) loadConfig() error {
 		return nil
 	}
 
-	cfg, err := config.Load("config.yml")
+	cfg, err := config.Load(cli.configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -115,7 +132,7 @@ func (cli *CLI) runConfig() error {
 	}
 
 	configCmd := commands.NewConfigCommand(cli.config)
-	return configCmd.Execute(os.Args[2:])
+	return configCmd.Execute(cli.args[1:])
 }
 
 // runEvents handles Nostr event management
@@ -125,7 +142,7 @@ func (cli *CLI) runEvents() error {
 	}
 
 	eventsCmd := commands.NewEventsCommand(cli.config)
-	return eventsCmd.Execute(os.Args[2:])
+	return eventsCmd.Execute(cli.args[1:])
 }
 
 // runStream handles stream management
@@ -135,7 +152,7 @@ func (cli *CLI) runStream() error {
 	}
 
 	streamCmd := commands.NewStreamCommand(cli.config)
-	return streamCmd.Execute(os.Args[2:])
+	return streamCmd.Execute(cli.args[1:])
 }
 
 // runCleanup handles cleanup operations
@@ -145,7 +162,30 @@ func (cli *CLI) runCleanup() error {
 	}
 
 	cleanupCmd := commands.NewCleanupCommand(cli.config)
-	return cleanupCmd.Execute(os.Args[2:])
+	return cleanupCmd.Execute(cli.args[1:])
+}
+
+// runBackup writes the setup files to a tarball
+func (cli *CLI) runBackup() error {
+	if err := cli.loadConfig(); err != nil {
+		return err
+	}
+
+	backupCmd := commands.NewBackupCommand(cli.config)
+	return backupCmd.Execute(cli.args[1:])
+}
+
+// runRestore unpacks a backup (no config needed, so it works on a fresh install)
+func (cli *CLI) runRestore() error {
+	restoreCmd := commands.NewRestoreCommand()
+	return restoreCmd.Execute(cli.args[1:])
+}
+
+// runDoctor checks the setup; a config that fails to load is reported rather than returned
+func (cli *CLI) runDoctor() error {
+	loadErr := cli.loadConfig()
+	doctorCmd := commands.NewDoctorCommand(cli.config, cli.configPath, loadErr)
+	return doctorCmd.Execute(cli.args[1:])
 }
 
 // runVersion shows version information
@@ -157,6 +197,33 @@ func (cli *CLI) runVersion() error {
 	return nil
 }
 
+// ParseGlobalFlags strips the global flags (--config <path> or --config=<path>) that precede
+// the command and returns the resolved config path with the remaining arguments
+func ParseGlobalFlags(args []string) (string, []string, error) {
+	configFlag := ""
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if name != "config" || !strings.HasPrefix(args[0], "-") {
+			break
+		}
+
+		if hasValue {
+			args = args[1:]
+		} else {
+			if len(args) < 2 {
+				return "", nil, fmt.Errorf("--config needs a file path")
+			}
+			value, args = args[1], args[2:]
+		}
+		if value == "" {
+			return "", nil, fmt.Errorf("--config needs a file path")
+		}
+		configFlag = value
+	}
+
+	return config.ResolveConfigPath(configFlag), args, nil
+}
+
 // ParseFlags parses common CLI flags
 func ParseFlags(args []string) (*flag.FlagSet, error) {
 	fs := flag.NewFlagSet("gnostream", flag.ContinueOnError)
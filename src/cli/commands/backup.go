@@ -0,0 +1,301 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gnostream/src/config"
+)
+
+// BackupCommand writes the setup files to a tarball
+type BackupCommand struct {
+	config *config.Config
+}
+
+// NewBackupCommand creates a new backup command
+func NewBackupCommand(cfg *config.Config) *BackupCommand {
+	return &BackupCommand{config: cfg}
+}
+
+// Execute runs the backup command
+func (b *BackupCommand) Execute(args []string) error {
+	var path string
+	noSecrets := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "help":
+			b.printUsage()
+			return nil
+		case "--no-secrets":
+			noSecrets = true
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("unknown option: %s", arg)
+			}
+			path = arg
+		}
+	}
+
+	if path == "" {
+		b.printUsage()
+		return fmt.Errorf("backup path required")
+	}
+
+	files := b.config.BackupFiles(b.config.Path())
+	secretFiles := make(map[string]bool)
+	for _, file := range b.config.SecretFiles() {
+		secretFiles[file] = true
+	}
+	fmt.Printf("📦 Backing up %d files to %s\n", len(files), path)
+
+	// Keys stay readable only by the owner unless they were stripped
+	mode := os.FileMode(0600)
+	if noSecrets {
+		mode = 0644
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	for _, file := range files {
+		if secretFiles[file] && noSecrets {
+			fmt.Printf("🔒 Leaving out %s (nostr.private_key_file)\n", file)
+			continue
+		}
+		if filepath.IsAbs(file) || strings.HasPrefix(file, "..") {
+			if secretFiles[file] {
+				fmt.Printf("⚠️  Skipping the private key file %s (outside the working directory) - copy it to the new machine yourself and chmod 600 it\n", file)
+				continue
+			}
+			fmt.Printf("⚠️  Skipping %s (outside the working directory, copy it manually)\n", file)
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if noSecrets && file == filepath.Clean(b.config.Path()) {
+			if data, err = config.RedactSecrets(data); err != nil {
+				return fmt.Errorf("failed to strip secrets from %s: %w", file, err)
+			}
+		}
+
+		header := &tar.Header{
+			Name:    filepath.ToSlash(file),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		}
+		if secretFiles[file] || (file == filepath.Clean(b.config.Path()) && !noSecrets) {
+			header.Mode = 0600
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+		fmt.Printf("   📄 %s\n", file)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if noSecrets {
+		fmt.Println("🔒 Secrets were left out - set nostr.private_key or its key file (and webhooks.secret) again after restoring")
+	}
+	if os.Getenv(config.PrivateKeyEnv) != "" {
+		fmt.Printf("⚠️  The private key comes from $%s, which is not part of the backup\n", config.PrivateKeyEnv)
+	}
+	fmt.Printf("✅ Backup written to %s\n", path)
+	return nil
+}
+
+// printUsage prints backup command usage
+func (b *BackupCommand) printUsage() {
+	fmt.Println(`BACKUP
+
+USAGE:
+    gnostream backup <path> [--no-secrets]
+
+Writes config.yml, the stream info file and its profiles, the chat filter word
+list and nostr.private_key_file to a .tar.gz for moving a working setup to a new
+machine. A key file outside the working directory is not included.
+
+OPTIONS:
+    --no-secrets    Blank the Nostr private key and webhook secret in the backed up config.yml
+                    and leave out nostr.private_key_file
+
+EXAMPLES:
+    gnostream backup gnostream-backup.tar.gz
+    gnostream backup share-me.tar.gz --no-secrets`)
+}
+
+// RestoreCommand unpacks a backup written by BackupCommand
+type RestoreCommand struct{}
+
+// NewRestoreCommand creates a new restore command. It needs no config, so it works on a fresh install.
+func NewRestoreCommand() *RestoreCommand {
+	return &RestoreCommand{}
+}
+
+// restoredFile is one file read from a backup
+type restoredFile struct {
+	name string
+	mode os.FileMode
+	data []byte
+}
+
+// Execute runs the restore command
+func (r *RestoreCommand) Execute(args []string) error {
+	var path string
+	force := false
+	confirm := false
+
+	for _, arg := range args {
+		switch arg {
+		case "--help", "help":
+			r.printUsage()
+			return nil
+		case "--force":
+			force = true
+		case "--confirm":
+			confirm = true
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("unknown option: %s", arg)
+			}
+			path = arg
+		}
+	}
+
+	if path == "" {
+		r.printUsage()
+		return fmt.Errorf("backup path required")
+	}
+
+	files, err := readBackup(path)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("backup %s contains no files", path)
+	}
+
+	fmt.Printf("📦 Backup %s contains:\n", path)
+	var existing []string
+	for _, file := range files {
+		marker := "📄"
+		if _, err := os.Stat(file.name); err == nil {
+			marker = "⚠️ "
+			existing = append(existing, file.name)
+		}
+		fmt.Printf("   %s %s\n", marker, file.name)
+	}
+
+	if len(existing) > 0 && !force {
+		return fmt.Errorf("%d files already exist (marked ⚠️); rerun with --force to overwrite them", len(existing))
+	}
+
+	if !confirm {
+		fmt.Print("\nRestore these files? (y/N): ")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Restore cancelled")
+			return nil
+		}
+	}
+
+	for _, file := range files {
+		if dir := filepath.Dir(file.name); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+		}
+		if err := os.WriteFile(file.name, file.data, file.mode); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", file.name, err)
+		}
+	}
+
+	fmt.Printf("✅ Restored %d files\n", len(files))
+	return nil
+}
+
+// readBackup reads every file from a backup, rejecting entries that would land outside the
+// working directory
+func readBackup(path string) ([]restoredFile, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	var files []restoredFile
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", path, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("backup entry %q points outside the working directory", header.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from backup: %w", header.Name, err)
+		}
+		files = append(files, restoredFile{name: name, mode: os.FileMode(header.Mode).Perm(), data: data})
+	}
+	return files, nil
+}
+
+// printUsage prints restore command usage
+func (r *RestoreCommand) printUsage() {
+	fmt.Println(`RESTORE
+
+USAGE:
+    gnostream restore <path> [--force] [--confirm]
+
+Unpacks a backup made with 'gnostream backup' into the working directory.
+
+OPTIONS:
+    --force      Overwrite files that already exist (refused otherwise)
+    --confirm    Skip the confirmation prompt
+
+EXAMPLES:
+    gnostream restore gnostream-backup.tar.gz
+    gnostream restore gnostream-backup.tar.gz --force`)
+}
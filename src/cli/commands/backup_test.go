@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gnostream/src/config"
+)
+
+// setupBackupDir creates a working directory with a config that reads its key from keyFile
+// and returns the loaded config. The test runs inside that directory.
+func setupBackupDir(t *testing.T, keyFile string) *config.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("changing to %s: %v", dir, err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+
+	files := map[string]string{
+		"config.yml":      "nostr:\n  private_key_file: \"" + keyFile + "\"\nwebhooks:\n  secret: \"hook-secret\"\n",
+		"stream-info.yml": "title: \"Backup test\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	if !filepath.IsAbs(keyFile) {
+		if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+			t.Fatalf("creating key directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(keyFile, []byte(testNsec+"\n"), 0600); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+
+	var cfg *config.Config
+	captureOutput(t, "", func() { cfg, err = config.Load("config.yml") })
+	if err != nil {
+		t.Fatalf("loading config: %v", err)
+	}
+	return cfg
+}
+
+// runBackup writes a backup with args and returns its files by name, plus what was printed
+func runBackup(t *testing.T, cfg *config.Config, args ...string) (map[string]restoredFile, string) {
+	t.Helper()
+
+	var runErr error
+	output := captureOutput(t, "", func() {
+		runErr = NewBackupCommand(cfg).Execute(append([]string{"backup.tar.gz"}, args...))
+	})
+	if runErr != nil {
+		t.Fatalf("backup %v: %v\n%s", args, runErr, output)
+	}
+
+	restored, err := readBackup("backup.tar.gz")
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	files := make(map[string]restoredFile)
+	for _, file := range restored {
+		files[file.name] = file
+	}
+	return files, output
+}
+
+func TestBackupIncludesPrivateKeyFile(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+	keyFile := filepath.Join("keys", "nsec")
+	cfg := setupBackupDir(t, keyFile)
+
+	files, _ := runBackup(t, cfg)
+
+	key, ok := files[keyFile]
+	if !ok {
+		t.Fatalf("backup files = %v, want %s included", fileNames(files), keyFile)
+	}
+	if strings.TrimSpace(string(key.data)) != testNsec {
+		t.Errorf("backed up key file = %q, want the key", key.data)
+	}
+	if key.mode != 0600 {
+		t.Errorf("key file mode = %o, want 600", key.mode)
+	}
+	if files["config.yml"].mode != 0600 {
+		t.Errorf("config.yml mode = %o, want 600", files["config.yml"].mode)
+	}
+}
+
+func TestBackupWithoutSecretsLeavesOutPrivateKeyFile(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+	keyFile := filepath.Join("keys", "nsec")
+	cfg := setupBackupDir(t, keyFile)
+
+	files, output := runBackup(t, cfg, "--no-secrets")
+
+	if _, ok := files[keyFile]; ok {
+		t.Errorf("--no-secrets backup contains the key file %s", keyFile)
+	}
+	if !strings.Contains(output, "Leaving out "+keyFile) {
+		t.Errorf("backup did not say the key file was left out:\n%s", output)
+	}
+	for name, file := range files {
+		if strings.Contains(string(file.data), testNsec) || strings.Contains(string(file.data), "hook-secret") {
+			t.Errorf("--no-secrets backup file %s contains a secret:\n%s", name, file.data)
+		}
+	}
+	// The path is a setting, not a secret: the restored config still says where the key goes
+	if !strings.Contains(string(files["config.yml"].data), keyFile) {
+		t.Errorf("redacted config.yml lost private_key_file:\n%s", files["config.yml"].data)
+	}
+}
+
+func TestBackupWarnsAboutKeysItCannotInclude(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "nsec") // Outside the working directory
+	cfg := setupBackupDir(t, keyFile)
+	t.Setenv(config.PrivateKeyEnv, testNsec)
+
+	files, output := runBackup(t, cfg)
+
+	for name := range files {
+		if strings.Contains(name, "nsec") {
+			t.Errorf("backup contains %s from outside the working directory", name)
+		}
+	}
+	if !strings.Contains(output, "Skipping the private key file "+keyFile) {
+		t.Errorf("backup did not warn that the key file is excluded:\n%s", output)
+	}
+	if !strings.Contains(output, "$"+config.PrivateKeyEnv) {
+		t.Errorf("backup did not warn that the environment key is excluded:\n%s", output)
+	}
+}
+
+func fileNames(files map[string]restoredFile) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
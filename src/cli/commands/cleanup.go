@@ -9,6 +9,7 @@ import (
 
 	"gnostream/src/config"
 	"gnostream/src/nostr"
+	"gnostream/src/util"
 )
 
 // CleanupCommand handles cleanup operations
@@ -146,7 +147,7 @@ func (c *CleanupCommand) handleOldSegments(args []string) error {
 		return nil
 	}
 
-	fmt.Printf("🗑️  Found %d old segment files (%s total)\n", len(oldFiles), formatFileSize(totalSize))
+	fmt.Printf("🗑️  Found %d old segment files (%s total)\n", len(oldFiles), util.FormatBytes(totalSize))
 
 	// Show some examples
 	fmt.Println("\nFiles to be deleted:")
@@ -310,7 +311,7 @@ func (c *CleanupCommand) handleDryRun(args []string) error {
 	} else if len(oldFiles) == 0 {
 		fmt.Println("   ✅ No old segments found")
 	} else {
-		fmt.Printf("   🗑️  Would delete %d files (%s)\n", len(oldFiles), formatFileSize(totalSize))
+		fmt.Printf("   🗑️  Would delete %d files (%s)\n", len(oldFiles), util.FormatBytes(totalSize))
 	}
 
 	// Check archives
@@ -407,17 +408,3 @@ func (c *CleanupCommand) findOldArchives(dir string, cutoff time.Time) ([]FileIn
 
 	return oldArchives, nil
 }
-
-// formatFileSize formats byte size into human readable format
-func formatFileSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
\ No newline at end of file
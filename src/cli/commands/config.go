@@ -35,9 +35,13 @@ func (c *ConfigCommand) Execute(args []string) error {
 	case "list":
 		return c.handleList()
 	case "show":
-		return c.handleShow()
+		return c.handleShow(args[1:])
 	case "reload":
 		return c.handleReload()
+	case "use":
+		return c.handleUse(args[1:])
+	case "profiles":
+		return c.handleProfiles()
 	case "--help", "help":
 		c.printUsage()
 		return nil
@@ -59,8 +63,11 @@ SUBCOMMANDS:
     get <key>           Get configuration value
     set <key> <value>   Set configuration value  
     list               List all configuration keys
-    show               Show current configuration
+    show               Show current configuration (private key redacted)
+    show --reveal-secrets  Also print the private key (asks for confirmation)
     reload             Reload configuration from file
+    use <profile>      Switch the active stream info profile ("default" for stream-info.yml)
+    profiles           List available stream info profiles
 
 CONFIGURATION KEYS:
     recording          Enable/disable recording (true/false)
@@ -70,17 +77,31 @@ CONFIGURATION KEYS:
     summary            Stream summary/description
     image              Stream thumbnail image URL
     tags               Stream tags (comma-separated)
-    server.port        Server port
-    server.host        Server host
-    rtmp.port          RTMP server port
+    category           Stream category/game (empty to clear)
+    server.port        Server port (config.yml)
+    server.host        Server host (config.yml)
+    server.external_url Public URL used in Nostr events (config.yml)
+    rtmp.port          RTMP server port (config.yml)
+    nostr.relays       Relay URLs (comma-separated, config.yml)
+    nostr.write_relays Relays events are published to first (empty = all, config.yml)
+    nostr.read_relays  Relays chat is read from first (empty = all, config.yml)
 
 EXAMPLES:
     gnostream config get recording
     gnostream config set recording true
     gnostream config set title "My Stream"
     gnostream config set tags "gaming,live,test"
+    gnostream config set server.port 8081
+    gnostream config set nostr.relays "wss://relay.damus.io,wss://nos.lol"
     gnostream config show
-    gnostream config reload`)
+    gnostream config reload
+    gnostream config use gaming         # Use stream-info.gaming.yml from the next server start
+    gnostream config profiles
+
+PROFILES:
+    A profile is a stream-info.<name>.yml file next to stream-info.yml with its
+    own title, tags, recording and HLS settings. Pick one per run with
+    "gnostream server --profile <name>" or save it with "config use <name>".`)
 }
 
 // handleGet gets a configuration value
@@ -113,6 +134,9 @@ func (c *ConfigCommand) handleSet(args []string) error {
 	}
 
 	fmt.Printf("✅ Set %s = %s\n", key, value)
+	if isMainConfigKey(key) {
+		fmt.Println("💡 Restart the server for this change to take effect")
+	}
 	return nil
 }
 
@@ -121,20 +145,43 @@ func (c *ConfigCommand) handleList() error {
 	fmt.Println("CONFIGURATION KEYS:")
 	keys := []string{
 		"recording", "segment_time", "playlist_size",
-		"title", "summary", "image", "tags",
-		"server.port", "server.host", "rtmp.port",
+		"title", "summary", "image", "tags", "category",
+		"server.port", "server.host", "server.external_url",
+		"rtmp.port", "nostr.relays", "nostr.write_relays", "nostr.read_relays",
 	}
 
 	for _, key := range keys {
 		value, _ := c.getConfigValue(key)
-		fmt.Printf("  %-15s %v\n", key, value)
+		fmt.Printf("  %-20s %v\n", key, value)
 	}
 
 	return nil
 }
 
-// handleShow shows the current configuration
-func (c *ConfigCommand) handleShow() error {
+// handleShow shows the current configuration. The private key is always redacted unless
+// --reveal-secrets is given and confirmed.
+func (c *ConfigCommand) handleShow(args []string) error {
+	revealSecrets := false
+	for _, arg := range args {
+		switch arg {
+		case "--reveal-secrets":
+			revealSecrets = true
+		default:
+			return fmt.Errorf("unknown option: %s", arg)
+		}
+	}
+
+	if revealSecrets {
+		fmt.Print("⚠️  This prints your Nostr private key. Anyone who sees it controls your identity.\nType 'reveal' to continue: ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "reveal" {
+			fmt.Println("Not revealing secrets")
+			revealSecrets = false
+		}
+		fmt.Println()
+	}
+
 	fmt.Println("CURRENT CONFIGURATION:")
 	fmt.Println()
 
@@ -144,11 +191,13 @@ func (c *ConfigCommand) handleShow() error {
 		fmt.Printf("  Summary:     %s\n", c.config.StreamInfo.Summary)
 		fmt.Printf("  Image:       %s\n", c.config.StreamInfo.Image)
 		fmt.Printf("  Tags:        %v\n", c.config.StreamInfo.Tags)
+		fmt.Printf("  Category:    %s\n", c.config.StreamInfo.Category)
 		fmt.Printf("  Recording:   %t\n", c.config.StreamInfo.Record)
 		fmt.Println()
+		hlsConfig := c.config.GetHLSConfig()
 		fmt.Printf("  HLS Settings:\n")
-		fmt.Printf("    Segment Time:   %d seconds\n", c.config.StreamInfo.HLS.SegmentTime)
-		fmt.Printf("    Playlist Size:  %d segments\n", c.config.StreamInfo.HLS.PlaylistSize)
+		fmt.Printf("    Segment Time:   %d seconds\n", hlsConfig.SegmentTime)
+		fmt.Printf("    Playlist Size:  %d segments\n", hlsConfig.PlaylistSize)
 	}
 
 	fmt.Println()
@@ -167,7 +216,23 @@ func (c *ConfigCommand) handleShow() error {
 	fmt.Println()
 	fmt.Println("🔗 NOSTR:")
 	fmt.Printf("  Relays:      %v\n", c.config.Nostr.Relays)
+	if len(c.config.Nostr.WriteRelays) > 0 {
+		fmt.Printf("  Write:       %v\n", c.config.Nostr.WriteRelays)
+	}
+	if len(c.config.Nostr.ReadRelays) > 0 {
+		fmt.Printf("  Read:        %v\n", c.config.Nostr.ReadRelays)
+	}
 	fmt.Printf("  Public Key:  %s\n", c.config.Nostr.PublicKey)
+	privateKey := "(not set)"
+	if key, err := c.config.Nostr.ResolvePrivateKey(); err != nil {
+		privateKey = fmt.Sprintf("(error: %v)", err)
+	} else if key != "" {
+		privateKey = fmt.Sprintf("[redacted] from %s (use --reveal-secrets to print it)", c.config.Nostr.PrivateKeySource())
+		if revealSecrets {
+			privateKey = key
+		}
+	}
+	fmt.Printf("  Private Key: %s\n", privateKey)
 	fmt.Printf("  Delete Non-Recorded: %t\n", c.config.Nostr.DeleteNonRecorded)
 
 	return nil
@@ -179,6 +244,9 @@ func (c *ConfigCommand) handleReload() error {
 	if err != nil {
 		return fmt.Errorf("failed to reload config: %w", err)
 	}
+	if err := c.config.StreamInfoError(); err != nil {
+		return fmt.Errorf("stream info file not reloaded, still using the last good version: %w", err)
+	}
 
 	if changed {
 		fmt.Println("✅ Configuration reloaded successfully")
@@ -189,8 +257,64 @@ func (c *ConfigCommand) handleReload() error {
 	return nil
 }
 
+// handleUse switches the active profile and saves it to config.yml
+func (c *ConfigCommand) handleUse(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing profile name")
+	}
+
+	name := args[0]
+	if err := c.config.UseProfile(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(c.config.Path(), c.config); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Active profile: %s (%s)\n", c.config.ActiveProfile(), c.config.StreamInfoPath)
+	fmt.Println("💡 Restart the server to stream with this profile")
+	return nil
+}
+
+// handleProfiles lists the available profiles and marks the active one
+func (c *ConfigCommand) handleProfiles() error {
+	profiles, err := c.config.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	fmt.Println("STREAM INFO PROFILES:")
+	for _, name := range append([]string{config.DefaultProfile}, profiles...) {
+		marker := " "
+		if name == c.config.ActiveProfile() {
+			marker = "*"
+		}
+		fmt.Printf("  %s %s\n", marker, name)
+	}
+
+	return nil
+}
+
 // getConfigValue gets a configuration value by key
 func (c *ConfigCommand) getConfigValue(key string) (interface{}, error) {
+	switch key {
+	case "server.port":
+		return c.config.Server.Port, nil
+	case "server.host":
+		return c.config.Server.Host, nil
+	case "server.external_url":
+		return c.config.Server.ExternalURL, nil
+	case "rtmp.port":
+		return c.config.GetRTMPDefaults().Port, nil
+	case "nostr.relays":
+		return strings.Join(c.config.Nostr.Relays, ","), nil
+	case "nostr.write_relays":
+		return strings.Join(c.config.Nostr.WriteRelays, ","), nil
+	case "nostr.read_relays":
+		return strings.Join(c.config.Nostr.ReadRelays, ","), nil
+	}
+
 	if c.config.StreamInfo == nil {
 		return nil, fmt.Errorf("stream info not loaded")
 	}
@@ -199,9 +323,10 @@ func (c *ConfigCommand) getConfigValue(key string) (interface{}, error) {
 	case "recording":
 		return c.config.StreamInfo.Record, nil
 	case "segment_time":
-		return c.config.StreamInfo.HLS.SegmentTime, nil
+		// The effective value (defaults and the low-latency cap applied), not the raw YAML
+		return c.config.GetHLSConfig().SegmentTime, nil
 	case "playlist_size":
-		return c.config.StreamInfo.HLS.PlaylistSize, nil
+		return c.config.GetHLSConfig().PlaylistSize, nil
 	case "title":
 		return c.config.StreamInfo.Title, nil
 	case "summary":
@@ -210,12 +335,8 @@ func (c *ConfigCommand) getConfigValue(key string) (interface{}, error) {
 		return c.config.StreamInfo.Image, nil
 	case "tags":
 		return strings.Join(c.config.StreamInfo.Tags, ","), nil
-	case "server.port":
-		return c.config.Server.Port, nil
-	case "server.host":
-		return c.config.Server.Host, nil
-	case "rtmp.port":
-		return c.config.GetRTMPDefaults().Port, nil
+	case "category":
+		return c.config.StreamInfo.Category, nil
 	default:
 		return nil, fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -223,6 +344,10 @@ func (c *ConfigCommand) getConfigValue(key string) (interface{}, error) {
 
 // setConfigValue sets a configuration value by key
 func (c *ConfigCommand) setConfigValue(key, value string) error {
+	if isMainConfigKey(key) {
+		return c.setMainConfigValue(key, value)
+	}
+
 	if c.config.StreamInfo == nil {
 		return fmt.Errorf("stream info not loaded")
 	}
@@ -236,14 +361,17 @@ func (c *ConfigCommand) setConfigValue(key, value string) error {
 		c.config.StreamInfo.Record = boolVal
 	case "segment_time":
 		intVal, err := strconv.Atoi(value)
-		if err != nil {
-			return fmt.Errorf("invalid integer value: %s", value)
+		if err != nil || intVal < 1 {
+			return fmt.Errorf("invalid segment time: %s (must be a positive number of seconds)", value)
 		}
 		c.config.StreamInfo.HLS.SegmentTime = intVal
+		if hlsConfig := c.config.GetHLSConfig(); hlsConfig.SegmentTime != intVal {
+			fmt.Printf("⚠️  low_latency is on, so FFmpeg uses %d second segments\n", hlsConfig.SegmentTime)
+		}
 	case "playlist_size":
 		intVal, err := strconv.Atoi(value)
-		if err != nil {
-			return fmt.Errorf("invalid integer value: %s", value)
+		if err != nil || intVal < 1 {
+			return fmt.Errorf("invalid playlist size: %s (must be a positive number of segments)", value)
 		}
 		c.config.StreamInfo.HLS.PlaylistSize = intVal
 	case "title":
@@ -258,10 +386,71 @@ func (c *ConfigCommand) setConfigValue(key, value string) error {
 		for i, tag := range c.config.StreamInfo.Tags {
 			c.config.StreamInfo.Tags[i] = strings.TrimSpace(tag)
 		}
+	case "category":
+		c.config.StreamInfo.Category = strings.TrimSpace(value)
 	default:
 		return fmt.Errorf("configuration key '%s' is not settable via CLI", key)
 	}
 
 	// Save the updated stream info back to file
 	return config.SaveStreamInfo(c.config.StreamInfoPath, c.config.StreamInfo)
-}
\ No newline at end of file
+}
+
+// isMainConfigKey reports whether a key lives in config.yml rather than the stream info file
+func isMainConfigKey(key string) bool {
+	return strings.HasPrefix(key, "server.") || strings.HasPrefix(key, "rtmp.") || strings.HasPrefix(key, "nostr.")
+}
+
+// setMainConfigValue validates and writes a server, RTMP or relay setting to config.yml
+func (c *ConfigCommand) setMainConfigValue(key, value string) error {
+	switch key {
+	case "server.port", "rtmp.port":
+		port, err := config.ParsePort(value)
+		if err != nil {
+			return err
+		}
+		if key == "server.port" {
+			c.config.Server.Port = port
+		} else {
+			c.config.RTMP.Port = port
+		}
+	case "server.host":
+		if err := config.ValidateHost(value); err != nil {
+			return err
+		}
+		c.config.Server.Host = value
+	case "server.external_url":
+		value = strings.TrimRight(value, "/")
+		if err := config.ValidateExternalURL(value); err != nil {
+			return err
+		}
+		c.config.Server.ExternalURL = value
+	case "nostr.relays", "nostr.write_relays", "nostr.read_relays":
+		relays := []string{}
+		for _, relay := range strings.Split(value, ",") {
+			relay = strings.TrimSpace(relay)
+			if relay == "" {
+				continue
+			}
+			if err := config.ValidateRelayURL(relay); err != nil {
+				return err
+			}
+			relays = append(relays, relay)
+		}
+		switch key {
+		case "nostr.relays":
+			if len(relays) == 0 {
+				return fmt.Errorf("at least one relay is required")
+			}
+			c.config.Nostr.Relays = relays
+		case "nostr.write_relays":
+			c.config.Nostr.WriteRelays = relays // Empty falls back to nostr.relays
+		default:
+			c.config.Nostr.ReadRelays = relays
+		}
+	default:
+		return fmt.Errorf("configuration key '%s' is not settable via CLI", key)
+	}
+
+	return config.SaveConfig(c.config.Path(), c.config)
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gnostream/src/config"
+)
+
+// NIP-19 test vector key, in both encodings
+const (
+	testNsec       = "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5"
+	testPrivateHex = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+)
+
+// captureOutput runs fn with stdin fed from the given string and returns what it prints
+func captureOutput(t *testing.T, stdin string, fn func()) string {
+	t.Helper()
+
+	inReader, inWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating stdin pipe: %v", err)
+	}
+	inWriter.WriteString(stdin)
+	inWriter.Close()
+
+	outReader, outWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating stdout pipe: %v", err)
+	}
+
+	stdinBefore, stdoutBefore := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = inReader, outWriter
+	defer func() { os.Stdin, os.Stdout = stdinBefore, stdoutBefore }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(outReader)
+		output <- string(data)
+	}()
+
+	fn()
+	outWriter.Close()
+	return <-output
+}
+
+// runConfigShow runs "config show" with args, feeding stdin and returning what it prints
+func runConfigShow(t *testing.T, cfg *config.Config, stdin string, args ...string) string {
+	t.Helper()
+
+	var runErr error
+	output := captureOutput(t, stdin, func() {
+		runErr = NewConfigCommand(cfg).Execute(append([]string{"show"}, args...))
+	})
+	if runErr != nil {
+		t.Fatalf("config show %v: %v", args, runErr)
+	}
+	return output
+}
+
+func TestConfigShowRedactsPrivateKey(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+
+	keyFile := filepath.Join(t.TempDir(), "nsec")
+	if err := os.WriteFile(keyFile, []byte(testNsec+"\n"), 0600); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+
+	sources := map[string]config.NostrRelayConfig{
+		"inline nsec": {PrivateKey: testNsec},
+		"inline hex":  {PrivateKey: testPrivateHex},
+		"key file":    {PrivateKeyFile: keyFile},
+	}
+
+	for name, nostrConfig := range sources {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Config{Nostr: nostrConfig}
+
+			tests := []struct {
+				name  string
+				stdin string
+				args  []string
+			}{
+				{name: "default"},
+				{name: "reveal not confirmed", stdin: "no\n", args: []string{"--reveal-secrets"}},
+			}
+			for _, tt := range tests {
+				output := runConfigShow(t, cfg, tt.stdin, tt.args...)
+				for _, secret := range []string{testNsec, testPrivateHex} {
+					if strings.Contains(output, secret) {
+						t.Errorf("%s: output contains the private key:\n%s", tt.name, output)
+					}
+				}
+				if !strings.Contains(output, "Private Key: [redacted]") {
+					t.Errorf("%s: output does not show the key as redacted:\n%s", tt.name, output)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigShowRevealsPrivateKeyWhenConfirmed(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+	cfg := &config.Config{Nostr: config.NostrRelayConfig{PrivateKey: testNsec}}
+
+	output := runConfigShow(t, cfg, "reveal\n", "--reveal-secrets")
+	if !strings.Contains(output, "Private Key: "+testNsec) {
+		t.Errorf("confirmed --reveal-secrets did not print the key:\n%s", output)
+	}
+}
+
+func TestConfigShowWithoutPrivateKey(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+
+	output := runConfigShow(t, &config.Config{}, "")
+	if !strings.Contains(output, "Private Key: (not set)") {
+		t.Errorf("output does not report a missing key:\n%s", output)
+	}
+}
+
+func TestConfigGetShowsEffectiveHLSValues(t *testing.T) {
+	tests := []struct {
+		name string
+		hls  config.HLSConfig
+		key  string
+		want string
+	}{
+		{name: "unset segment time", key: "segment_time", want: "segment_time = 10"},
+		{name: "configured segment time", hls: config.HLSConfig{SegmentTime: 4}, key: "segment_time", want: "segment_time = 4"},
+		{name: "low latency cap", hls: config.HLSConfig{SegmentTime: 6, LowLatency: true}, key: "segment_time", want: "segment_time = 2"},
+		{name: "unset playlist size", key: "playlist_size", want: "playlist_size = 10"},
+		{name: "configured playlist size", hls: config.HLSConfig{PlaylistSize: 5}, key: "playlist_size", want: "playlist_size = 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{StreamInfo: &config.StreamInfo{HLS: tt.hls}}
+
+			var runErr error
+			output := captureOutput(t, "", func() {
+				runErr = NewConfigCommand(cfg).Execute([]string{"get", tt.key})
+			})
+			if runErr != nil {
+				t.Fatalf("config get %s: %v", tt.key, runErr)
+			}
+			if got := strings.TrimSpace(output); got != tt.want {
+				t.Errorf("config get %s printed %q, want %q", tt.key, got, tt.want)
+			}
+
+			// It must be the value GetHLSConfig hands the RTMP server and monitor when they start FFmpeg
+			hls := cfg.GetHLSConfig()
+			effective := map[string]int{"segment_time": hls.SegmentTime, "playlist_size": hls.PlaylistSize}
+			if want := fmt.Sprintf("%s = %d", tt.key, effective[tt.key]); output != want+"\n" {
+				t.Errorf("config get %s printed %q, GetHLSConfig has %q", tt.key, output, want)
+			}
+		})
+	}
+}
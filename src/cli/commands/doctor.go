@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core/tools"
+
+	"gnostream/src/config"
+)
+
+// relayDialTimeout bounds each relay reachability check
+const relayDialTimeout = 5 * time.Second
+
+// DoctorCommand checks a setup for the problems that otherwise fail silently
+type DoctorCommand struct {
+	config     *config.Config
+	configPath string
+	loadErr    error
+
+	passed, warned, failed int
+}
+
+// NewDoctorCommand creates a doctor command. cfg is nil when the config failed to load with loadErr.
+func NewDoctorCommand(cfg *config.Config, configPath string, loadErr error) *DoctorCommand {
+	return &DoctorCommand{
+		config:     cfg,
+		configPath: configPath,
+		loadErr:    loadErr,
+	}
+}
+
+// Execute runs every check and prints the report; it fails when any check failed
+func (d *DoctorCommand) Execute(args []string) error {
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "help") {
+		d.printUsage()
+		return nil
+	}
+
+	fmt.Println("🩺 GNOSTREAM DOCTOR")
+
+	d.checkConfig()
+	d.checkBinaries()
+	if d.config != nil {
+		d.checkKeys()
+		d.checkRelays()
+		d.checkPorts()
+		d.checkDirectories()
+	}
+
+	fmt.Println()
+	fmt.Printf("📋 %d passed, %d warnings, %d failed\n", d.passed, d.warned, d.failed)
+	if d.failed > 0 {
+		return fmt.Errorf("%d checks failed", d.failed)
+	}
+	return nil
+}
+
+// printUsage prints doctor command usage
+func (d *DoctorCommand) printUsage() {
+	fmt.Println(`SETUP DIAGNOSTICS
+
+USAGE:
+    gnostream doctor
+
+Checks FFmpeg/ffprobe, the config file, the Nostr key, relay reachability, the RTMP and HTTP
+ports and the stream data directories, and prints a pass/fail report with hints. Run it with
+the server stopped, otherwise its ports are reported as in use.
+
+EXAMPLES:
+    gnostream doctor
+    gnostream --config /etc/gnostream/config.yml doctor`)
+}
+
+func (d *DoctorCommand) section(title string) {
+	fmt.Println()
+	fmt.Println(title)
+}
+
+func (d *DoctorCommand) pass(format string, args ...interface{}) {
+	d.passed++
+	fmt.Printf("   ✅ %s\n", fmt.Sprintf(format, args...))
+}
+
+func (d *DoctorCommand) warn(hint, format string, args ...interface{}) {
+	d.warned++
+	fmt.Printf("   ⚠️  %s\n", fmt.Sprintf(format, args...))
+	if hint != "" {
+		fmt.Printf("      💡 %s\n", hint)
+	}
+}
+
+func (d *DoctorCommand) fail(hint, format string, args ...interface{}) {
+	d.failed++
+	fmt.Printf("   ❌ %s\n", fmt.Sprintf(format, args...))
+	if hint != "" {
+		fmt.Printf("      💡 %s\n", hint)
+	}
+}
+
+// checkConfig reports whether the config loaded and lists its warnings
+func (d *DoctorCommand) checkConfig() {
+	d.section("⚙️  Configuration")
+
+	if d.loadErr != nil {
+		d.fail(fmt.Sprintf("Copy config.example.yml to %s (and stream-info.example.yml to stream-info.yml), or pass --config", d.configPath),
+			"%s could not be loaded: %v", d.configPath, d.loadErr)
+		return
+	}
+	d.pass("Loaded %s (stream info: %s)", d.config.Path(), d.config.StreamInfoPath)
+
+	for _, warning := range d.config.Warnings() {
+		d.warn("", "%s", warning)
+	}
+}
+
+// checkBinaries checks that ffmpeg and ffprobe run and reports their versions
+func (d *DoctorCommand) checkBinaries() {
+	d.section("🎞️  FFmpeg")
+
+	ffmpeg, ffprobe := "ffmpeg", "ffprobe"
+	if d.config != nil {
+		ffmpeg, ffprobe = d.config.FFmpegBinary(), d.config.FFprobeBinary()
+	}
+
+	for _, binary := range []struct{ name, path, setting string }{
+		{"ffmpeg", ffmpeg, "ffmpeg.binary"},
+		{"ffprobe", ffprobe, "ffprobe.binary"},
+	} {
+		version, err := binaryVersion(binary.path)
+		if err != nil {
+			d.fail(fmt.Sprintf("Install FFmpeg (it ships ffprobe) and make sure it is on PATH, or set %s", binary.setting),
+				"%s not usable (%s): %v", binary.name, binary.path, err)
+			continue
+		}
+		d.pass("%s", version)
+	}
+}
+
+// binaryVersion returns the first line of "<binary> -version"
+func binaryVersion(binary string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, binary, "-version").Output()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(output), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// checkKeys checks that the Nostr key resolves and derives the public key events are signed with
+func (d *DoctorCommand) checkKeys() {
+	d.section("🔑 Nostr key")
+
+	nostrConfig := &d.config.Nostr
+	privateKeyHex, err := nostrConfig.PrivateKeyHex()
+	if err != nil {
+		d.fail(fmt.Sprintf("Check the key in %s (an nsec1... or 64-character hex key)", nostrConfig.PrivateKeySource()),
+			"Private key could not be read: %v", err)
+		return
+	}
+
+	publicKey, err := nostrConfig.OwnerPublicKey()
+	switch {
+	case err != nil:
+		d.fail("Set nostr.private_key to a valid nsec, or nostr.public_key to a valid npub", "Public key could not be derived: %v", err)
+		return
+	case publicKey == "":
+		d.fail(fmt.Sprintf("Set nostr.private_key, nostr.private_key_file or $%s", config.PrivateKeyEnv),
+			"No Nostr key configured - stream events won't be published")
+		return
+	}
+
+	npub, _ := tools.EncodePubkey(publicKey)
+	if privateKeyHex == "" {
+		d.warn("Stream events are only published while the owner's browser is open to sign them",
+			"No private key; browser signing as %s", npub)
+		return
+	}
+	d.pass("Private key from %s signs as %s", nostrConfig.PrivateKeySource(), npub)
+}
+
+// checkRelays validates relay URLs and opens a quick connection to each
+func (d *DoctorCommand) checkRelays() {
+	d.section("🌐 Nostr relays")
+
+	relays := d.config.Nostr.AllRelays()
+	if len(relays) == 0 {
+		d.fail("Add relays under nostr.relays, e.g. wss://relay.damus.io", "No relays configured")
+		return
+	}
+
+	errs := make([]error, len(relays))
+	var wg sync.WaitGroup
+	for i, relay := range relays {
+		if err := config.ValidateRelayURL(relay); err != nil {
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int, relay string) {
+			defer wg.Done()
+			errs[i] = dialRelay(relay)
+		}(i, relay)
+	}
+	wg.Wait()
+
+	reachable := 0
+	for i, relay := range relays {
+		if errs[i] != nil {
+			d.warn("", "%s unreachable: %v", relay, errs[i])
+			continue
+		}
+		reachable++
+		d.pass("%s reachable", relay)
+	}
+	if reachable == 0 {
+		d.fail("Check the machine's internet connection or firewall, or try other relays", "No relay is reachable")
+	}
+}
+
+// dialRelay opens (and closes) a TCP connection to a relay, with TLS for wss://
+func dialRelay(relay string) error {
+	parsed, err := url.Parse(relay)
+	if err != nil {
+		return err
+	}
+
+	host := parsed.Host
+	if parsed.Port() == "" {
+		port := "80"
+		if parsed.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(parsed.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: relayDialTimeout}
+	var conn net.Conn
+	if parsed.Scheme == "wss" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: parsed.Hostname()})
+	} else {
+		conn, err = dialer.Dial("tcp", host)
+	}
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// checkPorts checks that the HTTP and RTMP ports can be bound
+func (d *DoctorCommand) checkPorts() {
+	d.section("🔌 Ports")
+
+	checkPort := func(name, host string, port int, setting string) {
+		address := net.JoinHostPort(host, strconv.Itoa(port))
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			d.fail(fmt.Sprintf("Stop whatever holds the port (often a running gnostream or a leftover ffmpeg) or change %s", setting),
+				"%s port %s is not available: %v", name, address, err)
+			return
+		}
+		listener.Close()
+		d.pass("%s port %s is free", name, address)
+	}
+
+	checkPort("HTTP", d.config.Server.Host, d.config.Server.Port, "server.port")
+
+	rtmpDefaults := d.config.GetRTMPDefaults()
+	if !rtmpDefaults.Enabled {
+		d.warn("", "RTMP ingest is disabled")
+		return
+	}
+	checkPort("RTMP", rtmpDefaults.Host, rtmpDefaults.Port, "rtmp.port")
+}
+
+// checkDirectories checks that the stream data directories exist (or can be created) and are writable
+func (d *DoctorCommand) checkDirectories() {
+	d.section("📁 Directories")
+
+	streamDefaults := d.config.GetStreamDefaults()
+	directories := []struct{ name, path string }{
+		{"Output", streamDefaults.OutputDir},
+		{"Archive", streamDefaults.ArchiveDir},
+	}
+	if d.config.Stream.Offline.Enabled() {
+		directories = append(directories, struct{ name, path string }{"Offline placeholder", streamDefaults.OfflineDir})
+	}
+
+	for _, dir := range directories {
+		if err := checkWritable(dir.path); err != nil {
+			d.fail("Fix the directory's permissions or point storage.data_dir somewhere writable",
+				"%s directory %s is not writable: %v", dir.name, dir.path, err)
+			continue
+		}
+		d.pass("%s directory %s is writable", dir.name, dir.path)
+	}
+}
+
+// checkWritable creates dir if needed and writes and removes a probe file in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
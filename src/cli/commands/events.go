@@ -2,11 +2,13 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,8 @@ import (
 	
 	"gnostream/src/config"
 	"gnostream/src/nostr"
+	"gnostream/src/stream"
+	"gnostream/src/util"
 )
 
 // EventsCommand handles Nostr event management
@@ -22,6 +26,9 @@ type EventsCommand struct {
 	nostrClient nostr.Client
 }
 
+// relayFetchTimeout bounds how long the CLI waits for relays that never send EOSE
+const relayFetchTimeout = 5 * time.Second
+
 // NewEventsCommand creates a new events command
 func NewEventsCommand(cfg *config.Config) *EventsCommand {
 	return &EventsCommand{
@@ -78,8 +85,11 @@ SUBCOMMANDS:
     search <query>      Search events by title/summary
     delete <id>         Delete specific event by ID
     show <id>           Show detailed event information
-    publish <type>      Publish new event (start|end|update)
+    publish <type>      Republish the live stream's event (start|end|update),
+                        publish your NIP-65 relay list (relays)
+                        or announce an upcoming stream (planned)
     deletions           List deletion requests you've sent
+                        (--resend/--purge re-broadcasts them to all relays)
 
 OPTIONS:
     --limit <n>         Limit number of results (default: 20)
@@ -92,7 +102,11 @@ EXAMPLES:
     gnostream events search "gaming"
     gnostream events delete 1234567890abcdef
     gnostream events show 1234567890abcdef
-    gnostream events publish update`)
+    gnostream events publish update
+    gnostream events publish relays
+    gnostream events deletions --purge
+    gnostream events publish planned --starts "2025-06-01 20:00" --title "Speedrun night"
+    gnostream events publish planned cancel`)
 }
 
 // initNostrClient initializes the Nostr client
@@ -137,7 +151,9 @@ func (e *EventsCommand) handleList(args []string) error {
 	}
 
 	// Fetch events from relays
-	events, err := e.fetchStreamEvents(limit, statusFilter, recent)
+	ctx, cancel := context.WithTimeout(context.Background(), relayFetchTimeout)
+	defer cancel()
+	events, err := e.fetchStreamEvents(ctx, limit, statusFilter, recent)
 	if err != nil {
 		return fmt.Errorf("failed to fetch events: %w", err)
 	}
@@ -177,7 +193,9 @@ func (e *EventsCommand) handleSearch(args []string) error {
 	query := strings.Join(args, " ")
 	fmt.Printf("🔍 Searching for events matching: %s\n", query)
 
-	events, err := e.fetchStreamEvents(50, "", false)
+	ctx, cancel := context.WithTimeout(context.Background(), relayFetchTimeout)
+	defer cancel()
+	events, err := e.fetchStreamEvents(ctx, 50, "", false)
 	if err != nil {
 		return err
 	}
@@ -218,7 +236,9 @@ func (e *EventsCommand) handleDelete(args []string) error {
 
 	// First verify the event exists
 	fmt.Println("🔍 Verifying event exists...")
-	event, err := e.fetchEventByID(eventID)
+	ctx, cancel := context.WithTimeout(context.Background(), relayFetchTimeout)
+	defer cancel()
+	event, err := e.fetchEventByID(ctx, eventID)
 	if err != nil {
 		return fmt.Errorf("❌ Cannot delete - event not found: %v", err)
 	}
@@ -228,6 +248,15 @@ func (e *EventsCommand) handleDelete(args []string) error {
 	// Create and publish deletion event with detailed response
 	deletionJSON, successfulRelays := e.nostrClient.BroadcastDeletionEventWithResponse(eventID, "Deleted via gnostream CLI")
 	
+	if deletionJSON == "" {
+		return fmt.Errorf("❌ Deletion request could not be signed or published - see the error above")
+	}
+
+	if e.config.Nostr.DryRun {
+		fmt.Println("🧪 Dry run (nostr.dry_run) - deletion request logged, not published")
+		return nil
+	}
+
 	if len(successfulRelays) == 0 {
 		return fmt.Errorf("❌ Deletion request failed - no relays accepted")
 	}
@@ -269,6 +298,41 @@ func (e *EventsCommand) handleDelete(args []string) error {
 	return nil
 }
 
+// archiveDateTolerance is how many days an archive folder's date may differ from the event
+// time: events without a start time are dated by their later created_at, and folders from older
+// versions were dated in the server's local time rather than UTC
+const archiveDateTolerance = 1
+
+// matchArchiveFolder reports whether an archive folder name (<M-D-YYYY>-<dtag>) belongs to the
+// stream with dtag around eventTime. The dtag must match exactly, so "123" never matches
+// "9-8-2025-1234". The date is compared in util.ArchiveLocation, the timezone folders are named
+// in, may be zero-padded and may be a day off either way.
+func matchArchiveFolder(name, dtag string, eventTime time.Time) bool {
+	folderDate, folderDtag, ok := util.ParseArchiveName(name)
+	if !ok || folderDtag != util.ArchiveDtag(dtag) {
+		return false
+	}
+
+	year, month, day := eventTime.In(util.ArchiveLocation).Date()
+	eventDate := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	days := int(eventDate.Sub(folderDate).Hours() / 24)
+	return days >= -archiveDateTolerance && days <= archiveDateTolerance
+}
+
+// streamStartTime returns the time an archive folder for event is dated by: its "starts" tag,
+// or its created_at for events without one
+func streamStartTime(event *NostrEvent) time.Time {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "starts" {
+			if starts, err := strconv.ParseInt(tag[1], 10, 64); err == nil && starts > 0 {
+				return time.Unix(starts, 0)
+			}
+			break
+		}
+	}
+	return time.Unix(event.CreatedAt, 0)
+}
+
 // checkAndDeleteRecordings checks for recordings associated with the event and prompts for deletion
 func (e *EventsCommand) checkAndDeleteRecordings(event *NostrEvent, eventID string) error {
 	// Extract dtag from event tags
@@ -285,10 +349,10 @@ func (e *EventsCommand) checkAndDeleteRecordings(event *NostrEvent, eventID stri
 		return nil
 	}
 	
-	eventTime := time.Unix(event.CreatedAt, 0)
+	eventTime := streamStartTime(event)
 	
 	// Archive path where recordings are stored
-	archivePath := "www/live/archive"
+	archivePath := e.config.GetStreamDefaults().ArchiveDir
 	
 	if _, err := os.Stat(archivePath); os.IsNotExist(err) {
 		fmt.Println("\n📁 No archive directory found")
@@ -297,28 +361,16 @@ func (e *EventsCommand) checkAndDeleteRecordings(event *NostrEvent, eventID stri
 	
 	var foundRecordings []string
 	
-	// Look for directories with pattern: date-dtag (e.g., "9-8-2025-315523")
-	datePattern := eventTime.Format("1-2-2006") // e.g., "9-8-2025"
-	expectedFolderPattern := datePattern + "-" + dtag
-	
-	err := filepath.Walk(archivePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Continue on errors
-		}
-		
-		if info.IsDir() && info.Name() != "archive" { // Skip the root archive dir itself
-			dirname := info.Name()
-			// Check if directory name matches the expected pattern
-			if dirname == expectedFolderPattern || strings.Contains(dirname, dtag) {
-				foundRecordings = append(foundRecordings, path)
-			}
-		}
-		return nil
-	})
-	
+	// Look for directories named date-dtag (e.g., "9-8-2025-315523")
+	entries, err := os.ReadDir(archivePath)
 	if err != nil {
 		return fmt.Errorf("error searching archive directory: %w", err)
 	}
+	for _, entry := range entries {
+		if entry.IsDir() && matchArchiveFolder(entry.Name(), dtag, eventTime) {
+			foundRecordings = append(foundRecordings, filepath.Join(archivePath, entry.Name()))
+		}
+	}
 	
 	if len(foundRecordings) == 0 {
 		fmt.Println("\n📁 No recordings found for this stream")
@@ -333,7 +385,7 @@ func (e *EventsCommand) checkAndDeleteRecordings(event *NostrEvent, eventID stri
 	}
 	
 	for _, recording := range foundRecordings {
-		size, err := calculateDirSize(recording)
+		size, err := util.DirSize(recording)
 		if err != nil {
 			fmt.Printf("⚠️ Could not calculate size for %s: %v\n", recording, err)
 			size = 0
@@ -345,9 +397,9 @@ func (e *EventsCommand) checkAndDeleteRecordings(event *NostrEvent, eventID stri
 		}{recording, size})
 	}
 
-	fmt.Printf("\n📁 Found %d potential recording(s) (Total: %s):\n", len(foundRecordings), formatBytes(totalSize))
+	fmt.Printf("\n📁 Found %d potential recording(s) (Total: %s):\n", len(foundRecordings), util.FormatBytes(totalSize))
 	for i, info := range recordingInfos {
-		fmt.Printf("   %d. %s (%s)\n", i+1, info.path, formatBytes(info.size))
+		fmt.Printf("   %d. %s (%s)\n", i+1, info.path, util.FormatBytes(info.size))
 	}
 	
 	// Prompt user for deletion
@@ -382,37 +434,24 @@ func (e *EventsCommand) checkAndDeleteRecordings(event *NostrEvent, eventID stri
 	return nil
 }
 
-// calculateDirSize calculates the total size of a directory
-func calculateDirSize(dirPath string) (int64, error) {
-	var size int64
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-		if !info.IsDir() {
-			size += info.Size()
-		}
-		return nil
-	})
-	return size, err
-}
+// handleDeletions lists deletion requests sent
+func (e *EventsCommand) handleDeletions(args []string) error {
+	// Parse options
+	limit := 20
+	resend := false
 
-// formatBytes formats byte size into human readable format
-func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--limit":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &limit)
+				i++
+			}
+		case "--resend", "--purge":
+			resend = true
+		}
 	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
 
-// handleDeletions lists deletion requests sent
-func (e *EventsCommand) handleDeletions(args []string) error {
 	fmt.Println("🔍 Fetching your deletion requests...")
 	
 	grainClient, ok := e.nostrClient.(*nostr.GrainClient)
@@ -421,7 +460,6 @@ func (e *EventsCommand) handleDeletions(args []string) error {
 	}
 
 	// Create filter for deletion events (kind 5)
-	limit := 20
 	limitPtr := &limit
 	filter := nostrTypes.Filter{
 		Kinds:   []int{5}, // NIP-09: Event Deletion
@@ -435,45 +473,12 @@ func (e *EventsCommand) handleDeletions(args []string) error {
 		return fmt.Errorf("failed to create subscription: %w", err)
 	}
 	
-	timeout := time.NewTimer(3 * time.Second)
-	defer timeout.Stop()
-	
-	// Graceful cleanup - close subscription after collection is done
-	defer func() {
-		if subscription != nil {
-			// Give a small delay for any pending messages to be processed
-			time.Sleep(100 * time.Millisecond)
-			subscription.Close()
-		}
-	}()
+	ctx, cancel := context.WithTimeout(context.Background(), relayFetchTimeout)
+	defer cancel()
 
 	var deletions []NostrEvent
-	collecting := true
-	
-	for collecting {
-		select {
-		case event, ok := <-subscription.Events:
-			if !ok {
-				collecting = false
-				break
-			}
-			
-			deletions = append(deletions, NostrEvent{
-				ID:        event.ID,
-				PubKey:    event.PubKey,
-				CreatedAt: event.CreatedAt,
-				Kind:      event.Kind,
-				Tags:      event.Tags,
-				Content:   event.Content,
-				Sig:       event.Sig,
-			})
-			
-		case <-subscription.Done:
-			collecting = false
-			
-		case <-timeout.C:
-			collecting = false
-		}
+	for _, event := range nostr.CollectEvents(ctx, subscription, 0) {
+		deletions = append(deletions, toNostrEvent(event))
 	}
 
 	if len(deletions) == 0 {
@@ -500,6 +505,56 @@ func (e *EventsCommand) handleDeletions(args []string) error {
 		fmt.Printf("%-64s %-20s %-30s\n", deletion.ID, created, targetID)
 	}
 
+	if resend {
+		return e.resendDeletions(grainClient, deletions)
+	}
+
+	return nil
+}
+
+// resendDeletions re-broadcasts the original signed deletion requests to every configured relay,
+// reaching relays that were offline (or dropped the request) the first time
+func (e *EventsCommand) resendDeletions(grainClient *nostr.GrainClient, deletions []NostrEvent) error {
+	fmt.Printf("\n📡 Re-sending %d deletion requests to %d relays...\n", len(deletions), len(e.config.Nostr.Relays))
+
+	failures := 0
+	for _, deletion := range deletions {
+		event := &nostrTypes.Event{
+			ID:        deletion.ID,
+			PubKey:    deletion.PubKey,
+			CreatedAt: deletion.CreatedAt,
+			Kind:      deletion.Kind,
+			Tags:      deletion.Tags,
+			Content:   deletion.Content,
+			Sig:       deletion.Sig,
+		}
+
+		fmt.Printf("\n🗑️  %s\n", deletion.ID)
+		results, err := grainClient.RepublishEvent(event)
+		if err != nil {
+			fmt.Printf("   ❌ Failed to publish: %v\n", err)
+			failures++
+			continue
+		}
+
+		for _, result := range results {
+			if result.Success {
+				fmt.Printf("   ✅ %s\n", result.RelayURL)
+			} else {
+				reason := result.Message
+				if result.Error != nil {
+					reason = result.Error.Error()
+				}
+				fmt.Printf("   ❌ %s: %s\n", result.RelayURL, reason)
+			}
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d deletion requests could not be published", failures)
+	}
+
+	fmt.Println("\n✅ Deletion requests re-sent")
 	return nil
 }
 
@@ -512,7 +567,9 @@ func (e *EventsCommand) handleShow(args []string) error {
 	eventID := args[0]
 	fmt.Printf("🔍 Fetching event details: %s\n", eventID)
 
-	event, err := e.fetchEventByID(eventID)
+	ctx, cancel := context.WithTimeout(context.Background(), relayFetchTimeout)
+	defer cancel()
+	event, err := e.fetchEventByID(ctx, eventID)
 	if err != nil {
 		return err
 	}
@@ -550,27 +607,137 @@ func (e *EventsCommand) handlePublish(args []string) error {
 	}
 
 	eventType := args[0]
-	fmt.Printf("📡 Publishing %s event...\n", eventType)
+	if eventType == "relays" {
+		return e.publishRelayList()
+	}
+	if eventType == "planned" {
+		return e.publishPlanned(args[1:])
+	}
+	if eventType != "start" && eventType != "end" && eventType != "update" {
+		return fmt.Errorf("unknown event type: %s (use: start|end|update|relays|planned)", eventType)
+	}
 
-	metadata := e.config.GetStreamMetadata()
+	metadata, err := e.liveStreamMetadata()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📡 Publishing %s event for stream %s...\n", eventType, metadata.Dtag)
 
 	switch eventType {
 	case "start":
 		e.nostrClient.BroadcastStartEvent(metadata)
 	case "end":
+		metadata.Status = "ended"
+		metadata.Ends = fmt.Sprintf("%d", time.Now().Unix())
 		e.nostrClient.BroadcastEndEvent(metadata)
 	case "update":
 		e.nostrClient.BroadcastUpdateEvent(metadata)
-	default:
-		return fmt.Errorf("unknown event type: %s (use: start|end|update)", eventType)
 	}
 
 	fmt.Printf("✅ %s event published successfully\n", strings.ToUpper(eventType))
 	return nil
 }
 
+// publishPlanned announces an upcoming stream; the live event later reuses its dtag
+func (e *EventsCommand) publishPlanned(args []string) error {
+	if len(args) > 0 && args[0] == "cancel" {
+		if err := stream.ClearPlannedStream(e.config); err != nil {
+			return fmt.Errorf("failed to clear planned stream: %w", err)
+		}
+		fmt.Println("✅ Planned stream cleared - the next stream gets a new dtag")
+		return nil
+	}
+
+	var startsValue, title, summary string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--starts":
+			if i+1 < len(args) {
+				startsValue = args[i+1]
+				i++
+			}
+		case "--title":
+			if i+1 < len(args) {
+				title = args[i+1]
+				i++
+			}
+		case "--summary":
+			if i+1 < len(args) {
+				summary = args[i+1]
+				i++
+			}
+		}
+	}
+
+	if startsValue == "" {
+		return fmt.Errorf("missing --starts <time> (unix seconds, RFC 3339 or \"YYYY-MM-DD HH:MM\")")
+	}
+	starts, err := stream.ParsePlannedStart(startsValue)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📅 Publishing planned stream for %s...\n", starts.Format(time.RFC1123))
+	metadata, err := stream.SchedulePlannedStream(e.config, e.nostrClient, title, summary, starts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Planned event published to %d relays\n", len(metadata.SuccessfulRelays))
+	fmt.Printf("   Title: %s\n", metadata.Title)
+	fmt.Printf("   Dtag:  %s (the live event will reuse it)\n", metadata.Dtag)
+	return nil
+}
+
+// publishRelayList publishes the configured relays as a NIP-65 relay list (kind 10002)
+func (e *EventsCommand) publishRelayList() error {
+	if !e.nostrClient.IsEnabled() {
+		return fmt.Errorf("nostr client not enabled - configure nostr.private_key first")
+	}
+
+	fmt.Printf("📡 Publishing relay list (%d write, %d read relays)...\n",
+		len(e.config.Nostr.WriteRelayList()), len(e.config.Nostr.ReadRelayList()))
+	for _, relay := range e.config.Nostr.AllRelays() {
+		fmt.Printf("   • %s\n", relay)
+	}
+
+	eventJSON, successfulRelays := e.nostrClient.BroadcastRelayListEventWithResponse()
+	if eventJSON == "" {
+		return fmt.Errorf("failed to publish relay list")
+	}
+
+	fmt.Printf("✅ Relay list published to %d relays\n", len(successfulRelays))
+	return nil
+}
+
+// liveStreamMetadata merges the current stream-info.yml with the live stream's runtime metadata
+// (dtag, start time, URLs) so CLI-published events target the real live event
+func (e *EventsCommand) liveStreamMetadata() (*config.StreamMetadata, error) {
+	metadataPath := e.config.GetStreamDefaults().MetadataPath
+
+	live, err := config.LoadStreamMetadata(metadataPath)
+	if err != nil || live.Dtag == "" {
+		return nil, fmt.Errorf("no live stream found (%s missing or incomplete) - start streaming first", metadataPath)
+	}
+	if live.Status != "live" {
+		return nil, fmt.Errorf("stream %s is not live (status: %s)", live.Dtag, live.Status)
+	}
+
+	// Editable fields come from stream-info.yml, runtime fields from the live stream
+	metadata := e.config.GetStreamMetadata()
+	metadata.Dtag = live.Dtag
+	metadata.Status = live.Status
+	metadata.Starts = live.Starts
+	metadata.Ends = live.Ends
+	metadata.StreamURL = live.StreamURL
+	metadata.RecordingURL = live.RecordingURL
+
+	return metadata, nil
+}
+
 // fetchStreamEvents fetches stream events from Nostr relays
-func (e *EventsCommand) fetchStreamEvents(limit int, statusFilter string, recent bool) ([]NostrEvent, error) {
+func (e *EventsCommand) fetchStreamEvents(ctx context.Context, limit int, statusFilter string, recent bool) ([]NostrEvent, error) {
 	grainClient, ok := e.nostrClient.(*nostr.GrainClient)
 	if !ok || !grainClient.IsEnabled() {
 		return nil, fmt.Errorf("grain client not available or not enabled")
@@ -595,96 +762,31 @@ func (e *EventsCommand) fetchStreamEvents(limit int, statusFilter string, recent
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subscription: %w", err)
 	}
-	
-	// Use a shorter timeout to prevent blocking
-	var events []NostrEvent
-	timeout := time.NewTimer(3 * time.Second) // Reduced timeout
-	defer timeout.Stop()
 
-	// Start collecting events with timeout and proper cleanup
-	eventCount := 0
-	collecting := true
-	
-	// Graceful cleanup - close subscription after collection is done
-	defer func() {
-		if subscription != nil {
-			// Give a small delay for any pending messages to be processed
-			time.Sleep(100 * time.Millisecond)
-			subscription.Close()
-		}
-	}()
-	
-	for collecting {
-		select {
-		case event, ok := <-subscription.Events:
-			if !ok {
-				// Channel closed, we're done
-				collecting = false
-				break
-			}
-			
-			// Filter by status if specified
-			if statusFilter != "" {
-				status := ""
-				for _, tag := range event.Tags {
-					if len(tag) >= 2 && tag[0] == "status" {
-						status = tag[1]
-						break
-					}
-				}
-				if status != statusFilter {
-					continue
-				}
-			}
-			
-			// Convert grain event to our NostrEvent type
-			nostrEvent := NostrEvent{
-				ID:        event.ID,
-				PubKey:    event.PubKey,
-				CreatedAt: event.CreatedAt,
-				Kind:      event.Kind,
-				Tags:      event.Tags,
-				Content:   event.Content,
-				Sig:       event.Sig,
-			}
-			events = append(events, nostrEvent)
-			eventCount++
-			
-			// If we've collected enough events, stop collecting
-			if eventCount >= limit {
-				collecting = false
-			}
-			
-		case <-subscription.Done:
-			// Subscription finished
-			collecting = false
-			
-		case <-timeout.C:
-			// Timeout reached
-			collecting = false
-		}
-	}
-	
-	// Deduplicate by event ID
-	seen := make(map[string]bool)
-	var deduped []NostrEvent
-	for _, event := range events {
-		if !seen[event.ID] {
-			seen[event.ID] = true
-			deduped = append(deduped, event)
+	// Collect until every relay sent its stored events (deduplicated across relays)
+	var events []NostrEvent
+	for _, event := range nostr.CollectEvents(ctx, subscription, 0) {
+		// Filter by status if specified
+		if statusFilter != "" && eventTagValue(event.Tags, "status") != statusFilter {
+			continue
 		}
+		events = append(events, toNostrEvent(event))
 	}
 	
 	// Sort events by date (newest first)
-	sort.Slice(deduped, func(i, j int) bool {
-		return deduped[i].CreatedAt > deduped[j].CreatedAt
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt > events[j].CreatedAt
 	})
+
+	if len(events) > limit {
+		events = events[:limit]
+	}
 	
-	return deduped, nil
+	return events, nil
 }
 
 // fetchEventByID fetches a specific event by ID
-func (e *EventsCommand) fetchEventByID(eventID string) (*NostrEvent, error) {
+func (e *EventsCommand) fetchEventByID(ctx context.Context, eventID string) (*NostrEvent, error) {
 	grainClient, ok := e.nostrClient.(*nostr.GrainClient)
 	if !ok || !grainClient.IsEnabled() {
 		return nil, fmt.Errorf("grain client not available or not enabled")
@@ -704,51 +806,38 @@ func (e *EventsCommand) fetchEventByID(eventID string) (*NostrEvent, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subscription: %w", err)
 	}
-	
-	timeout := time.NewTimer(3 * time.Second) // Reduced timeout
-	defer timeout.Stop()
-	
-	// Graceful cleanup - close subscription after collection is done
-	defer func() {
-		if subscription != nil {
-			// Give a small delay for any pending messages to be processed
-			time.Sleep(100 * time.Millisecond)
-			subscription.Close()
-		}
-	}()
-
-	searching := true
-	for searching {
-		select {
-		case event, ok := <-subscription.Events:
-			if !ok {
-				// Channel closed, event not found
-				return nil, fmt.Errorf("event not found: %s", eventID)
-			}
-			
-			// Convert grain event to our NostrEvent type
-			nostrEvent := &NostrEvent{
-				ID:        event.ID,
-				PubKey:    event.PubKey,
-				CreatedAt: event.CreatedAt,
-				Kind:      event.Kind,
-				Tags:      event.Tags,
-				Content:   event.Content,
-				Sig:       event.Sig,
-			}
-			return nostrEvent, nil
-			
-		case <-subscription.Done:
-			// Subscription finished without finding event
-			searching = false
-			
-		case <-timeout.C:
-			// Timeout reached
-			searching = false
+
+	// Stops at the first match, or once every relay has answered without one
+	events := nostr.CollectEvents(ctx, subscription, 1)
+	if len(events) == 0 {
+		return nil, fmt.Errorf("event not found: %s", eventID)
+	}
+
+	event := toNostrEvent(events[0])
+	return &event, nil
+}
+
+// toNostrEvent converts a grain event to the CLI's NostrEvent type
+func toNostrEvent(event *nostrTypes.Event) NostrEvent {
+	return NostrEvent{
+		ID:        event.ID,
+		PubKey:    event.PubKey,
+		CreatedAt: event.CreatedAt,
+		Kind:      event.Kind,
+		Tags:      event.Tags,
+		Content:   event.Content,
+		Sig:       event.Sig,
+	}
+}
+
+// eventTagValue returns the first value of a tag, or "" if the event doesn't have it
+func eventTagValue(tags [][]string, name string) string {
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == name {
+			return tag[1]
 		}
 	}
-	
-	return nil, fmt.Errorf("event not found: %s", eventID)
+	return ""
 }
 
 // filterEventsByQuery filters events by search query
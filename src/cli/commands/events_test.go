@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchArchiveFolder(t *testing.T) {
+	started := time.Date(2025, 9, 8, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		folder    string
+		dtag      string
+		eventTime time.Time
+		want      bool
+	}{
+		{name: "exact", folder: "9-8-2025-123", dtag: "123", eventTime: started, want: true},
+		{name: "zero-padded date", folder: "09-08-2025-123", dtag: "123", eventTime: started, want: true},
+		{name: "longer dtag", folder: "9-8-2025-1234", dtag: "123", eventTime: started, want: false},
+		{name: "shorter dtag", folder: "9-8-2025-12", dtag: "123", eventTime: started, want: false},
+		{name: "dtag with dashes", folder: "9-8-2025-my-stream", dtag: "my-stream", eventTime: started, want: true},
+		{name: "dtag is a suffix", folder: "9-8-2025-other-stream", dtag: "stream", eventTime: started, want: false},
+		{name: "unsafe dtag", folder: "9-8-2025-etcpasswd", dtag: "../etc/passwd", eventTime: started, want: true},
+		{name: "day before", folder: "9-7-2025-123", dtag: "123", eventTime: started, want: true},
+		{name: "day after", folder: "9-9-2025-123", dtag: "123", eventTime: started, want: true},
+		{name: "two days off", folder: "9-6-2025-123", dtag: "123", eventTime: started, want: false},
+		{name: "across a month", folder: "8-31-2025-123", dtag: "123", eventTime: time.Date(2025, 9, 1, 1, 0, 0, 0, time.UTC), want: true},
+		{name: "not an archive name", folder: "recordings-123", dtag: "123", eventTime: started, want: false},
+		{name: "traversal", folder: "..", dtag: "..", eventTime: started, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchArchiveFolder(tt.folder, tt.dtag, tt.eventTime); got != tt.want {
+				t.Errorf("matchArchiveFolder(%q, %q, %v) = %t, want %t", tt.folder, tt.dtag, tt.eventTime, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchArchiveFolderAcrossMidnight(t *testing.T) {
+	// Started at 11:55 PM UTC-5 (4:55 AM UTC on March 8) and ended after midnight local time
+	newYork := time.FixedZone("UTC-5", -5*60*60)
+	event := &NostrEvent{
+		CreatedAt: time.Date(2026, 3, 8, 0, 5, 0, 0, newYork).Unix(),
+		Tags: [][]string{
+			{"d", "late-show"},
+			{"starts", "1772945700"}, // 2026-03-08 04:55:00 UTC
+		},
+	}
+
+	eventTime := streamStartTime(event)
+	if !eventTime.Equal(time.Date(2026, 3, 7, 23, 55, 0, 0, newYork)) {
+		t.Fatalf("streamStartTime = %v, want the starts tag", eventTime)
+	}
+	if !matchArchiveFolder("3-8-2026-late-show", "late-show", eventTime) {
+		t.Error("the folder dated by the UTC start did not match")
+	}
+}
+
+func TestStreamStartTime(t *testing.T) {
+	createdAt := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		tags [][]string
+		want time.Time
+	}{
+		{name: "starts tag", tags: [][]string{{"starts", "1772928000"}}, want: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{name: "no starts tag", tags: [][]string{{"d", "stream"}}, want: createdAt},
+		{name: "malformed starts", tags: [][]string{{"starts", "yesterday"}}, want: createdAt},
+		{name: "zero starts", tags: [][]string{{"starts", "0"}}, want: createdAt},
+		{name: "starts without value", tags: [][]string{{"starts"}}, want: createdAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &NostrEvent{CreatedAt: createdAt.Unix(), Tags: tt.tags}
+			if got := streamStartTime(event); !got.Equal(tt.want) {
+				t.Errorf("streamStartTime = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,17 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"gnostream/src/config"
+	"gnostream/src/nostr"
+	"gnostream/src/util"
 )
 
 // StreamCommand handles stream management and debugging
@@ -40,6 +46,8 @@ func (s *StreamCommand) Execute(args []string) error {
 		return s.handleFiles()
 	case "logs":
 		return s.handleLogs(args[1:])
+	case "restart":
+		return s.handleRestart()
 	case "--help", "help":
 		s.printUsage()
 		return nil
@@ -63,12 +71,14 @@ SUBCOMMANDS:
     debug               Show debug information
     files               List stream files and sizes
     logs                Show recent log entries
+    restart             Restart the running server's FFmpeg process
 
 EXAMPLES:
     gnostream stream status
     gnostream stream info
     gnostream stream debug
-    gnostream stream files`)
+    gnostream stream files
+    gnostream stream restart`)
 }
 
 // handleStatus shows current stream status
@@ -89,7 +99,7 @@ func (s *StreamCommand) handleStatus() error {
 	fmt.Printf("📁 Output Directory: %s\n", streamDefaults.OutputDir)
 
 	// Check for metadata
-	metadataPath := filepath.Join(streamDefaults.OutputDir, "metadata.json")
+	metadataPath := streamDefaults.MetadataPath
 	if _, err := os.Stat(metadataPath); err == nil {
 		fmt.Println("📄 Metadata: Available")
 	} else {
@@ -123,13 +133,15 @@ func (s *StreamCommand) handleInfo() error {
 	fmt.Printf("  Title:       %s\n", s.config.StreamInfo.Title)
 	fmt.Printf("  Summary:     %s\n", s.config.StreamInfo.Summary)
 	fmt.Printf("  Tags:        %v\n", s.config.StreamInfo.Tags)
+	fmt.Printf("  Category:    %s\n", s.config.StreamInfo.Category)
 	fmt.Printf("  Recording:   %t\n", s.config.StreamInfo.Record)
 	fmt.Println()
 
-	// HLS Configuration
+	// HLS Configuration, as FFmpeg uses it
+	hlsConfig := s.config.GetHLSConfig()
 	fmt.Println("🎬 HLS SETTINGS:")
-	fmt.Printf("  Segment Time:   %d seconds\n", s.config.StreamInfo.HLS.SegmentTime)
-	fmt.Printf("  Playlist Size:  %d segments\n", s.config.StreamInfo.HLS.PlaylistSize)
+	fmt.Printf("  Segment Time:   %d seconds\n", hlsConfig.SegmentTime)
+	fmt.Printf("  Playlist Size:  %d segments\n", hlsConfig.PlaylistSize)
 	fmt.Println()
 
 	// Stream paths
@@ -180,7 +192,7 @@ func (s *StreamCommand) handleDebug() error {
 
 	// Check for active stream files
 	fmt.Println("🎬 STREAM FILES:")
-	streamFiles := []string{"stream.m3u8", "metadata.json"}
+	streamFiles := []string{"stream.m3u8", config.MetadataFileName}
 	
 	for _, file := range streamFiles {
 		path := filepath.Join(streamDefaults.OutputDir, file)
@@ -193,7 +205,7 @@ func (s *StreamCommand) handleDebug() error {
 	fmt.Println()
 
 	// Check metadata content
-	metadataPath := filepath.Join(streamDefaults.OutputDir, "metadata.json")
+	metadataPath := streamDefaults.MetadataPath
 	if data, err := os.ReadFile(metadataPath); err == nil {
 		var metadata map[string]interface{}
 		if json.Unmarshal(data, &metadata) == nil {
@@ -246,6 +258,67 @@ func (s *StreamCommand) handleLogs(args []string) error {
 	return nil
 }
 
+// handleRestart asks the running server to kill and relaunch its FFmpeg process
+func (s *StreamCommand) handleRestart() error {
+	fmt.Println("🔄 RESTARTING FFMPEG")
+	fmt.Println()
+
+	privateKeyHex, err := s.config.Nostr.PrivateKeyHex()
+	if err != nil {
+		return fmt.Errorf("failed to decode private key: %w", err)
+	}
+	if privateKeyHex == "" {
+		return fmt.Errorf("nostr.private_key is required to authenticate with the server")
+	}
+	signer, err := nostr.NewLocalSigner(privateKeyHex)
+	if err != nil {
+		return err
+	}
+
+	// The server usually binds all interfaces; talk to it over loopback
+	host := s.config.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	requestURL := fmt.Sprintf("http://%s/api/stream/restart-ffmpeg", net.JoinHostPort(host, strconv.Itoa(s.config.Server.Port)))
+
+	authHeader, err := nostr.BuildHTTPAuthHeader(signer, requestURL, http.MethodPost)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	// Restarting waits for the RTMP port to be freed, so allow a generous timeout
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach server at %s (is it running?): %w", requestURL, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response from server (HTTP %d): %w", resp.StatusCode, err)
+	}
+
+	if !result.Success {
+		fmt.Printf("❌ Restart failed: %s\n", result.Error)
+		return fmt.Errorf("restart failed: %s", result.Error)
+	}
+
+	fmt.Println("✅ FFmpeg restarted - the RTMP server is listening again")
+	return nil
+}
+
 // listDirectory lists files in a directory with sizes
 func (s *StreamCommand) listDirectory(dirPath string) error {
 	entries, err := os.ReadDir(dirPath)
@@ -273,14 +346,14 @@ func (s *StreamCommand) listDirectory(dirPath string) error {
 				fileCount++
 				
 				// Format file size
-				sizeStr := formatFileSize(size)
+				sizeStr := util.FormatBytes(size)
 				ext := strings.ToLower(filepath.Ext(entry.Name()))
 				
 				var icon string
 				switch ext {
 				case ".m3u8":
 					icon = "🎬"
-				case ".ts":
+				case ".ts", ".m4s":
 					icon = "🎞️"
 				case ".json":
 					icon = "📄"
@@ -294,7 +367,7 @@ func (s *StreamCommand) listDirectory(dirPath string) error {
 	}
 
 	if fileCount > 0 {
-		fmt.Printf("   📊 Total: %d files, %s\n", fileCount, formatFileSize(totalSize))
+		fmt.Printf("   📊 Total: %d files, %s\n", fileCount, util.FormatBytes(totalSize))
 	}
 
 	return nil
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ABRConfig controls adaptive bitrate output (several video renditions under one master playlist)
+type ABRConfig struct {
+	Auto      bool `yaml:"auto"`       // Build a rendition ladder from the input resolution, never upscaling
+	MaxHeight int  `yaml:"max_height"` // Tallest rendition when the input can't be probed first (default 1080)
+}
+
+// Rendition is one rung of the ABR ladder
+type Rendition struct {
+	Name         string // Variant name, also used in the media playlist name (stream_<name>.m3u8)
+	Height       int
+	VideoBitrate int // Peak video bitrate in kbps (caps the CRF encode)
+}
+
+// ladderRungs are the standard rendition heights and their peak bitrates, tallest first
+var ladderRungs = []Rendition{
+	{Height: 2160, VideoBitrate: 14000},
+	{Height: 1440, VideoBitrate: 8000},
+	{Height: 1080, VideoBitrate: 5000},
+	{Height: 720, VideoBitrate: 2800},
+	{Height: 480, VideoBitrate: 1400},
+}
+
+// minLadderHeight is the smallest rendition generated, and maxLadderRungs caps the encode load
+const (
+	minLadderHeight  = 480
+	maxLadderRungs   = 3
+	defaultABRHeight = 1080
+)
+
+// ABREnabled reports whether ABR output should be produced. It is skipped for multi-track audio
+// and low-latency mode, which expect a single video playlist, and when extra_args copy the video.
+func (cfg *Config) ABREnabled(hls *HLSConfig) bool {
+	return cfg.ABR.Auto && hls.AudioTrackCount() <= 1 && !hls.LowLatency && !cfg.FFmpeg.copiesCodec("v")
+}
+
+// ABRLadder returns the renditions for an input sourceHeight pixels tall, tallest first: the
+// source height (capped at abr.max_height and ffmpeg.max_height) followed by the standard rungs
+// below it, down to 480p. A sourceHeight of 0 means the input wasn't probed and the cap is
+// assumed. A source at or below 480p gets a single rendition.
+func (cfg *Config) ABRLadder(sourceHeight int) []Rendition {
+	top := cfg.ABR.MaxHeight
+	if top <= 0 {
+		top = defaultABRHeight
+	}
+	if capHeight := cfg.FFmpeg.maxHeight(); capHeight > 0 && capHeight < top {
+		top = capHeight
+	}
+	if sourceHeight > 0 && sourceHeight < top {
+		top = sourceHeight
+	}
+	top -= top % 2 // H.264 needs even dimensions
+
+	ladder := []Rendition{{Name: fmt.Sprintf("%dp", top), Height: top, VideoBitrate: rungBitrate(top)}}
+	for _, rung := range ladderRungs {
+		if len(ladder) == maxLadderRungs {
+			break
+		}
+		if rung.Height < top && rung.Height >= minLadderHeight {
+			rung.Name = fmt.Sprintf("%dp", rung.Height)
+			ladder = append(ladder, rung)
+		}
+	}
+	return ladder
+}
+
+// rungBitrate returns the peak bitrate for a rendition height, scaling by pixel count from the
+// nearest standard rung below it for heights in between
+func rungBitrate(height int) int {
+	base := ladderRungs[len(ladderRungs)-1]
+	for _, rung := range ladderRungs {
+		if rung.Height == height {
+			return rung.VideoBitrate
+		}
+		if rung.Height < height {
+			base = rung
+			break
+		}
+	}
+	kbps := base.VideoBitrate * height * height / (base.Height * base.Height)
+	return (kbps + 50) / 100 * 100
+}
+
+// DescribeLadder formats a ladder for logging, e.g. "1080p@5000k, 720p@2800k"
+func DescribeLadder(ladder []Rendition) string {
+	parts := make([]string, len(ladder))
+	for i, r := range ladder {
+		parts[i] = fmt.Sprintf("%s@%dk", r.Name, r.VideoBitrate)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ABRArgs returns the FFmpeg arguments that split the video into ladder renditions, each paired
+// with the first audio track, written as stream_<name>.m3u8 media playlists under an output.m3u8
+// master. Scaling is capped at the input height, so an unprobed input is never upscaled.
+// ffmpeg.video_filters is applied once before the split, as -vf can't feed a complex filtergraph.
+func (cfg *Config) ABRArgs(ladder []Rendition) []string {
+	var graph strings.Builder
+	graph.WriteString("[0:v]")
+	if cfg.FFmpeg.VideoFilters != "" {
+		graph.WriteString(cfg.FFmpeg.VideoFilters + ",")
+	}
+	fmt.Fprintf(&graph, "split=%d", len(ladder))
+	for i := range ladder {
+		fmt.Fprintf(&graph, "[v%d]", i)
+	}
+	for i, r := range ladder {
+		fmt.Fprintf(&graph, ";[v%d]scale=-2:'min(%d,ih)'[vout%d]", i, r.Height, i)
+	}
+
+	args := []string{"-filter_complex", graph.String()}
+	variants := make([]string, len(ladder))
+	for i, r := range ladder {
+		args = append(args,
+			"-map", fmt.Sprintf("[vout%d]", i),
+			"-map", "0:a:0",
+			fmt.Sprintf("-maxrate:v:%d", i), fmt.Sprintf("%dk", r.VideoBitrate),
+			fmt.Sprintf("-bufsize:v:%d", i), fmt.Sprintf("%dk", r.VideoBitrate*2),
+		)
+		variants[i] = fmt.Sprintf("v:%d,a:%d,name:%s", i, i, r.Name)
+	}
+
+	return append(args, "-var_stream_map", strings.Join(variants, " "), "-master_pl_name", MasterPlaylistName)
+}
+
+// AudioFilterArgs returns only the -af arguments, for ABR output where video filters go into the
+// complex filtergraph instead
+func (f *FFmpegConfig) AudioFilterArgs() []string {
+	if f.AudioFilters != "" && !f.copiesCodec("a") {
+		return []string{"-af", f.AudioFilters}
+	}
+	return nil
+}
+
+// ABRMediaPlaylistName returns the media playlist of the tallest rendition, used to watch stream activity
+func ABRMediaPlaylistName(ladder []Rendition) string {
+	return "stream_" + ladder[0].Name + ".m3u8"
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessConfig restricts which client IPs may watch (HLS and archives) and, optionally, use the API
+type AccessConfig struct {
+	Allowlist []string `yaml:"allowlist"` // CIDRs or single IPs; when set, everything else is denied
+	Denylist  []string `yaml:"denylist"`  // CIDRs or single IPs that are always denied
+	API       bool     `yaml:"api"`       // Also apply the lists to /api/ and /ws/ routes
+
+	TrustedProxies []string `yaml:"trusted_proxies"` // CIDRs or IPs of reverse proxies whose X-Forwarded-For/X-Real-IP is believed
+
+	RequireToken bool   `yaml:"require_token"` // HLS playlists and segments need a signed access token
+	TokenSecret  string `yaml:"token_secret"`  // HMAC key for access tokens (generated and kept in the data dir when empty)
+
+	generatedSecret []byte // Key loaded by LoadTokenSecret when token_secret is empty
+}
+
+// IPRules is a parsed allowlist and denylist
+type IPRules struct {
+	allow       []netip.Prefix
+	deny        []netip.Prefix
+	allowlisted bool // An allowlist is configured, even if none of its entries parsed
+}
+
+// IPRules parses the allowlist and denylist. Invalid entries are skipped and reported in the
+// error, so one typo doesn't drop the rest of the list. An allowlist stays deny-by-default
+// when every entry is invalid: a typo never opens a private stream to everyone.
+func (a *AccessConfig) IPRules() (*IPRules, error) {
+	allow, allowErr := ParseCIDRs(a.Allowlist)
+	deny, denyErr := ParseCIDRs(a.Denylist)
+	allowlisted := slices.ContainsFunc(a.Allowlist, func(entry string) bool { return strings.TrimSpace(entry) != "" })
+	return &IPRules{allow: allow, deny: deny, allowlisted: allowlisted}, errors.Join(allowErr, denyErr)
+}
+
+// DeniesAll reports whether an allowlist is configured but none of its entries is valid
+func (r *IPRules) DeniesAll() bool {
+	return r.allowlisted && len(r.allow) == 0
+}
+
+// TrustedProxyRanges parses trusted_proxies. Invalid entries are skipped and reported in the error.
+func (a *AccessConfig) TrustedProxyRanges() ([]netip.Prefix, error) {
+	return ParseCIDRs(a.TrustedProxies)
+}
+
+// ParseCIDRs parses CIDR ranges; a bare IP is taken as a single-address range
+func ParseCIDRs(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	var errs []error
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid CIDR %q", entry))
+				continue
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid IP %q", entry))
+			continue
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, errors.Join(errs...)
+}
+
+// Active reports whether any rule is configured
+func (r *IPRules) Active() bool {
+	return r.allowlisted || len(r.deny) > 0
+}
+
+// Allows reports whether a client IP may connect: it must not be denylisted and, when an
+// allowlist is set, must be on it. An IP that can't be parsed only passes without an allowlist.
+func (r *IPRules) Allows(ip string) bool {
+	addr, err := netip.ParseAddr(strings.Trim(ip, "[]"))
+	if err != nil {
+		return !r.allowlisted
+	}
+	addr = addr.Unmap().WithZone("")
+
+	for _, prefix := range r.deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if !r.allowlisted {
+		return true
+	}
+	for _, prefix := range r.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Access token errors
+var (
+	ErrTokenMissing = errors.New("access token required")
+	ErrTokenInvalid = errors.New("access token invalid")
+	ErrTokenExpired = errors.New("access token expired")
+)
+
+var (
+	generatedTokenSecret     []byte
+	generatedTokenSecretOnce sync.Once
+)
+
+// tokenSecret returns token_secret, else the key LoadTokenSecret kept in the data directory, else
+// a random key generated once per run (tokens minted then stop working on restart)
+func (a *AccessConfig) tokenSecret() []byte {
+	if a.TokenSecret != "" {
+		return []byte(a.TokenSecret)
+	}
+	if a.generatedSecret != nil {
+		return a.generatedSecret
+	}
+
+	generatedTokenSecretOnce.Do(func() {
+		generatedTokenSecret = make([]byte, 32)
+		rand.Read(generatedTokenSecret)
+	})
+	return generatedTokenSecret
+}
+
+// LoadTokenSecret keeps access tokens valid across restarts when access.token_secret is unset:
+// a key is generated on first use, saved to the data directory readable only by the owner, and
+// read back on later starts. It does nothing when token_secret is set.
+func (cfg *Config) LoadTokenSecret() error {
+	if cfg.Access.TokenSecret != "" {
+		return nil
+	}
+
+	path := cfg.GetStreamDefaults().TokenSecretPath
+	data, err := os.ReadFile(path)
+	if err == nil {
+		secret, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(secret) < 32 {
+			return fmt.Errorf("invalid access token key in %s", path)
+		}
+		cfg.Access.generatedSecret = secret
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read access token key: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate access token key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(secret)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save access token key: %w", err)
+	}
+	cfg.Access.generatedSecret = secret
+	return nil
+}
+
+// MintToken creates an access token valid until expires. Tokens are "<expires>.<signature>",
+// the signature being the hex HMAC-SHA256 of the expiry, so they need no server-side storage.
+func (a *AccessConfig) MintToken(expires time.Time) string {
+	expiry := strconv.FormatInt(expires.Unix(), 10)
+	return expiry + "." + a.tokenSignature(expiry)
+}
+
+// VerifyToken checks an access token's signature and expiry, returning when it expires
+func (a *AccessConfig) VerifyToken(token string, now time.Time) (time.Time, error) {
+	if token == "" {
+		return time.Time{}, ErrTokenMissing
+	}
+
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return time.Time{}, ErrTokenInvalid
+	}
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(a.tokenSignature(expiry))) {
+		return time.Time{}, ErrTokenInvalid
+	}
+	if now.Unix() >= expires {
+		return time.Time{}, ErrTokenExpired
+	}
+	return time.Unix(expires, 0), nil
+}
+
+// tokenSignature signs an expiry with the token secret
+func (a *AccessConfig) tokenSignature(expiry string) string {
+	mac := hmac.New(sha256.New, a.tokenSecret())
+	mac.Write([]byte("gnostream-access." + expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr string
+	}{
+		{name: "CIDRs", entries: []string{"10.0.0.0/8", "2001:db8::/32"}, want: []string{"10.0.0.0/8", "2001:db8::/32"}},
+		{name: "bare IPs", entries: []string{"192.168.1.5", "::1"}, want: []string{"192.168.1.5/32", "::1/128"}},
+		{name: "host bits are masked", entries: []string{"192.168.1.77/24"}, want: []string{"192.168.1.0/24"}},
+		{name: "IPv4-mapped IP", entries: []string{"::ffff:10.0.0.1"}, want: []string{"10.0.0.1/32"}},
+		{name: "whitespace and blanks", entries: []string{" 10.0.0.0/8 ", "", "  "}, want: []string{"10.0.0.0/8"}},
+		{name: "invalid entries are skipped", entries: []string{"10.0.0.0/33", "10.0.0.1", "not-an-ip"}, want: []string{"10.0.0.1/32"}, wantErr: `invalid CIDR "10.0.0.0/33"`},
+		{name: "invalid IP", entries: []string{"300.1.1.1"}, wantErr: `invalid IP "300.1.1.1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes, err := ParseCIDRs(tt.entries)
+
+			var got []string
+			for _, prefix := range prefixes {
+				got = append(got, prefix.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseCIDRs(%q) = %v, want %v", tt.entries, got, tt.want)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ParseCIDRs(%q) error = %v, want none", tt.entries, err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ParseCIDRs(%q) error = %v, want one containing %q", tt.entries, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIPRulesAllows(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		ip        string
+		want      bool
+	}{
+		{name: "no rules", ip: "203.0.113.7", want: true},
+		{name: "denied range", denylist: []string{"203.0.113.0/24"}, ip: "203.0.113.7", want: false},
+		{name: "outside denied range", denylist: []string{"203.0.113.0/24"}, ip: "203.0.114.7", want: true},
+		{name: "on allowlist", allowlist: []string{"192.168.1.0/24"}, ip: "192.168.1.20", want: true},
+		{name: "off allowlist", allowlist: []string{"192.168.1.0/24"}, ip: "192.168.2.20", want: false},
+		{name: "denylist wins", allowlist: []string{"192.168.1.0/24"}, denylist: []string{"192.168.1.20"}, ip: "192.168.1.20", want: false},
+		{name: "bracketed IPv6", allowlist: []string{"2001:db8::/32"}, ip: "[2001:db8::5]", want: true},
+		{name: "IPv4-mapped address", denylist: []string{"10.0.0.1"}, ip: "::ffff:10.0.0.1", want: false},
+		{name: "unparseable without allowlist", denylist: []string{"10.0.0.1"}, ip: "unknown", want: true},
+		{name: "unparseable with allowlist", allowlist: []string{"10.0.0.1"}, ip: "unknown", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := &AccessConfig{Allowlist: tt.allowlist, Denylist: tt.denylist}
+			rules, err := access.IPRules()
+			if err != nil {
+				t.Fatalf("IPRules: %v", err)
+			}
+			if got := rules.Allows(tt.ip); got != tt.want {
+				t.Errorf("Allows(%q) = %t, want %t", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPRulesInvalidAllowlistDeniesAll(t *testing.T) {
+	access := &AccessConfig{Allowlist: []string{"192.168.1.0/33", "not-an-ip"}}
+
+	rules, err := access.IPRules()
+	if err == nil {
+		t.Error("IPRules accepted invalid allowlist entries without an error")
+	}
+	if !rules.Active() {
+		t.Error("Active() = false for a configured allowlist with no valid entries")
+	}
+	if !rules.DeniesAll() {
+		t.Error("DeniesAll() = false for a configured allowlist with no valid entries")
+	}
+	for _, ip := range []string{"192.168.1.20", "203.0.113.7", "unknown"} {
+		if rules.Allows(ip) {
+			t.Errorf("Allows(%q) = true with no valid allowlist entries", ip)
+		}
+	}
+
+	blank := &AccessConfig{Allowlist: []string{"", "  "}}
+	rules, err = blank.IPRules()
+	if err != nil {
+		t.Fatalf("IPRules with blank entries: %v", err)
+	}
+	if rules.Active() || rules.DeniesAll() || !rules.Allows("203.0.113.7") {
+		t.Error("blank allowlist entries should not enable the allowlist")
+	}
+}
+
+func TestTrustedProxyRanges(t *testing.T) {
+	access := &AccessConfig{TrustedProxies: []string{"127.0.0.1", "10.0.0.0/8", "bogus"}}
+
+	ranges, err := access.TrustedProxyRanges()
+	if err == nil {
+		t.Error("TrustedProxyRanges accepted an invalid entry without an error")
+	}
+	want := []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32"), netip.MustParsePrefix("10.0.0.0/8")}
+	if !slices.Equal(ranges, want) {
+		t.Errorf("TrustedProxyRanges() = %v, want %v", ranges, want)
+	}
+}
+
+func TestLoadTokenSecretPersistsGeneratedKey(t *testing.T) {
+	dataDir := t.TempDir()
+	newConfig := func() *Config {
+		cfg := &Config{}
+		cfg.Storage.DataDir = dataDir
+		cfg.Access.RequireToken = true
+		return cfg
+	}
+
+	first := newConfig()
+	if err := first.LoadTokenSecret(); err != nil {
+		t.Fatalf("LoadTokenSecret: %v", err)
+	}
+	path := first.GetStreamDefaults().TokenSecretPath
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("key file not written: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("key file mode = %o, want 600", mode)
+	}
+
+	expires := time.Now().Add(time.Hour)
+	token := first.Access.MintToken(expires)
+
+	// A restart reads the same key back, so tokens minted before it still verify
+	second := newConfig()
+	if err := second.LoadTokenSecret(); err != nil {
+		t.Fatalf("LoadTokenSecret after restart: %v", err)
+	}
+	if _, err := second.Access.VerifyToken(token, time.Now()); err != nil {
+		t.Errorf("token minted before the restart = %v, want valid", err)
+	}
+
+	// Another data directory means another key
+	other := &Config{}
+	other.Storage.DataDir = t.TempDir()
+	if err := other.LoadTokenSecret(); err != nil {
+		t.Fatalf("LoadTokenSecret: %v", err)
+	}
+	if _, err := other.Access.VerifyToken(token, time.Now()); err == nil {
+		t.Error("a token verified with a different generated key")
+	}
+}
+
+func TestLoadTokenSecretWithConfiguredSecret(t *testing.T) {
+	cfg := &Config{}
+	cfg.Storage.DataDir = t.TempDir()
+	cfg.Access.TokenSecret = "configured"
+
+	if err := cfg.LoadTokenSecret(); err != nil {
+		t.Fatalf("LoadTokenSecret: %v", err)
+	}
+	if _, err := os.Stat(cfg.GetStreamDefaults().TokenSecretPath); !os.IsNotExist(err) {
+		t.Errorf("key file written although token_secret is set (stat error %v)", err)
+	}
+
+	token := cfg.Access.MintToken(time.Now().Add(time.Hour))
+	configured := &AccessConfig{TokenSecret: "configured"}
+	if _, err := configured.VerifyToken(token, time.Now()); err != nil {
+		t.Errorf("token = %v, want it signed with token_secret", err)
+	}
+}
+
+func TestLoadTokenSecretRejectsCorruptKey(t *testing.T) {
+	cfg := &Config{}
+	cfg.Storage.DataDir = t.TempDir()
+	writeTestFile(t, cfg.Storage.DataDir, "access-token.key", "not hex\n")
+
+	if err := cfg.LoadTokenSecret(); err == nil {
+		t.Error("LoadTokenSecret accepted a corrupt key file")
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretKeys are config.yml keys whose values are blanked in a backup without secrets
+var secretKeys = map[string]bool{
+	"private_key":  true,
+	"secret":       true,
+	"token_secret": true,
+}
+
+// BackupFiles returns the setup files worth carrying to a new machine: the main config, the
+// stream info file with its profiles, the chat filter word list and the Nostr private key file,
+// skipping any that don't exist
+func (cfg *Config) BackupFiles(configPath string) []string {
+	candidates := []string{configPath, cfg.baseStreamInfoPath}
+	if cfg.baseStreamInfoPath == "" {
+		candidates[1] = cfg.StreamInfoPath
+	}
+
+	if profiles, err := cfg.ListProfiles(); err == nil {
+		for _, name := range profiles {
+			candidates = append(candidates, ProfileStreamInfoPath(candidates[1], name))
+		}
+	}
+
+	if cfg.Chat.Filter.WordList != "" {
+		candidates = append(candidates, cfg.Chat.Filter.WordList)
+	}
+	candidates = append(candidates, cfg.SecretFiles()...)
+
+	seen := make(map[string]bool)
+	files := []string{}
+	for _, path := range candidates {
+		path = filepath.Clean(path)
+		if path == "." || seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// SecretFiles returns the setup files that hold secrets rather than settings: the
+// nostr.private_key_file, if one is configured. A backup without secrets leaves them out.
+func (cfg *Config) SecretFiles() []string {
+	if cfg.Nostr.PrivateKeyFile == "" {
+		return nil
+	}
+	return []string{filepath.Clean(cfg.Nostr.PrivateKeyFile)}
+}
+
+// RedactSecrets returns config.yml content with secret values (the Nostr private key, the
+// webhook secret) blanked, keeping comments and key order
+func RedactSecrets(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	redactNode(&doc)
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+	return []byte(out.String()), nil
+}
+
+// redactNode blanks scalar values of secret keys anywhere below node
+func redactNode(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if secretKeys[key.Value] && value.Kind == yaml.ScalarNode {
+				value.Value = ""
+				value.Tag = "!!str"
+				value.Style = yaml.DoubleQuotedStyle
+				continue
+			}
+			redactNode(value)
+		}
+		return
+	}
+
+	for _, child := range node.Content {
+		redactNode(child)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	input := `# Main config
+nostr:
+  private_key: "nsec1secretsecret" # inline key
+  private_key_file: "keys/nsec"
+  relays:
+    - "wss://relay.one"
+webhooks:
+  secret: "hook-secret"
+access:
+  token_secret: "token-secret"
+`
+
+	redacted, err := RedactSecrets([]byte(input))
+	if err != nil {
+		t.Fatalf("RedactSecrets: %v", err)
+	}
+	output := string(redacted)
+
+	for _, secret := range []string{"nsec1secretsecret", "hook-secret", "token-secret"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("redacted config still contains %q:\n%s", secret, output)
+		}
+	}
+	for _, kept := range []string{"# Main config", "# inline key", `private_key: ""`, `private_key_file: "keys/nsec"`, "wss://relay.one"} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("redacted config lost %q:\n%s", kept, output)
+		}
+	}
+}
+
+func TestBackupFilesIncludesPrivateKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeTestFile(t, dir, "config.yml", "server:\n  port: 8080\n")
+	streamInfoPath := writeTestFile(t, dir, "stream-info.yml", "title: \"Backup\"\n")
+	keyPath := writeTestFile(t, dir, "nsec", testNsec+"\n")
+
+	cfg := &Config{StreamInfoPath: streamInfoPath}
+	cfg.Nostr.PrivateKeyFile = keyPath
+
+	files := cfg.BackupFiles(configPath)
+	if !slices.Contains(files, keyPath) {
+		t.Errorf("BackupFiles() = %v, want the private key file %s", files, keyPath)
+	}
+	if secrets := cfg.SecretFiles(); !slices.Equal(secrets, []string{keyPath}) {
+		t.Errorf("SecretFiles() = %v, want [%s]", secrets, keyPath)
+	}
+
+	// A key file that doesn't exist is skipped like any other missing file
+	cfg.Nostr.PrivateKeyFile = filepath.Join(dir, "missing")
+	if files := cfg.BackupFiles(configPath); slices.Contains(files, cfg.Nostr.PrivateKeyFile) {
+		t.Errorf("BackupFiles() = %v, includes a missing key file", files)
+	}
+
+	cfg.Nostr.PrivateKeyFile = ""
+	if secrets := cfg.SecretFiles(); len(secrets) != 0 {
+		t.Errorf("SecretFiles() = %v without a key file, want none", secrets)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// rtmpBindHost resolves the address FFmpeg listens on to an IP literal. FFmpeg binds the first
+// address a host name resolves to, so "localhost" can end up on ::1 while OBS connects to
+// 127.0.0.1 (or the other way round); an explicit address avoids the family mismatch.
+func (rc *RTMPConfig) rtmpBindHost() (string, error) {
+	if name := strings.TrimSpace(rc.Interface); name != "" {
+		return interfaceAddress(name, rc.IPv6)
+	}
+
+	host := strings.Trim(strings.TrimSpace(rc.Host), "[]")
+	switch {
+	case host == "" && rc.IPv6:
+		return "::", nil
+	case host == "":
+		return "0.0.0.0", nil
+	case net.ParseIP(host) != nil:
+		return host, nil
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return "", fmt.Errorf("rtmp.host %q does not resolve: %w", host, err)
+	}
+	if ip := pickFamily(ips, rc.IPv6); ip != nil {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf("rtmp.host %q has no address", host)
+}
+
+// interfaceAddress returns the address of a network interface to bind to, skipping link-local
+// IPv6 addresses since FFmpeg can't be given their zone
+func interfaceAddress(name string, ipv6 bool) (string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return "", fmt.Errorf("rtmp.interface %q: %w", name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", fmt.Errorf("rtmp.interface %q: %w", name, err)
+	}
+
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	if ip := pickFamily(ips, ipv6); ip != nil {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf("rtmp.interface %q has no usable address", name)
+}
+
+// pickFamily returns the first address of the preferred family, or the first address otherwise
+func pickFamily(ips []net.IP, ipv6 bool) net.IP {
+	for _, ip := range ips {
+		if (ip.To4() == nil) == ipv6 {
+			return ip
+		}
+	}
+	if len(ips) > 0 {
+		return ips[0]
+	}
+	return nil
+}
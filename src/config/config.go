@@ -3,7 +3,11 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,19 +20,40 @@ type Config struct {
 	Server               ServerConfig     `yaml:"server"`
 	RTMP                 RTMPConfig       `yaml:"rtmp"`
 	Nostr                NostrRelayConfig `yaml:"nostr"`
+	Logging              LoggingConfig    `yaml:"logging"`
+	FFmpeg               FFmpegConfig     `yaml:"ffmpeg"`
+	FFprobe              FFprobeConfig    `yaml:"ffprobe"`
+	Chat                 ChatConfig       `yaml:"chat"`
+	Stream               StreamConfig     `yaml:"stream"`
+	ABR                  ABRConfig        `yaml:"abr"`
+	Webhooks             WebhooksConfig   `yaml:"webhooks"`
+	Access               AccessConfig     `yaml:"access"`
+	Storage              StorageConfig    `yaml:"storage"`
 	StreamInfoPath    string      `yaml:"stream_info_path"`
+	Profile           string      `yaml:"profile"` // Named stream info profile (stream-info.<name>.yml), empty for the default
 	StreamInfo        *StreamInfo `yaml:"-"`    // Not stored in main config, loaded separately
 	streamInfoModTime time.Time   `yaml:"-"`    // Track file modification time
 	streamInfoMutex   sync.RWMutex `yaml:"-"`    // Protect concurrent access
+	streamInfoErr     error        `yaml:"-"`    // Why the file on disk can't be used, nil when it's fine
+	baseStreamInfoPath string     `yaml:"-"`    // stream_info_path before a profile is applied
+	path               string     `yaml:"-"`    // The file this config was loaded from
 }
 
-// GetStreamDefaults returns hardcoded stream configuration defaults
+// GetStreamDefaults returns stream configuration defaults, with the data directories from storage
 func (cfg *Config) GetStreamDefaults() *StreamDefaults {
+	outputDir, archiveDir := cfg.Storage.dirs()
+	offlineDir := filepath.Join(cfg.Storage.dataDir(), "offline")
 	return &StreamDefaults{
-		RTMPUrl:       "rtmp://localhost:1935/live/stream",
-		OutputDir:     "www/live",
-		ArchiveDir:    "www/live/archive", 
-		CheckInterval: 5 * time.Second,
+		RTMPUrl:          "rtmp://localhost:1935/live/stream",
+		OutputDir:        outputDir,
+		ArchiveDir:       archiveDir,
+		MetadataPath:     filepath.Join(outputDir, MetadataFileName),
+		ArchiveRoute:     "/media/archive/",
+		OfflineDir:       offlineDir,
+		PlannedPath:      filepath.Join(cfg.Storage.dataDir(), "planned-stream.json"),
+		ChatSettingsPath: filepath.Join(cfg.Storage.dataDir(), "chat-settings.json"),
+		TokenSecretPath:  filepath.Join(cfg.Storage.dataDir(), "access-token.key"),
+		CheckInterval:    5 * time.Second,
 	}
 }
 
@@ -43,46 +68,376 @@ func (cfg *Config) GetRTMPDefaults() *RTMPDefaults {
 	if host == "" {
 		host = "0.0.0.0"
 	}
+	if bindHost, err := cfg.RTMP.rtmpBindHost(); err == nil {
+		host = bindHost
+	}
 	
+	idleTimeout := cfg.RTMP.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = 15
+	}
+
+	restartDelay := cfg.RTMP.RestartDelay
+	if restartDelay <= 0 {
+		restartDelay = 3
+	}
+
+	authTimeout := cfg.RTMP.AuthTimeout
+	if authTimeout <= 0 {
+		authTimeout = 5
+	}
+
+	// A key with slashes or spaces would change the path FFmpeg listens on
+	streamKey := strings.Trim(strings.TrimSpace(cfg.RTMP.StreamKey), "/")
+	if streamKey == "" || strings.ContainsAny(streamKey, "/ ?#") {
+		streamKey = "stream"
+	}
+
 	return &RTMPDefaults{
-		Port:    port,
-		Host:    host,
-		Enabled: true,
+		Port:           port,
+		Host:           host,
+		ConfiguredHost: cfg.RTMP.Host,
+		Enabled:        true,
+		IdleTimeout:    time.Duration(idleTimeout) * time.Second,
+		RestartDelay:   time.Duration(restartDelay) * time.Second,
+		AuthURL:        strings.TrimSpace(cfg.RTMP.AuthURL),
+		AuthTimeout:    time.Duration(authTimeout) * time.Second,
+		StreamKey:      streamKey,
 	}
 }
 
 // StreamDefaults holds hardcoded stream configuration
 type StreamDefaults struct {
-	RTMPUrl       string
-	OutputDir     string
-	ArchiveDir    string
-	CheckInterval time.Duration
+	RTMPUrl          string
+	OutputDir        string
+	ArchiveDir       string
+	MetadataPath     string // The live stream's metadata.json in OutputDir
+	ArchiveRoute     string // URL path ArchiveDir is served under
+	OfflineDir       string // Pre-encoded offline placeholder, kept outside OutputDir so archiving doesn't move it
+	PlannedPath      string // Scheduled ("planned") stream, kept outside OutputDir so archiving doesn't move it
+	ChatSettingsPath string // Owner's chat moderation toggles, kept outside OutputDir so they aren't served or archived
+	TokenSecretPath  string // Generated access token key when access.token_secret is unset, kept outside OutputDir
+	CheckInterval    time.Duration
 }
 
 // RTMPConfig holds RTMP configuration from YAML
 type RTMPConfig struct {
-	Port int    `yaml:"port"`
-	Host string `yaml:"host"`
+	Port          int    `yaml:"port"`
+	Host          string `yaml:"host"`
+	ValidateInput bool   `yaml:"validate_input"` // Probe tracks with ffprobe when a stream connects
+	IdleTimeout   int    `yaml:"idle_timeout"`   // Seconds without new HLS output before a stream counts as ended (default 15)
+	RestartDelay  int    `yaml:"restart_delay"`  // Seconds to wait for the RTMP port to free up before relaunching FFmpeg (default 3)
+	AuthURL       string `yaml:"auth_url"`       // Optional on_publish style callback; a publish is only accepted on a 2xx answer
+	AuthTimeout   int    `yaml:"auth_timeout"`   // Seconds to wait for auth_url before denying (default 5)
+	StreamKey     string `yaml:"stream_key"`     // Stream key to enter in OBS (default "stream"); not a secret, others are accepted too
+	Interface     string `yaml:"interface"`      // Listen on this network interface's address (e.g. eth0) instead of host
+	IPv6          bool   `yaml:"ipv6"`           // Prefer IPv6 when resolving host/interface; an empty host listens on ::
 }
 
+// RTMPApp is the RTMP application encoders publish to (OBS "Server": rtmp://host:port/live)
+const RTMPApp = "live"
+
 // RTMPDefaults holds RTMP configuration with defaults applied
 type RTMPDefaults struct {
-	Port    int
-	Host    string
-	Enabled bool
+	Port           int
+	Host           string // Resolved IP literal FFmpeg listens on
+	ConfiguredHost string // rtmp.host as written, e.g. a host name
+	Enabled        bool
+	IdleTimeout    time.Duration
+	RestartDelay   time.Duration
+	AuthURL        string
+	AuthTimeout    time.Duration
+	StreamKey      string
+}
+
+// ListenURL is the URL FFmpeg listens on. FFmpeg takes the first path element as the RTMP app,
+// which must match what the encoder connects to; the stream key after it only has to be present.
+func (d *RTMPDefaults) ListenURL() string {
+	return fmt.Sprintf("rtmp://%s/%s/%s", net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), RTMPApp, d.StreamKey)
+}
+
+// StreamConfig holds per-stream safety limits
+type StreamConfig struct {
+	MaxDuration int           `yaml:"max_duration"` // Seconds after which a live stream is ended automatically (0 = unlimited)
+	Offline     OfflineConfig `yaml:"offline"`      // Placeholder served at the live URL while nobody is streaming
+}
+
+// OfflineConfig sets up the "be right back" placeholder, pre-encoded to HLS with FFmpeg at startup
+type OfflineConfig struct {
+	Source   string `yaml:"source"`   // Image or video to loop while offline (empty = off)
+	Duration int    `yaml:"duration"` // Seconds encoded from the source before looping (default 10 for images, whole video otherwise)
+}
+
+// Enabled reports whether an offline placeholder is configured
+func (o *OfflineConfig) Enabled() bool {
+	return o.Source != ""
+}
+
+// WebhooksConfig holds URLs notified with a POST when streams start and stop
+type WebhooksConfig struct {
+	OnStart string `yaml:"on_start"`
+	OnStop  string `yaml:"on_stop"`
+	Secret  string `yaml:"secret"` // HMAC-SHA256 key; when set every request carries a signature header
+}
+
+// LoggingConfig holds log output configuration
+type LoggingConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error (default info)
+	Format string `yaml:"format"` // text (default) or json
+}
+
+// ChatConfig holds chat moderation settings
+type ChatConfig struct {
+	Filter            ChatFilterConfig `yaml:"filter"`
+	MaxMessageLength  int              `yaml:"max_message_length"`  // Longest chat message in characters (default 2000)
+	MaxCachedMessages int              `yaml:"max_cached_messages"` // Recent messages kept for the HTTP API and new viewers (default 100)
+}
+
+// defaultMaxMessageLength is the chat message length limit when none is configured
+const defaultMaxMessageLength = 2000
+
+// defaultMaxCachedMessages is the chat history cache size when none is configured
+const defaultMaxCachedMessages = 100
+
+// CacheLimit returns how many recent chat messages are cached
+func (c *ChatConfig) CacheLimit() int {
+	if c.MaxCachedMessages <= 0 {
+		return defaultMaxCachedMessages
+	}
+	return c.MaxCachedMessages
+}
+
+// MessageLengthLimit returns the maximum chat message length in characters
+func (c *ChatConfig) MessageLengthLimit() int {
+	if c.MaxMessageLength <= 0 {
+		return defaultMaxMessageLength
+	}
+	return c.MaxMessageLength
+}
+
+// ChatFilterConfig configures automated chat filtering (off by default)
+type ChatFilterConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WordList   string `yaml:"word_list"`   // File with one word per line ("re:" prefix for a regex, "#" for comments), reloaded on change
+	Action     string `yaml:"action"`      // drop (default) or mask
+	BlockLinks bool   `yaml:"block_links"` // Drop messages containing links
+}
+
+// FFmpegConfig selects the FFmpeg build used for RTMP ingest and HLS conversion
+type FFmpegConfig struct {
+	Binary       string   `yaml:"binary"`        // Path or name of the ffmpeg executable (default "ffmpeg" from PATH)
+	ExtraArgs    []string `yaml:"extra_args"`    // Extra arguments inserted before the output path
+	AudioFilters string   `yaml:"audio_filters"` // -af filter chain, e.g. "loudnorm=I=-16:TP=-1.5:LRA=11"
+	VideoFilters string   `yaml:"video_filters"` // -vf filter chain, e.g. a drawtext or movie/overlay watermark
+	CRF          int      `yaml:"crf"`           // x264 constant quality, lower is better (default 18); exclusive with video_bitrate
+	VideoBitrate int      `yaml:"video_bitrate"` // Target video bitrate in kbps instead of CRF, to cap viewer bandwidth (0 = CRF)
+	MaxHeight    int      `yaml:"max_height"`    // Downscale taller input to this height, keeping the aspect ratio (0 = keep)
+	MaxFPS       int      `yaml:"max_fps"`       // Cap the output framerate; slower input is left alone (0 = keep)
+	AudioBitrate int      `yaml:"audio_bitrate"` // AAC bitrate in kbps (default 160)
+}
+
+// copiesCodec reports whether extra_args stream-copy the given stream type ("v" or "a"),
+// which FFmpeg can't combine with filters on that stream
+func (f *FFmpegConfig) copiesCodec(stream string) bool {
+	for i := 0; i+1 < len(f.ExtraArgs); i++ {
+		switch f.ExtraArgs[i] {
+		case "-c", "-codec", "-c:" + stream, "-codec:" + stream, "-" + stream + "codec":
+			if f.ExtraArgs[i+1] == "copy" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterArgs returns the -vf/-af arguments for the configured filter chains. A chain is
+// dropped when extra_args stream-copy that stream, since FFmpeg would refuse to start.
+func (f *FFmpegConfig) FilterArgs() []string {
+	var args []string
+	if chain := f.videoFilterChain(); chain != "" && !f.copiesCodec("v") {
+		args = append(args, "-vf", chain)
+	}
+	if f.AudioFilters != "" && !f.copiesCodec("a") {
+		args = append(args, "-af", f.AudioFilters)
+	}
+	return args
+}
+
+// FFprobeConfig selects the ffprobe build used for stream probing
+type FFprobeConfig struct {
+	Binary string `yaml:"binary"` // Path or name of the ffprobe executable (default "ffprobe" from PATH)
+}
+
+// FFmpegBinary returns the configured ffmpeg executable
+func (cfg *Config) FFmpegBinary() string {
+	if cfg.FFmpeg.Binary == "" {
+		return "ffmpeg"
+	}
+	return cfg.FFmpeg.Binary
+}
+
+// FFprobeBinary returns the configured ffprobe executable
+func (cfg *Config) FFprobeBinary() string {
+	if cfg.FFprobe.Binary == "" {
+		return "ffprobe"
+	}
+	return cfg.FFprobe.Binary
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Port        int    `yaml:"port"`
-	Host        string `yaml:"host"`
-	ExternalURL string `yaml:"external_url"`
+	Port            int    `yaml:"port"`
+	Host            string `yaml:"host"`
+	ExternalURL     string `yaml:"external_url"`
+	DevMode         bool   `yaml:"dev_mode"`         // Re-parse templates on every request
+	CacheHeaders    bool   `yaml:"cache_headers"`    // Send Cache-Control tuned for live vs archived HLS (for caching proxies/CDNs)
+	ViewerBlocklist string `yaml:"viewer_blocklist"` // Optional JSON file persisting blocked viewer IPs (empty = until restart)
 }
 
 // HLSConfig holds HLS conversion settings
 type HLSConfig struct {
-	SegmentTime  int `yaml:"segment_time"`
-	PlaylistSize int `yaml:"playlist_size"`
+	SegmentTime         int    `yaml:"segment_time"`
+	PlaylistSize        int    `yaml:"playlist_size"`
+	SegmentFilename     string `yaml:"segment_filename"`      // FFmpeg pattern, e.g. "segment_%05d.ts" (empty = FFmpeg default)
+	AbsoluteSegmentURLs bool   `yaml:"absolute_segment_urls"` // Prefix segment URIs with external_url + /live/
+	SegmentType         string `yaml:"segment_type"`          // "mpegts" (default, .ts) or "fmp4" (CMAF .m4s + init.mp4)
+	LowLatency          bool   `yaml:"low_latency"`           // Short fMP4 segments + blocking playlist reload (LL-HLS)
+	DVRWindow           int    `yaml:"dvr_window"`            // Seconds viewers can seek back on a non-recorded stream (0 = playlist_size only)
+	AudioTracks         int      `yaml:"audio_tracks"`        // Input audio tracks to offer as selectable HLS renditions (0/1 = single track)
+	AudioLanguages      []string `yaml:"audio_languages"`     // Optional language code per audio track, e.g. ["en", "es"]
+	PlaylistCheck       string   `yaml:"playlist_check"`      // Missing/empty segments in served live playlists: "repair" (default), "log" or "off"
+}
+
+// FFmpegSettingsChanged reports whether other differs in a setting FFmpeg is started with, so a
+// running FFmpeg has to be restarted to apply it. playlist_check is applied per request instead.
+func (h *HLSConfig) FFmpegSettingsChanged(other *HLSConfig) bool {
+	return h.SegmentTime != other.SegmentTime ||
+		h.PlaylistSize != other.PlaylistSize ||
+		h.SegmentFilename != other.SegmentFilename ||
+		h.AbsoluteSegmentURLs != other.AbsoluteSegmentURLs ||
+		h.SegmentType != other.SegmentType ||
+		h.LowLatency != other.LowLatency ||
+		h.DVRWindow != other.DVRWindow ||
+		h.AudioTrackCount() != other.AudioTrackCount() ||
+		!slices.Equal(h.AudioLanguages, other.AudioLanguages)
+}
+
+// Live playlist check modes
+const (
+	PlaylistCheckRepair = "repair" // Serve the playlist without broken segments
+	PlaylistCheckLog    = "log"    // Log broken segments, serve the playlist as written
+	PlaylistCheckOff    = "off"    // Serve the playlist without checking
+)
+
+// maxAudioTracks caps multi-track audio mapping
+const maxAudioTracks = 8
+
+// MasterPlaylistName is the playlist viewers load; with multi-track audio it becomes a master
+// playlist pointing at one video and several audio renditions
+const MasterPlaylistName = "output.m3u8"
+
+// multiAudioVideoPlaylist is the video rendition's media playlist in multi-track mode
+const multiAudioVideoPlaylist = "stream_video.m3u8"
+
+// AudioTrackCount returns how many audio tracks are mapped into the HLS output. Multi-track audio
+// is not combined with low-latency mode, whose playlist handling expects a single media playlist.
+func (hls *HLSConfig) AudioTrackCount() int {
+	if hls.AudioTracks <= 1 || hls.LowLatency {
+		return 1
+	}
+	if hls.AudioTracks > maxAudioTracks {
+		return maxAudioTracks
+	}
+	return hls.AudioTracks
+}
+
+// MediaPlaylistName returns the playlist FFmpeg rewrites with every video segment, used to watch
+// stream activity: output.m3u8 itself, or the video rendition when output.m3u8 is a master playlist
+func MediaPlaylistName(audioTracks int) string {
+	if audioTracks > 1 {
+		return multiAudioVideoPlaylist
+	}
+	return MasterPlaylistName
+}
+
+// AudioTrackArgs returns the FFmpeg mapping for audioTracks audio renditions: one video variant
+// plus an audio group, written as stream_<name>.m3u8 media playlists under an output.m3u8 master.
+// Segment and init file names must contain %v in this mode (see OutputPattern).
+func (hls *HLSConfig) AudioTrackArgs(audioTracks int) []string {
+	if audioTracks <= 1 {
+		return nil
+	}
+
+	args := []string{"-map", "0:v:0"}
+	variants := []string{"v:0,agroup:audio,name:video"}
+	for i := 0; i < audioTracks; i++ {
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", i))
+
+		variant := fmt.Sprintf("a:%d,agroup:audio,name:audio_%d", i, i)
+		if i == 0 {
+			variant += ",default:yes"
+		}
+		if i < len(hls.AudioLanguages) && hls.AudioLanguages[i] != "" {
+			variant += ",language:" + hls.AudioLanguages[i]
+		}
+		variants = append(variants, variant)
+	}
+
+	return append(args, "-var_stream_map", strings.Join(variants, " "), "-master_pl_name", MasterPlaylistName)
+}
+
+// OutputPattern returns the output playlist, segment and fMP4 init file names for audioTracks
+// renditions. In multi-track mode each name carries FFmpeg's %v variant placeholder.
+func (hls *HLSConfig) OutputPattern(audioTracks int) (playlist, segment, init string) {
+	playlist, segment, init = MasterPlaylistName, hls.SegmentFilename, "init.mp4"
+	if audioTracks <= 1 {
+		return playlist, segment, init
+	}
+
+	playlist, init = "stream_%v.m3u8", "init_%v.mp4"
+	if segment != "" && !strings.Contains(segment, "%v") {
+		segment = "%v_" + segment
+	}
+	return playlist, segment, init
+}
+
+// dvrDeleteThreshold is how many segments past the DVR window stay on disk, so players still
+// fetching a segment that just left the playlist don't get a 404
+const dvrDeleteThreshold = 3
+
+// LiveRetention returns the playlist length and FFmpeg delete threshold (both in segments) for
+// non-recorded streams. Without a DVR window the playlist holds playlist_size segments; with one
+// it grows to cover the window so players can seek back that far.
+func (hls *HLSConfig) LiveRetention() (listSize, deleteThreshold int) {
+	listSize = hls.PlaylistSize
+	if hls.DVRWindow <= 0 || hls.SegmentTime <= 0 {
+		return listSize, 0
+	}
+
+	dvrSegments := (hls.DVRWindow + hls.SegmentTime - 1) / hls.SegmentTime
+	if dvrSegments > listSize {
+		listSize = dvrSegments
+	}
+	return listSize, dvrDeleteThreshold
+}
+
+// lowLatencyMaxSegmentTime caps segment length in low-latency mode
+const lowLatencyMaxSegmentTime = 2
+
+// PublicBaseURL returns the URL viewers reach the server at (external_url, or localhost)
+func (cfg *Config) PublicBaseURL() string {
+	if cfg.Server.ExternalURL != "" {
+		return cfg.Server.ExternalURL
+	}
+	return fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
+}
+
+// SegmentBaseURL returns the absolute base URL for live segments, or "" if segment URIs should stay relative
+func (cfg *Config) SegmentBaseURL(hls *HLSConfig) string {
+	if !hls.AbsoluteSegmentURLs || cfg.Server.ExternalURL == "" {
+		return ""
+	}
+	return strings.TrimRight(cfg.Server.ExternalURL, "/") + "/live/"
 }
 
 
@@ -92,6 +447,7 @@ type StreamInfo struct {
 	Summary     string    `yaml:"summary"`
 	Image       string    `yaml:"image"`
 	Tags        []string  `yaml:"tags"`
+	Category    string    `yaml:"category,omitempty"` // Stream category/game for category-filtered directories
 	Record      bool      `yaml:"record"` // Whether to record/archive the stream
 	HLS         HLSConfig `yaml:"hls"`    // HLS conversion settings
 }
@@ -102,6 +458,7 @@ type StreamMetadata struct {
 	Summary          string   `yaml:"summary" json:"summary"`
 	Image            string   `yaml:"image" json:"image"`
 	Tags             []string `yaml:"tags" json:"tags"`
+	Category         string   `yaml:"category" json:"category,omitempty"`
 	Pubkey           string   `yaml:"pubkey" json:"pubkey"`
 	Dtag             string   `yaml:"dtag" json:"dtag"`
 	StreamURL        string   `yaml:"stream_url" json:"stream_url"`
@@ -113,16 +470,121 @@ type StreamMetadata struct {
 	SuccessfulRelays []string `yaml:"successful_relays" json:"successful_relays"`     // Relays that accepted the event
 }
 
+// MetadataSnapshot is a point-in-time copy of stream metadata handed to readers outside the
+// monitor. It is a value and shares no memory with the monitor's live metadata.
+type MetadataSnapshot StreamMetadata
+
+// Clone returns a deep copy, so callers can read it while the monitor keeps updating the original
+func (md *StreamMetadata) Clone() *StreamMetadata {
+	if md == nil {
+		return nil
+	}
+	clone := *md
+	clone.Tags = append([]string(nil), md.Tags...)
+	clone.SuccessfulRelays = append([]string(nil), md.SuccessfulRelays...)
+	return &clone
+}
+
+// Snapshot returns a deep-copied snapshot of the metadata
+func (md *StreamMetadata) Snapshot() MetadataSnapshot {
+	return MetadataSnapshot(*md.Clone())
+}
+
+// Metadata returns a private *StreamMetadata copy of the snapshot for APIs that take a pointer
+func (s MetadataSnapshot) Metadata() *StreamMetadata {
+	metadata := StreamMetadata(s)
+	return metadata.Clone()
+}
+
 // NostrRelayConfig represents Nostr configuration
 type NostrRelayConfig struct {
 	PrivateKey        string   `yaml:"private_key"`         // nsec format private key
+	PrivateKeyFile    string   `yaml:"private_key_file"`    // File holding the key instead (takes precedence over private_key)
 	Relays            []string `yaml:"relays"`
+	WriteRelays       []string `yaml:"write_relays"`        // Publish to these first, falling back to the rest of relays (default: relays)
+	ReadRelays        []string `yaml:"read_relays"`         // Subscribe for chat on these, falling back to the rest of relays (default: relays)
 	DeleteNonRecorded bool     `yaml:"delete_non_recorded"` // Send NIP-09 deletion for streams without recordings
+	UseRelayHints     bool     `yaml:"use_relay_hints"`     // Look up users' NIP-65 write relays when fetching profiles
+	DryRun            bool     `yaml:"dry_run"`             // Build, sign and log events without publishing them
+	RepublishInterval int      `yaml:"republish_interval"`  // Seconds between re-sends of the live event while streaming (0 = off)
+	Connection        NostrConnectionConfig `yaml:"connection"` // Relay pool timeouts and retries
+	ReconnectBeforePublish string `yaml:"reconnect_before_publish"` // "dropped" (default) reconnects only missing relays, "all" retries every relay, "off" skips the check
+	EventSigner            string `yaml:"event_signer"`             // "config" (default) signs stream events with the private key, "browser" with the owner's logged-in signer
+	SignTimeout            int    `yaml:"sign_timeout"`             // Seconds to wait for the browser to sign a stream event (default 60)
 	
+	ConfiguredPublicKey string `yaml:"public_key,omitempty"` // Optional npub/hex, checked against the key derived from private_key
+
 	// Derived fields (not stored in YAML)
 	PublicKey  string `yaml:"-"` // Will be derived from private key
 }
 
+// Reconnect-before-publish modes
+const (
+	ReconnectDropped = "dropped" // Reconnect only relays that are no longer connected
+	ReconnectAll     = "all"     // Run the full connect-with-retry loop over every relay
+	ReconnectOff     = "off"     // Publish to whatever is connected
+)
+
+// NostrConnectionConfig holds relay connection tuning from YAML; zero values use the defaults
+type NostrConnectionConfig struct {
+	ConnectTimeout int `yaml:"connect_timeout"` // Seconds to establish a relay connection (default 15)
+	ReadTimeout    int `yaml:"read_timeout"`    // Seconds to wait for relay messages (default 45)
+	WriteTimeout   int `yaml:"write_timeout"`   // Seconds to send a message to a relay (default 15)
+	MaxConnections int `yaml:"max_connections"` // Relay pool size (default 20)
+	RetryAttempts  int `yaml:"retry_attempts"`  // Connection attempts before giving up on a relay (default 3)
+	RetryDelay     int `yaml:"retry_delay"`     // Seconds between attempts, multiplied by the attempt number (default 2)
+}
+
+// NostrConnectionDefaults holds relay connection settings with defaults applied
+type NostrConnectionDefaults struct {
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	MaxConnections int
+	RetryAttempts  int
+	RetryDelay     time.Duration
+}
+
+// Validate rejects negative connection settings (zero means "use the default")
+func (c *NostrConnectionConfig) Validate() error {
+	values := []struct {
+		name  string
+		value int
+	}{
+		{"connect_timeout", c.ConnectTimeout},
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"max_connections", c.MaxConnections},
+		{"retry_attempts", c.RetryAttempts},
+		{"retry_delay", c.RetryDelay},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			return fmt.Errorf("nostr.connection.%s must be positive (got %d)", v.name, v.value)
+		}
+	}
+	return nil
+}
+
+// Defaults returns the connection settings with defaults applied
+func (c *NostrConnectionConfig) Defaults() *NostrConnectionDefaults {
+	orDefault := func(value, fallback int) int {
+		if value <= 0 {
+			return fallback
+		}
+		return value
+	}
+
+	return &NostrConnectionDefaults{
+		ConnectTimeout: time.Duration(orDefault(c.ConnectTimeout, 15)) * time.Second,
+		ReadTimeout:    time.Duration(orDefault(c.ReadTimeout, 45)) * time.Second,
+		WriteTimeout:   time.Duration(orDefault(c.WriteTimeout, 15)) * time.Second,
+		MaxConnections: orDefault(c.MaxConnections, 20),
+		RetryAttempts:  orDefault(c.RetryAttempts, 3),
+		RetryDelay:     time.Duration(orDefault(c.RetryDelay, 2)) * time.Second,
+	}
+}
+
 // Load reads and parses the main configuration file
 func Load(path string) (*Config, error) {
 	// Check if config file exists, if not try to copy from example
@@ -163,6 +625,27 @@ func Load(path string) (*Config, error) {
 	if cfg.StreamInfoPath == "" {
 		cfg.StreamInfoPath = "stream-info.yml"
 	}
+	cfg.baseStreamInfoPath = cfg.StreamInfoPath
+	cfg.path = path
+
+	if err := cfg.Nostr.Connection.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Nostr.ReconnectBeforePublish {
+	case "", ReconnectDropped, ReconnectAll, ReconnectOff:
+	default:
+		return nil, fmt.Errorf("nostr.reconnect_before_publish must be %q, %q or %q (got %q)",
+			ReconnectDropped, ReconnectAll, ReconnectOff, cfg.Nostr.ReconnectBeforePublish)
+	}
+
+	// A named profile swaps in its own stream info file
+	if cfg.Profile != "" && cfg.Profile != DefaultProfile {
+		profilePath, err := cfg.ProfilePath(cfg.Profile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.StreamInfoPath = profilePath
+	}
 
 	// Load stream info from separate file
 	streamInfo, modTime, err := LoadStreamInfoWithModTime(cfg.StreamInfoPath)
@@ -178,36 +661,88 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
-// validateAndWarn checks config values and warns about potential issues
+// validateAndWarn prints the configuration warnings
 func (cfg *Config) validateAndWarn() {
+	warnings := cfg.Warnings()
+	if len(warnings) > 0 {
+		fmt.Println("⚠️  Configuration Warnings:")
+		for _, warning := range warnings {
+			fmt.Printf("   • %s\n", warning)
+		}
+		fmt.Printf("   💡 Edit %s to fix these issues\n", cfg.Path())
+		fmt.Println()
+	}
+}
+
+// Warnings checks config values and returns potential issues
+func (cfg *Config) Warnings() []string {
 	warnings := []string{}
 
 	// Check Nostr private key
-	if cfg.Nostr.PrivateKey == "your-nostr-private-key-nsec" || cfg.Nostr.PrivateKey == "" {
-		warnings = append(warnings, "Nostr private key (nsec) is not configured - Nostr broadcasting will not work")
+	privateKey, err := cfg.Nostr.ResolvePrivateKey()
+	if err != nil {
+		warnings = append(warnings, err.Error()+" - Nostr broadcasting will not work")
+	} else if privateKey == "" {
+		if cfg.Nostr.BrowserSigning() && cfg.Nostr.ConfiguredPublicKey != "" {
+			warnings = append(warnings, "Nostr private key (nsec) is not configured - only stream events signed in the owner's browser will be published")
+		} else {
+			warnings = append(warnings, "Nostr private key (nsec) is not configured - Nostr broadcasting will not work")
+		}
 	} else {
 		// Basic nsec validation
-		if !strings.HasPrefix(cfg.Nostr.PrivateKey, "nsec1") {
+		if !strings.HasPrefix(privateKey, "nsec1") {
 			warnings = append(warnings, "Nostr private key should be in nsec format (starts with 'nsec1')")
-		} else if len(cfg.Nostr.PrivateKey) != 63 {
+		} else if len(privateKey) != 63 {
 			warnings = append(warnings, "Nostr private key should be 63 characters long (nsec format)")
 		}
 	}
 
+	// The public key must belong to the private key, or owner checks and event authorship disagree
+	if warning := cfg.Nostr.reconcilePublicKey(); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	if signer := strings.TrimSpace(cfg.Nostr.EventSigner); signer != "" && signer != EventSignerConfig && !cfg.Nostr.BrowserSigning() {
+		warnings = append(warnings, fmt.Sprintf("nostr.event_signer %q is not recognised - signing with the configured key", signer))
+	}
+
+	if _, err := cfg.RTMP.rtmpBindHost(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("%v - RTMP listens on %s instead", err, cfg.GetRTMPDefaults().Host))
+	}
+
 	// Check if relays are configured
-	if len(cfg.Nostr.Relays) == 0 {
+	if len(cfg.Nostr.AllRelays()) == 0 {
 		warnings = append(warnings, "No Nostr relays configured - events will not be published")
 	}
 
-	// Print warnings
-	if len(warnings) > 0 {
-		fmt.Println("⚠️  Configuration Warnings:")
-		for _, warning := range warnings {
-			fmt.Printf("   • %s\n", warning)
+	// Filters need re-encoding, so they can't be combined with stream copy
+	if cfg.FFmpeg.VideoFilters != "" && cfg.FFmpeg.copiesCodec("v") {
+		warnings = append(warnings, "ffmpeg.video_filters is ignored because ffmpeg.extra_args copy the video stream")
+	}
+	if cfg.FFmpeg.AudioFilters != "" && cfg.FFmpeg.copiesCodec("a") {
+		warnings = append(warnings, "ffmpeg.audio_filters is ignored because ffmpeg.extra_args copy the audio stream")
+	}
+
+	// ABR needs a single re-encoded video playlist per rendition
+	if cfg.ABR.Auto {
+		if cfg.FFmpeg.copiesCodec("v") {
+			warnings = append(warnings, "abr.auto is ignored because ffmpeg.extra_args copy the video stream")
+		} else if hls := cfg.GetHLSConfig(); hls.AudioTrackCount() > 1 || hls.LowLatency {
+			warnings = append(warnings, "abr.auto is ignored with hls.audio_tracks > 1 or hls.low_latency")
+		}
+	}
+
+	warnings = append(warnings, cfg.FFmpeg.encodeWarnings(cfg.ABREnabled(cfg.GetHLSConfig()))...)
+
+	// Skipped access entries would silently change who can watch
+	if rules, err := cfg.Access.IPRules(); err != nil {
+		warnings = append(warnings, "access lists have entries that are ignored: "+strings.ReplaceAll(err.Error(), "\n", ", "))
+		if rules.DeniesAll() {
+			warnings = append(warnings, "access.allowlist has no valid entries - every client is denied until it is fixed")
 		}
-		fmt.Println("   💡 Edit config.yml to fix these issues")
-		fmt.Println()
 	}
+
+	return warnings
 }
 
 // LoadStreamInfo loads stream info from YAML file, creating a default if it doesn't exist
@@ -269,24 +804,22 @@ func (cfg *Config) GetStreamMetadata() *StreamMetadata {
 		Title:   cfg.StreamInfo.Title,
 		Summary: cfg.StreamInfo.Summary,
 		Image:   cfg.StreamInfo.Image,
-		Tags:    cfg.StreamInfo.Tags,
+		Tags:     cfg.StreamInfo.Tags,
+		Category: cfg.StreamInfo.Category,
 	}
 }
 
-// GetHLSConfig returns HLS configuration with defaults applied
+// GetHLSConfig returns HLS configuration with defaults applied. The hls block of stream-info.yml
+// is the only source of HLS settings: every FFmpeg launch, the CLI and the web server read them here.
 func (cfg *Config) GetHLSConfig() *HLSConfig {
 	cfg.streamInfoMutex.RLock()
 	defer cfg.streamInfoMutex.RUnlock()
-	
-	if cfg.StreamInfo == nil {
-		// Return defaults if no stream info
-		return &HLSConfig{
-			SegmentTime:  10,
-			PlaylistSize: 10,
-		}
-	}
 
-	hls := cfg.StreamInfo.HLS
+	// Without stream info every setting takes its default
+	var hls HLSConfig
+	if cfg.StreamInfo != nil {
+		hls = cfg.StreamInfo.HLS
+	}
 	
 	// Apply defaults if not set
 	if hls.SegmentTime == 0 {
@@ -295,15 +828,42 @@ func (cfg *Config) GetHLSConfig() *HLSConfig {
 	if hls.PlaylistSize == 0 {
 		hls.PlaylistSize = 10
 	}
+	if hls.DVRWindow < 0 {
+		hls.DVRWindow = 0
+	}
+	// Low-latency mode needs fMP4 and short segments
+	if hls.LowLatency {
+		hls.SegmentType = "fmp4"
+		if hls.SegmentTime > lowLatencyMaxSegmentTime {
+			hls.SegmentTime = lowLatencyMaxSegmentTime
+		}
+	}
+	if hls.SegmentType != "fmp4" {
+		hls.SegmentType = "mpegts"
+	}
+	switch strings.ToLower(strings.TrimSpace(hls.PlaylistCheck)) {
+	case PlaylistCheckLog:
+		hls.PlaylistCheck = PlaylistCheckLog
+	case PlaylistCheckOff:
+		hls.PlaylistCheck = PlaylistCheckOff
+	default:
+		hls.PlaylistCheck = PlaylistCheckRepair
+	}
+	// Segment pattern must number segments and stay inside the output directory
+	if !strings.Contains(hls.SegmentFilename, "%") || strings.ContainsAny(hls.SegmentFilename, `/\`) {
+		hls.SegmentFilename = ""
+	}
 
 	return &hls
 }
 
-// CheckAndReloadStreamInfo checks if stream info file has been modified and reloads if needed
+// CheckAndReloadStreamInfo checks if stream info file has been modified and reloads if needed.
+// A missing or malformed file keeps the last good stream info in use: it is reported once and
+// picked up again as soon as the file is valid.
 func (cfg *Config) CheckAndReloadStreamInfo() (*StreamInfo, bool, error) {
 	fileInfo, err := os.Stat(cfg.StreamInfoPath)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to stat stream info file: %w", err)
+		return cfg.keepLastStreamInfo(fmt.Errorf("failed to stat stream info file: %w", err))
 	}
 
 	cfg.streamInfoMutex.RLock()
@@ -316,21 +876,53 @@ func (cfg *Config) CheckAndReloadStreamInfo() (*StreamInfo, bool, error) {
 		// File was modified, reload it
 		newInfo, newModTime, err := LoadStreamInfoWithModTime(cfg.StreamInfoPath)
 		if err != nil {
-			return nil, false, fmt.Errorf("failed to reload stream info: %w", err)
+			return cfg.keepLastStreamInfo(fmt.Errorf("failed to reload stream info: %w", err))
 		}
 
 		cfg.streamInfoMutex.Lock()
+		recovered := cfg.streamInfoErr != nil
 		cfg.StreamInfo = newInfo
 		cfg.streamInfoModTime = newModTime
+		cfg.streamInfoErr = nil
 		cfg.streamInfoMutex.Unlock()
 
+		if recovered {
+			fmt.Printf("✅ Stream info file is valid again: %s\n", cfg.StreamInfoPath)
+		}
 		fmt.Printf("📝 Stream info reloaded from: %s\n", cfg.StreamInfoPath)
 		return newInfo, true, nil
 	}
 
+	cfg.streamInfoMutex.RLock()
+	defer cfg.streamInfoMutex.RUnlock()
+	return cfg.StreamInfo, false, nil
+}
+
+// keepLastStreamInfo records a reload failure and keeps the last good stream info, warning
+// only when the problem first appears or changes so watchers don't log it every tick
+func (cfg *Config) keepLastStreamInfo(reloadErr error) (*StreamInfo, bool, error) {
+	cfg.streamInfoMutex.Lock()
+	defer cfg.streamInfoMutex.Unlock()
+
+	if cfg.StreamInfo == nil {
+		// Nothing to fall back to
+		return nil, false, reloadErr
+	}
+
+	if cfg.streamInfoErr == nil || cfg.streamInfoErr.Error() != reloadErr.Error() {
+		fmt.Printf("⚠️ %v - keeping the last good stream info until the file is fixed\n", reloadErr)
+	}
+	cfg.streamInfoErr = reloadErr
 	return cfg.StreamInfo, false, nil
 }
 
+// StreamInfoError returns why the stream info file on disk is currently being ignored, or nil
+func (cfg *Config) StreamInfoError() error {
+	cfg.streamInfoMutex.RLock()
+	defer cfg.streamInfoMutex.RUnlock()
+	return cfg.streamInfoErr
+}
+
 // SaveStreamMetadata saves stream metadata to JSON file
 func SaveStreamMetadata(path string, metadata *StreamMetadata) error {
 	// Convert to map for JSON serialization with lowercase keys
@@ -339,6 +931,7 @@ func SaveStreamMetadata(path string, metadata *StreamMetadata) error {
 		"summary":          metadata.Summary,
 		"image":            metadata.Image,
 		"tags":             metadata.Tags,
+		"category":         metadata.Category,
 		"pubkey":           metadata.Pubkey,
 		"dtag":             metadata.Dtag,
 		"stream_url":       metadata.StreamURL,
@@ -353,6 +946,21 @@ func SaveStreamMetadata(path string, metadata *StreamMetadata) error {
 	return SaveJSON(path, data)
 }
 
+// LoadStreamMetadata loads runtime stream metadata from a JSON file written by SaveStreamMetadata
+func LoadStreamMetadata(path string) (*StreamMetadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file %s: %w", path, err)
+	}
+
+	var metadata StreamMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", path, err)
+	}
+
+	return &metadata, nil
+}
+
 // SaveJSON saves data to JSON file with pretty formatting
 func SaveJSON(path string, data interface{}) error {
 	file, err := os.Create(path)
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadTestStreamInfo returns a config whose stream info was loaded from a file in a temp dir
+func loadTestStreamInfo(t *testing.T, content string) *Config {
+	t.Helper()
+
+	path := writeTestFile(t, t.TempDir(), "stream-info.yml", content)
+	info, modTime, err := LoadStreamInfoWithModTime(path)
+	if err != nil {
+		t.Fatalf("LoadStreamInfoWithModTime: %v", err)
+	}
+	return &Config{StreamInfoPath: path, StreamInfo: info, streamInfoModTime: modTime}
+}
+
+// rewriteStreamInfo replaces the file and moves its mod time forward so the change is seen
+func rewriteStreamInfo(t *testing.T, cfg *Config, content string, age time.Duration) {
+	t.Helper()
+
+	if err := os.WriteFile(cfg.StreamInfoPath, []byte(content), 0644); err != nil {
+		t.Fatalf("writing stream info: %v", err)
+	}
+	modTime := time.Now().Add(age)
+	if err := os.Chtimes(cfg.StreamInfoPath, modTime, modTime); err != nil {
+		t.Fatalf("setting stream info mod time: %v", err)
+	}
+}
+
+// captureStdout returns what fn prints; config reports reload problems on stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+
+	fn()
+	writer.Close()
+	return <-output
+}
+
+// assertKeepsLastGood checks that a reload keeps the original stream info and reports why
+func assertKeepsLastGood(t *testing.T, cfg *Config) {
+	t.Helper()
+
+	info, changed, err := cfg.CheckAndReloadStreamInfo()
+	if err != nil {
+		t.Fatalf("CheckAndReloadStreamInfo returned %v, want the last good stream info", err)
+	}
+	if changed {
+		t.Error("CheckAndReloadStreamInfo reported a change")
+	}
+	if info == nil || info.Title != "Good" {
+		t.Errorf("stream info = %+v, want the last good one", info)
+	}
+	if cfg.StreamInfoError() == nil {
+		t.Error("StreamInfoError() = nil, want the reload failure")
+	}
+}
+
+func TestCheckAndReloadStreamInfoMissingFile(t *testing.T) {
+	cfg := loadTestStreamInfo(t, "title: \"Good\"\n")
+	if err := os.Remove(cfg.StreamInfoPath); err != nil {
+		t.Fatalf("removing stream info: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		assertKeepsLastGood(t, cfg)
+		assertKeepsLastGood(t, cfg)
+		assertKeepsLastGood(t, cfg)
+	})
+	if warnings := strings.Count(output, "keeping the last good stream info"); warnings != 1 {
+		t.Errorf("logged %d warnings for the same missing file, want 1:\n%s", warnings, output)
+	}
+
+	// Restoring the file resumes reloading
+	rewriteStreamInfo(t, cfg, "title: \"Restored\"\n", time.Minute)
+	captureStdout(t, func() {
+		info, changed, err := cfg.CheckAndReloadStreamInfo()
+		if err != nil || !changed || info.Title != "Restored" {
+			t.Errorf("after restore got %+v, changed %t, err %v; want the restored info", info, changed, err)
+		}
+	})
+	if err := cfg.StreamInfoError(); err != nil {
+		t.Errorf("StreamInfoError() = %v after the file was restored, want nil", err)
+	}
+}
+
+func TestCheckAndReloadStreamInfoMalformedFile(t *testing.T) {
+	cfg := loadTestStreamInfo(t, "title: \"Good\"\nhls:\n  segment_time: 4\n")
+	rewriteStreamInfo(t, cfg, "title: [unclosed\nhls: {\n", time.Minute)
+
+	output := captureStdout(t, func() {
+		assertKeepsLastGood(t, cfg)
+		assertKeepsLastGood(t, cfg)
+	})
+	if warnings := strings.Count(output, "keeping the last good stream info"); warnings != 1 {
+		t.Errorf("logged %d warnings for the same malformed file, want 1:\n%s", warnings, output)
+	}
+	if got := cfg.GetHLSConfig().SegmentTime; got != 4 {
+		t.Errorf("segment_time = %d while the file is malformed, want the last good 4", got)
+	}
+
+	// A different problem is reported again
+	if err := os.Remove(cfg.StreamInfoPath); err != nil {
+		t.Fatalf("removing stream info: %v", err)
+	}
+	output = captureStdout(t, func() { assertKeepsLastGood(t, cfg) })
+	if !strings.Contains(output, "keeping the last good stream info") {
+		t.Errorf("a new reload error was not reported:\n%s", output)
+	}
+
+	rewriteStreamInfo(t, cfg, "title: \"Fixed\"\n", 2*time.Minute)
+	output = captureStdout(t, func() {
+		info, changed, err := cfg.CheckAndReloadStreamInfo()
+		if err != nil || !changed || info.Title != "Fixed" {
+			t.Errorf("after fix got %+v, changed %t, err %v; want the fixed info", info, changed, err)
+		}
+	})
+	if !strings.Contains(output, "valid again") {
+		t.Errorf("recovery was not reported:\n%s", output)
+	}
+}
+
+func TestCheckAndReloadStreamInfoWithoutLastGood(t *testing.T) {
+	cfg := &Config{StreamInfoPath: filepath.Join(t.TempDir(), "missing.yml")}
+
+	info, changed, err := cfg.CheckAndReloadStreamInfo()
+	if err == nil {
+		t.Errorf("CheckAndReloadStreamInfo returned %+v with no error, want an error when nothing was ever loaded", info)
+	}
+	if changed {
+		t.Error("CheckAndReloadStreamInfo reported a change")
+	}
+}
+
+func TestGetHLSConfigEffectiveValues(t *testing.T) {
+	tests := []struct {
+		name            string
+		streamInfo      string // Empty means no stream info loaded
+		wantSegmentTime int
+		wantPlaylist    int
+		wantSegmentType string
+	}{
+		{name: "no stream info", wantSegmentTime: 10, wantPlaylist: 10, wantSegmentType: "mpegts"},
+		{name: "no hls block", streamInfo: "title: \"Test\"\n", wantSegmentTime: 10, wantPlaylist: 10, wantSegmentType: "mpegts"},
+		{name: "configured", streamInfo: "hls:\n  segment_time: 4\n  playlist_size: 6\n", wantSegmentTime: 4, wantPlaylist: 6, wantSegmentType: "mpegts"},
+		{name: "fmp4", streamInfo: "hls:\n  segment_type: \"fmp4\"\n", wantSegmentTime: 10, wantPlaylist: 10, wantSegmentType: "fmp4"},
+		{name: "unknown segment type", streamInfo: "hls:\n  segment_type: \"webm\"\n", wantSegmentTime: 10, wantPlaylist: 10, wantSegmentType: "mpegts"},
+		{name: "low latency caps segments", streamInfo: "hls:\n  segment_time: 6\n  low_latency: true\n", wantSegmentTime: 2, wantPlaylist: 10, wantSegmentType: "fmp4"},
+		{name: "low latency keeps short segments", streamInfo: "hls:\n  segment_time: 1\n  low_latency: true\n", wantSegmentTime: 1, wantPlaylist: 10, wantSegmentType: "fmp4"},
+		{name: "low latency default segments", streamInfo: "hls:\n  low_latency: true\n", wantSegmentTime: 2, wantPlaylist: 10, wantSegmentType: "fmp4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			if tt.streamInfo != "" {
+				cfg = loadTestStreamInfo(t, tt.streamInfo)
+			}
+
+			hls := cfg.GetHLSConfig()
+			if hls.SegmentTime != tt.wantSegmentTime || hls.PlaylistSize != tt.wantPlaylist || hls.SegmentType != tt.wantSegmentType {
+				t.Errorf("GetHLSConfig() = segment_time %d, playlist_size %d, segment_type %q; want %d, %d, %q",
+					hls.SegmentTime, hls.PlaylistSize, hls.SegmentType, tt.wantSegmentTime, tt.wantPlaylist, tt.wantSegmentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Output encode defaults and limits
+const (
+	defaultCRF          = 18
+	maxCRF              = 51
+	defaultAudioBitrate = 160
+	minAudioBitrate     = 32
+	maxAudioBitrate     = 512
+	minVideoBitrate     = 100
+)
+
+// crf returns the x264 constant quality, falling back to the default when out of range
+func (f *FFmpegConfig) crf() int {
+	if f.CRF <= 0 || f.CRF > maxCRF {
+		return defaultCRF
+	}
+	return f.CRF
+}
+
+// audioBitrate returns the AAC bitrate in kbps, falling back to the default when out of range
+func (f *FFmpegConfig) audioBitrate() int {
+	if f.AudioBitrate < minAudioBitrate || f.AudioBitrate > maxAudioBitrate {
+		return defaultAudioBitrate
+	}
+	return f.AudioBitrate
+}
+
+// videoBitrate returns the target video bitrate in kbps, or 0 for a CRF encode
+func (f *FFmpegConfig) videoBitrate() int {
+	if f.VideoBitrate < minVideoBitrate {
+		return 0
+	}
+	return f.VideoBitrate
+}
+
+// maxHeight returns the output height cap rounded down to an even number (H.264), or 0 for none
+func (f *FFmpegConfig) maxHeight() int {
+	if f.MaxHeight < 2 {
+		return 0
+	}
+	return f.MaxHeight - f.MaxHeight%2
+}
+
+// EncodeArgs returns the codec, rate control and framerate arguments for the HLS encode. A target
+// bitrate replaces CRF and is capped with a matching VBV buffer; with ABR each rendition has its
+// own peak bitrate, so only CRF applies.
+func (f *FFmpegConfig) EncodeArgs(abr bool) []string {
+	args := []string{"-c:v", "libx264"}
+	if kbps := f.videoBitrate(); kbps > 0 && !abr {
+		args = append(args,
+			"-b:v", fmt.Sprintf("%dk", kbps),
+			"-maxrate", fmt.Sprintf("%dk", kbps),
+			"-bufsize", fmt.Sprintf("%dk", kbps*2),
+		)
+	} else {
+		args = append(args, "-crf", strconv.Itoa(f.crf()))
+	}
+	args = append(args, "-preset", "veryfast")
+
+	// A framerate can't be forced on a stream-copied video
+	if f.MaxFPS > 0 && !f.copiesCodec("v") {
+		args = append(args, "-fpsmax", strconv.Itoa(f.MaxFPS))
+	}
+
+	return append(args, "-c:a", "aac", "-b:a", fmt.Sprintf("%dk", f.audioBitrate()))
+}
+
+// videoFilterChain returns video_filters followed by the max_height downscale, if any
+func (f *FFmpegConfig) videoFilterChain() string {
+	chain := f.VideoFilters
+	if height := f.maxHeight(); height > 0 {
+		if chain != "" {
+			chain += ","
+		}
+		chain += fmt.Sprintf("scale=-2:'min(%d,ih)'", height)
+	}
+	return chain
+}
+
+// encodeWarnings reports output settings that are out of range or cancel each other out
+func (f *FFmpegConfig) encodeWarnings(abr bool) []string {
+	var warnings []string
+
+	if f.CRF != 0 && f.VideoBitrate != 0 {
+		warnings = append(warnings, "ffmpeg.crf and ffmpeg.video_bitrate are mutually exclusive - encoding to the target bitrate")
+	}
+	if f.CRF < 0 || f.CRF > maxCRF {
+		warnings = append(warnings, fmt.Sprintf("ffmpeg.crf %d is out of range (1-%d) - using %d", f.CRF, maxCRF, defaultCRF))
+	}
+	if f.VideoBitrate != 0 && f.videoBitrate() == 0 {
+		warnings = append(warnings, fmt.Sprintf("ffmpeg.video_bitrate %dk is below %dk - using CRF instead", f.VideoBitrate, minVideoBitrate))
+	}
+	if f.videoBitrate() > 0 && abr {
+		warnings = append(warnings, "ffmpeg.video_bitrate is ignored with abr.auto - each rendition has its own peak bitrate")
+	}
+	if f.AudioBitrate != 0 && f.audioBitrate() != f.AudioBitrate {
+		warnings = append(warnings, fmt.Sprintf("ffmpeg.audio_bitrate %dk is out of range (%d-%dk) - using %dk",
+			f.AudioBitrate, minAudioBitrate, maxAudioBitrate, defaultAudioBitrate))
+	}
+	if f.MaxHeight < 0 || f.MaxHeight == 1 {
+		warnings = append(warnings, fmt.Sprintf("ffmpeg.max_height %d is invalid - keeping the input resolution", f.MaxHeight))
+	}
+	if f.MaxFPS < 0 {
+		warnings = append(warnings, fmt.Sprintf("ffmpeg.max_fps %d is invalid - keeping the input framerate", f.MaxFPS))
+	}
+	if f.copiesCodec("v") && (f.videoBitrate() > 0 || f.maxHeight() > 0 || f.MaxFPS > 0) {
+		warnings = append(warnings, "ffmpeg.video_bitrate, max_height and max_fps are ignored because ffmpeg.extra_args copy the video stream")
+	}
+	return warnings
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/0ceanslim/grain/client/core/tools"
+
+	"gnostream/src/nip19"
+)
+
+// PrivateKeyEnv overrides nostr.private_key and nostr.private_key_file when set
+const PrivateKeyEnv = "GNOSTREAM_NOSTR_PRIVATE_KEY"
+
+// placeholderPrivateKey is the private_key value shipped in config.example.yml
+const placeholderPrivateKey = "your-nostr-private-key-nsec"
+
+// ResolvePrivateKey returns the private key (nsec or hex) from, in order of precedence, the
+// GNOSTREAM_NOSTR_PRIVATE_KEY environment variable, private_key_file and the inline
+// private_key. It returns "" when none is configured. The key file is read on every call, so a
+// rotated key is picked up without editing config.yml.
+func (nc *NostrRelayConfig) ResolvePrivateKey() (string, error) {
+	if key := strings.TrimSpace(os.Getenv(PrivateKeyEnv)); key != "" {
+		return key, nil
+	}
+
+	if nc.PrivateKeyFile != "" {
+		data, err := os.ReadFile(nc.PrivateKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read nostr.private_key_file: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", fmt.Errorf("nostr.private_key_file %s is empty", nc.PrivateKeyFile)
+		}
+		return key, nil
+	}
+
+	key := strings.TrimSpace(nc.PrivateKey)
+	if key == placeholderPrivateKey {
+		return "", nil
+	}
+	return key, nil
+}
+
+// PrivateKeySource describes where ResolvePrivateKey takes the key from
+func (nc *NostrRelayConfig) PrivateKeySource() string {
+	switch {
+	case strings.TrimSpace(os.Getenv(PrivateKeyEnv)) != "":
+		return "$" + PrivateKeyEnv
+	case nc.PrivateKeyFile != "":
+		return nc.PrivateKeyFile
+	default:
+		return "config.yml"
+	}
+}
+
+// PrivateKeyHex returns the configured private key as hex ("" if none is configured)
+func (nc *NostrRelayConfig) PrivateKeyHex() (string, error) {
+	key, err := nc.ResolvePrivateKey()
+	if err != nil || key == "" {
+		return "", err
+	}
+
+	if strings.HasPrefix(key, "nsec") {
+		// Grain's decoder isn't used because it logs the key
+		decoded, err := nip19.DecodeNsec(key)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode nsec: %w", err)
+		}
+		return decoded, nil
+	}
+	return key, nil
+}
+
+// reconcilePublicKey derives PublicKey from the private key and compares it with the optional
+// public_key setting. The derived key always wins; a mismatch is returned as a warning, since
+// owner checks and event authorship would otherwise disagree.
+func (nc *NostrRelayConfig) reconcilePublicKey() string {
+	configured, err := nc.configuredPublicKeyHex()
+	if err != nil {
+		return err.Error()
+	}
+
+	privateKeyHex, err := nc.PrivateKeyHex()
+	if err != nil || privateKeyHex == "" {
+		// Without a private key, browser signing publishes as public_key; otherwise the missing
+		// key itself is reported elsewhere
+		if nc.BrowserSigning() {
+			nc.PublicKey = configured
+		}
+		return ""
+	}
+
+	derived, err := tools.DerivePublicKey(privateKeyHex)
+	if err != nil {
+		return fmt.Sprintf("Could not derive a public key from nostr.private_key: %v", err)
+	}
+	nc.PublicKey = derived
+
+	if configured != "" && configured != derived {
+		return fmt.Sprintf("❗ nostr.public_key (%s) does NOT match nostr.private_key (derives %s) - using the derived key; fix or remove public_key", configured, derived)
+	}
+	return ""
+}
+
+// configuredPublicKeyHex returns the optional public_key setting as lowercase hex
+func (nc *NostrRelayConfig) configuredPublicKeyHex() (string, error) {
+	configured := strings.TrimSpace(nc.ConfiguredPublicKey)
+	if strings.HasPrefix(configured, "npub") {
+		decoded, err := nip19.DecodeNpub(configured)
+		if err != nil {
+			return "", fmt.Errorf("nostr.public_key %q is not a valid npub", configured)
+		}
+		configured = decoded
+	}
+	return strings.ToLower(configured), nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// NIP-19 test vector key, in both encodings
+const (
+	testNsec       = "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5"
+	testPrivateHex = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+)
+
+func TestPrivateKeyHex(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := writeTestFile(t, dir, "nsec", "\n  "+testNsec+"  \n")
+	emptyFile := writeTestFile(t, dir, "empty", "\n")
+
+	tests := []struct {
+		name    string
+		env     string
+		nostr   NostrRelayConfig
+		want    string
+		wantErr string
+	}{
+		{name: "not configured", want: ""},
+		{name: "example placeholder", nostr: NostrRelayConfig{PrivateKey: placeholderPrivateKey}, want: ""},
+		{name: "inline nsec", nostr: NostrRelayConfig{PrivateKey: testNsec}, want: testPrivateHex},
+		{name: "inline hex", nostr: NostrRelayConfig{PrivateKey: testPrivateHex}, want: testPrivateHex},
+		{name: "key file wins over inline", nostr: NostrRelayConfig{PrivateKey: "nsec1other", PrivateKeyFile: keyFile}, want: testPrivateHex},
+		{name: "environment wins over file", env: testPrivateHex, nostr: NostrRelayConfig{PrivateKeyFile: emptyFile}, want: testPrivateHex},
+		{name: "missing key file", nostr: NostrRelayConfig{PrivateKeyFile: filepath.Join(dir, "missing")}, wantErr: "failed to read nostr.private_key_file"},
+		{name: "empty key file", nostr: NostrRelayConfig{PrivateKeyFile: emptyFile}, wantErr: "is empty"},
+		{name: "bad checksum", nostr: NostrRelayConfig{PrivateKey: testNsec[:len(testNsec)-1] + "6"}, wantErr: "checksum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PrivateKeyEnv, tt.env)
+
+			got, err := tt.nostr.PrivateKeyHex()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PrivateKeyHex() = %q, %v; want an error containing %q", got, err, tt.wantErr)
+				}
+				// Errors end up in logs and warnings, so they must never echo the key
+				if strings.Contains(err.Error(), tt.nostr.PrivateKey) && tt.nostr.PrivateKey != "" {
+					t.Errorf("error %q contains the private key", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("PrivateKeyHex() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcilePublicKey(t *testing.T) {
+	t.Setenv(PrivateKeyEnv, "")
+	const otherPubkey = "b3e1f8fbc4f57b50d4f5c6f2f3bb5e5c4c3a8f47c1c4b5b7a3e2b6c7f0b1e1b2"
+
+	nc := NostrRelayConfig{PrivateKey: testNsec}
+	if warning := nc.reconcilePublicKey(); warning != "" {
+		t.Fatalf("reconcilePublicKey warned without a configured public key: %s", warning)
+	}
+	if len(nc.PublicKey) != 64 {
+		t.Fatalf("derived PublicKey = %q, want 64 hex characters", nc.PublicKey)
+	}
+
+	mismatched := NostrRelayConfig{PrivateKey: testNsec, ConfiguredPublicKey: otherPubkey}
+	if warning := mismatched.reconcilePublicKey(); !strings.Contains(warning, "does NOT match") {
+		t.Errorf("mismatched public_key warning = %q, want a mismatch warning", warning)
+	}
+	if mismatched.PublicKey != nc.PublicKey {
+		t.Errorf("PublicKey = %q with a mismatched public_key, want the derived %q", mismatched.PublicKey, nc.PublicKey)
+	}
+}
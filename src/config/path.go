@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultConfigPath is the main config file used when neither --config nor $GNOSTREAM_CONFIG is set
+const DefaultConfigPath = "config.yml"
+
+// ConfigPathEnv selects the main config file when no --config flag is given
+const ConfigPathEnv = "GNOSTREAM_CONFIG"
+
+// ResolveConfigPath picks the main config file: the --config flag, then $GNOSTREAM_CONFIG, then config.yml
+func ResolveConfigPath(flagValue string) string {
+	if path := strings.TrimSpace(flagValue); path != "" {
+		return path
+	}
+	if path := strings.TrimSpace(os.Getenv(ConfigPathEnv)); path != "" {
+		return path
+	}
+	return DefaultConfigPath
+}
+
+// Path returns the file the config was loaded from (and is saved back to)
+func (cfg *Config) Path() string {
+	if cfg.path == "" {
+		return DefaultConfigPath
+	}
+	return cfg.path
+}
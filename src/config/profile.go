@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile selects the plain stream_info_path file
+const DefaultProfile = "default"
+
+// profileNamePattern keeps profile names usable as part of a file name
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ProfileStreamInfoPath returns the stream info file for a named profile,
+// e.g. stream-info.yml + "gaming" -> stream-info.gaming.yml
+func ProfileStreamInfoPath(basePath, name string) string {
+	if name == "" || name == DefaultProfile {
+		return basePath
+	}
+	ext := filepath.Ext(basePath)
+	return strings.TrimSuffix(basePath, ext) + "." + name + ext
+}
+
+// ListProfiles returns the named profiles found next to the base stream info file
+func (cfg *Config) ListProfiles() ([]string, error) {
+	ext := filepath.Ext(cfg.baseStreamInfoPath)
+	prefix := strings.TrimSuffix(cfg.baseStreamInfoPath, ext) + "."
+
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := []string{}
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		// Skip stream-info.example.yml and anything that isn't a valid profile name
+		if name == "example" || !profileNamePattern.MatchString(name) {
+			continue
+		}
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// ProfilePath validates a profile name and returns its stream info file, which must exist
+func (cfg *Config) ProfilePath(name string) (string, error) {
+	if name != "" && name != DefaultProfile && !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", name)
+	}
+
+	path := ProfileStreamInfoPath(cfg.baseStreamInfoPath, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("profile %q not found (expected %s)", name, path)
+	}
+	return path, nil
+}
+
+// ActiveProfile returns the name of the profile in use
+func (cfg *Config) ActiveProfile() string {
+	if cfg.Profile == "" {
+		return DefaultProfile
+	}
+	return cfg.Profile
+}
+
+// UseProfile switches to a named profile and loads its stream info
+func (cfg *Config) UseProfile(name string) error {
+	path, err := cfg.ProfilePath(name)
+	if err != nil {
+		return err
+	}
+
+	streamInfo, modTime, err := LoadStreamInfoWithModTime(path)
+	if err != nil {
+		return fmt.Errorf("failed to load profile %q: %w", name, err)
+	}
+
+	cfg.streamInfoMutex.Lock()
+	defer cfg.streamInfoMutex.Unlock()
+
+	if name == DefaultProfile {
+		name = ""
+	}
+	cfg.Profile = name
+	cfg.StreamInfoPath = path
+	cfg.StreamInfo = streamInfo
+	cfg.streamInfoModTime = modTime
+	return nil
+}
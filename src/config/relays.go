@@ -0,0 +1,34 @@
+package config
+
+// WriteRelayList returns the relays events are published to first: write_relays, or relays when unset
+func (n *NostrRelayConfig) WriteRelayList() []string {
+	if len(n.WriteRelays) > 0 {
+		return n.WriteRelays
+	}
+	return n.Relays
+}
+
+// ReadRelayList returns the relays chat and event queries subscribe to first: read_relays, or
+// relays when unset
+func (n *NostrRelayConfig) ReadRelayList() []string {
+	if len(n.ReadRelays) > 0 {
+		return n.ReadRelays
+	}
+	return n.Relays
+}
+
+// AllRelays returns every configured relay (relays, then write_relays and read_relays) without
+// duplicates. These are all connected; relays outside the write or read set serve as fallbacks.
+func (n *NostrRelayConfig) AllRelays() []string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, list := range [][]string{n.Relays, n.WriteRelays, n.ReadRelays} {
+		for _, relay := range list {
+			if !seen[relay] {
+				seen[relay] = true
+				all = append(all, relay)
+			}
+		}
+	}
+	return all
+}
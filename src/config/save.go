@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFile mirrors the sections of config.yml that SaveConfig writes. Runtime-only state
+// (StreamInfo, the reload mutex and mod time, the derived public key) never reaches the file.
+type configFile struct {
+	Server         ServerConfig     `yaml:"server"`
+	Logging        LoggingConfig    `yaml:"logging"`
+	FFmpeg         FFmpegConfig     `yaml:"ffmpeg"`
+	FFprobe        FFprobeConfig    `yaml:"ffprobe"`
+	Chat           ChatConfig       `yaml:"chat"`
+	Stream         StreamConfig     `yaml:"stream"`
+	ABR            ABRConfig        `yaml:"abr"`
+	Webhooks       WebhooksConfig   `yaml:"webhooks"`
+	Access         AccessConfig     `yaml:"access"`
+	RTMP           RTMPConfig       `yaml:"rtmp"`
+	StreamInfoPath string           `yaml:"stream_info_path"`
+	Profile        string           `yaml:"profile"`
+	Nostr          NostrRelayConfig `yaml:"nostr"`
+}
+
+// SaveConfig writes the main configuration to a YAML file. Values are merged into the
+// existing file, so its comments, key order and any unknown keys are kept.
+func SaveConfig(path string, cfg *Config) error {
+	file := configFile{
+		Server:         cfg.Server,
+		Logging:        cfg.Logging,
+		FFmpeg:         cfg.FFmpeg,
+		FFprobe:        cfg.FFprobe,
+		Chat:           cfg.Chat,
+		Stream:         cfg.Stream,
+		ABR:            cfg.ABR,
+		Webhooks:       cfg.Webhooks,
+		Access:         cfg.Access,
+		RTMP:           cfg.RTMP,
+		StreamInfoPath: cfg.baseStreamInfoPath,
+		Profile:        cfg.Profile,
+		Nostr:          cfg.Nostr,
+	}
+	if file.StreamInfoPath == "" {
+		file.StreamInfoPath = cfg.StreamInfoPath
+	}
+
+	var updated yaml.Node
+	if err := updated.Encode(&file); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	doc := yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	if data, err := os.ReadFile(path); err == nil {
+		var existing yaml.Node
+		if err := yaml.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("failed to parse config: %w", err)
+		}
+		if len(existing.Content) > 0 && existing.Content[0].Kind == yaml.MappingNode {
+			doc = existing
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	mergeYAMLMapping(doc.Content[0], &updated)
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+
+	if err := os.WriteFile(path, []byte(out.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// mergeYAMLMapping copies src's keys into dst, recursing into nested sections and
+// keeping the comments attached to values that are replaced
+func mergeYAMLMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		var existing *yaml.Node
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				existing = dst.Content[j+1]
+				break
+			}
+		}
+
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeYAMLMapping(existing, value)
+		default:
+			keepScalarStyle(existing, value)
+			value.HeadComment = existing.HeadComment
+			value.LineComment = existing.LineComment
+			value.FootComment = existing.FootComment
+			*existing = *value
+		}
+	}
+}
+
+// keepScalarStyle reuses the existing quoting style (e.g. "double quoted") for replaced strings
+func keepScalarStyle(existing, value *yaml.Node) {
+	if existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode && len(existing.Content) > 0 {
+		for _, item := range value.Content {
+			keepScalarStyle(existing.Content[0], item)
+		}
+		return
+	}
+	if existing.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode && existing.Tag == value.Tag {
+		value.Style = existing.Style
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTestFile writes content to name inside dir and returns its path
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+	return path
+}
+
+func TestSaveConfigRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	streamInfoPath := writeTestFile(t, dir, "stream-info.yml", "title: \"Round trip\"\ntags:\n  - test\n")
+	configPath := writeTestFile(t, dir, "config.yml", `# gnostream test config
+server:
+  port: 8080 # web UI port
+  host: "0.0.0.0"
+rtmp:
+  port: 1935
+stream_info_path: "`+streamInfoPath+`"
+nostr:
+  relays:
+    - "wss://relay.one"
+custom_section:
+  kept: true
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	cfg.Server.Port = 9090
+	cfg.RTMP.StreamKey = "obs"
+	cfg.RTMP.AuthURL = "http://auth.local/publish"
+	cfg.Nostr.Relays = append(cfg.Nostr.Relays, "wss://relay.two")
+	cfg.Nostr.DeleteNonRecorded = true
+	cfg.StreamInfo.Title = "Changed in memory only"
+
+	if err := SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	saved, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load after save: %v", err)
+	}
+
+	if saved.Server.Port != 9090 || saved.Server.Host != "0.0.0.0" {
+		t.Errorf("server = %s:%d, want 0.0.0.0:9090", saved.Server.Host, saved.Server.Port)
+	}
+	if saved.RTMP.Port != 1935 || saved.RTMP.StreamKey != "obs" || saved.RTMP.AuthURL != "http://auth.local/publish" {
+		t.Errorf("rtmp = %+v, want port 1935, stream key obs and the auth URL", saved.RTMP)
+	}
+	if want := []string{"wss://relay.one", "wss://relay.two"}; !slices.Equal(saved.Nostr.Relays, want) {
+		t.Errorf("nostr.relays = %v, want %v", saved.Nostr.Relays, want)
+	}
+	if !saved.Nostr.DeleteNonRecorded {
+		t.Error("nostr.delete_non_recorded was not saved")
+	}
+	if saved.StreamInfoPath != streamInfoPath {
+		t.Errorf("stream_info_path = %q, want %q", saved.StreamInfoPath, streamInfoPath)
+	}
+	if saved.StreamInfo.Title != "Round trip" {
+		t.Errorf("stream info title = %q, SaveConfig must not touch stream-info.yml", saved.StreamInfo.Title)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("reading saved config: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"# gnostream test config", "# web UI port", "custom_section:", "kept: true"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("saved config lost %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"Changed in memory only", "title:", "streaminfo", "modtime"} {
+		if strings.Contains(strings.ToLower(text), strings.ToLower(unwanted)) {
+			t.Errorf("saved config contains runtime-only %q:\n%s", unwanted, text)
+		}
+	}
+}
+
+func TestSaveConfigCreatesMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	streamInfoPath := writeTestFile(t, dir, "stream-info.yml", "title: \"New file\"\n")
+	configPath := filepath.Join(dir, "config.yml")
+
+	cfg := &Config{
+		Server:         ServerConfig{Host: "127.0.0.1", Port: 8181},
+		RTMP:           RTMPConfig{Port: 1936},
+		StreamInfoPath: streamInfoPath,
+	}
+	cfg.Nostr.Relays = []string{"wss://relay.one"}
+
+	if err := SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	saved, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if saved.Server.Host != "127.0.0.1" || saved.Server.Port != 8181 || saved.RTMP.Port != 1936 {
+		t.Errorf("saved config = server %s:%d rtmp %d, want 127.0.0.1:8181 rtmp 1936",
+			saved.Server.Host, saved.Server.Port, saved.RTMP.Port)
+	}
+	if !slices.Equal(saved.Nostr.Relays, cfg.Nostr.Relays) {
+		t.Errorf("nostr.relays = %v, want %v", saved.Nostr.Relays, cfg.Nostr.Relays)
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Repository files the schema describes, relative to this package
+const (
+	schemaPath        = "../../stream-info.schema.json"
+	examplePath       = "../../stream-info.example.yml"
+	readmePath        = "../../readme.md"
+	readmeExampleHead = "### stream-info.yml"
+)
+
+// jsonSchema is the subset of JSON Schema stream-info.schema.json uses
+type jsonSchema struct {
+	Type                 string                 `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []any                  `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	Pattern              string                 `json:"pattern"`
+	Default              any                    `json:"default"`
+}
+
+func loadSchema(t *testing.T) *jsonSchema {
+	t.Helper()
+
+	data, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+	var schema jsonSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("parsing schema: %v", err)
+	}
+	return &schema
+}
+
+// validate checks a decoded YAML value against schema, returning every violation
+func (schema *jsonSchema) validate(path string, value any) []string {
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(allowed any) bool { return allowed == value }) {
+		fail("%v is not one of %v", value, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("want an object, got %T", value)
+			break
+		}
+		for key, child := range object {
+			property, known := schema.Properties[key]
+			if !known {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					fail("unknown key %q", key)
+				}
+				continue
+			}
+			errs = append(errs, property.validate(path+"."+key, child)...)
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			fail("want an array, got %T", value)
+			break
+		}
+		for i, item := range array {
+			if schema.Items != nil {
+				errs = append(errs, schema.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			fail("want a string, got %T", value)
+			break
+		}
+		if schema.Pattern != "" && !regexp.MustCompile(schema.Pattern).MatchString(text) {
+			fail("%q does not match %s", text, schema.Pattern)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("want a boolean, got %T", value)
+		}
+	case "integer":
+		number, ok := value.(int)
+		if !ok {
+			fail("want an integer, got %T", value)
+			break
+		}
+		if schema.Minimum != nil && float64(number) < *schema.Minimum {
+			fail("%d is below the minimum %v", number, *schema.Minimum)
+		}
+		if schema.Maximum != nil && float64(number) > *schema.Maximum {
+			fail("%d is above the maximum %v", number, *schema.Maximum)
+		}
+	}
+	return errs
+}
+
+// validateYAML decodes a stream-info document and validates it against the schema
+func validateYAML(t *testing.T, schema *jsonSchema, document string) []string {
+	t.Helper()
+
+	var value map[string]any
+	if err := yaml.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatalf("parsing YAML: %v", err)
+	}
+	if value == nil {
+		value = map[string]any{}
+	}
+	return schema.validate("stream-info", value)
+}
+
+// readmeStreamInfoExample returns the stream-info.yml block documented in the readme
+func readmeStreamInfoExample(t *testing.T) string {
+	t.Helper()
+
+	data, err := os.ReadFile(readmePath)
+	if err != nil {
+		t.Fatalf("reading readme: %v", err)
+	}
+	_, section, found := strings.Cut(string(data), readmeExampleHead)
+	if !found {
+		t.Fatalf("readme has no %q section", readmeExampleHead)
+	}
+	_, block, found := strings.Cut(section, "```yaml\n")
+	if !found {
+		t.Fatal("readme stream-info.yml section has no YAML example")
+	}
+	block, _, _ = strings.Cut(block, "```")
+	return block
+}
+
+func TestStreamInfoExamplesMatchSchema(t *testing.T) {
+	schema := loadSchema(t)
+
+	example, err := os.ReadFile(examplePath)
+	if err != nil {
+		t.Fatalf("reading example: %v", err)
+	}
+	examples := map[string]string{
+		"stream-info.example.yml": string(example),
+		"readme":                  readmeStreamInfoExample(t),
+	}
+
+	for name, document := range examples {
+		t.Run(name, func(t *testing.T) {
+			for _, problem := range validateYAML(t, schema, document) {
+				t.Error(problem)
+			}
+
+			// The example must also load into StreamInfo exactly, with no keys the model lacks
+			decoder := yaml.NewDecoder(strings.NewReader(document))
+			decoder.KnownFields(true)
+			var info StreamInfo
+			if err := decoder.Decode(&info); err != nil {
+				t.Errorf("decoding into StreamInfo: %v", err)
+			}
+		})
+	}
+}
+
+func TestStreamInfoSchemaRejectsInvalidValues(t *testing.T) {
+	schema := loadSchema(t)
+
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{name: "unknown top-level key", document: "titel: \"typo\"\n", wantErr: `unknown key "titel"`},
+		{name: "unknown hls key", document: "hls:\n  segment_tim: 4\n", wantErr: `unknown key "segment_tim"`},
+		{name: "segment time as text", document: "hls:\n  segment_time: \"4\"\n", wantErr: "want an integer"},
+		{name: "negative playlist size", document: "hls:\n  playlist_size: -1\n", wantErr: "below the minimum"},
+		{name: "too many audio tracks", document: "hls:\n  audio_tracks: 9\n", wantErr: "above the maximum"},
+		{name: "unknown segment type", document: "hls:\n  segment_type: \"webm\"\n", wantErr: "is not one of"},
+		{name: "segment name without counter", document: "hls:\n  segment_filename: \"segment.ts\"\n", wantErr: "does not match"},
+		{name: "segment name with directory", document: "hls:\n  segment_filename: \"../seg%d.ts\"\n", wantErr: "does not match"},
+		{name: "record as text", document: "record: \"yes\"\n", wantErr: "want a boolean"},
+		{name: "tags as a string", document: "tags: \"live\"\n", wantErr: "want an array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validateYAML(t, schema, tt.document)
+			if !slices.ContainsFunc(problems, func(problem string) bool { return strings.Contains(problem, tt.wantErr) }) {
+				t.Errorf("problems = %q, want one containing %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
+
+// yamlKeys returns the YAML keys of a struct's fields
+func yamlKeys(value any) []string {
+	var keys []string
+	typ := reflect.TypeOf(value)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestStreamInfoSchemaCoversModel(t *testing.T) {
+	schema := loadSchema(t)
+
+	schemaKeys := func(s *jsonSchema) []string {
+		var keys []string
+		for key := range s.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	if got, want := schemaKeys(schema), yamlKeys(StreamInfo{}); !slices.Equal(got, want) {
+		t.Errorf("schema properties = %v, StreamInfo has %v", got, want)
+	}
+	hls := schema.Properties["hls"]
+	if hls == nil {
+		t.Fatal("schema has no hls block")
+	}
+	if got, want := schemaKeys(hls), yamlKeys(HLSConfig{}); !slices.Equal(got, want) {
+		t.Errorf("schema hls properties = %v, HLSConfig has %v", got, want)
+	}
+
+	// Documented defaults must be what GetHLSConfig applies to an empty hls block
+	effective := (&Config{StreamInfo: &StreamInfo{}}).GetHLSConfig()
+	defaults := map[string]any{
+		"segment_time":   effective.SegmentTime,
+		"playlist_size":  effective.PlaylistSize,
+		"segment_type":   effective.SegmentType,
+		"dvr_window":     effective.DVRWindow,
+		"audio_tracks":   effective.AudioTrackCount(),
+		"playlist_check": effective.PlaylistCheck,
+	}
+	for key, want := range defaults {
+		got := hls.Properties[key].Default
+		if number, ok := got.(float64); ok && number == math.Trunc(number) {
+			got = int(number)
+		}
+		if got != want {
+			t.Errorf("schema default for hls.%s = %v, GetHLSConfig uses %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core/tools"
+)
+
+// Stream event signers
+const (
+	EventSignerConfig  = "config"  // Sign with the configured private key
+	EventSignerBrowser = "browser" // Hand events to the owner's browser extension (or other external signer)
+)
+
+// defaultSignTimeout is how long a stream event waits for the browser to sign it
+const defaultSignTimeout = 60 * time.Second
+
+// BrowserSigning reports whether stream events are signed by the owner's logged-in signer
+func (nc *NostrRelayConfig) BrowserSigning() bool {
+	return strings.EqualFold(strings.TrimSpace(nc.EventSigner), EventSignerBrowser)
+}
+
+// SignTimeoutDuration returns sign_timeout with its default applied
+func (nc *NostrRelayConfig) SignTimeoutDuration() time.Duration {
+	if nc.SignTimeout <= 0 {
+		return defaultSignTimeout
+	}
+	return time.Duration(nc.SignTimeout) * time.Second
+}
+
+// OwnerPublicKey returns the server owner's hex public key: derived from the private key, or,
+// with browser signing and no private key, taken from public_key. It returns "" if neither is set.
+func (nc *NostrRelayConfig) OwnerPublicKey() (string, error) {
+	privateKeyHex, err := nc.PrivateKeyHex()
+	if err != nil {
+		return "", err
+	}
+	if privateKeyHex != "" {
+		return tools.DerivePublicKey(privateKeyHex)
+	}
+	if !nc.BrowserSigning() {
+		return "", nil
+	}
+	return nc.configuredPublicKeyHex()
+}
@@ -0,0 +1,37 @@
+package config
+
+import "path/filepath"
+
+// MetadataFileName is the stream metadata file kept in the output directory and each archive
+const MetadataFileName = "metadata.json"
+
+// StorageConfig relocates stream data (live HLS output and recordings), e.g. onto a mounted
+// volume or a per-instance directory. Templates and static assets stay under www/.
+type StorageConfig struct {
+	DataDir    string `yaml:"data_dir"`    // Root for stream data (default "www")
+	OutputDir  string `yaml:"output_dir"`  // Live HLS output (default <data_dir>/live)
+	ArchiveDir string `yaml:"archive_dir"` // Recordings (default <output_dir>/archive)
+}
+
+// dataDir returns data_dir with its default applied
+func (s *StorageConfig) dataDir() string {
+	if s.DataDir == "" {
+		return "www"
+	}
+	return s.DataDir
+}
+
+// dirs resolves the output and archive directories, each defaulting from the one above it
+func (s *StorageConfig) dirs() (outputDir, archiveDir string) {
+	outputDir = s.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(s.dataDir(), "live")
+	}
+
+	archiveDir = s.ArchiveDir
+	if archiveDir == "" {
+		archiveDir = filepath.Join(outputDir, "archive")
+	}
+
+	return filepath.Clean(outputDir), filepath.Clean(archiveDir)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// hostnamePattern matches RFC 1123 host names
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+
+// ParsePort parses and validates a TCP port number
+func ParsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q (must be 1-65535)", value)
+	}
+	return port, nil
+}
+
+// ValidateHost checks that a bind/listen host is an IP address or a host name
+func ValidateHost(host string) error {
+	if net.ParseIP(host) == nil && !hostnamePattern.MatchString(host) {
+		return fmt.Errorf("invalid host %q", host)
+	}
+	return nil
+}
+
+// ValidateExternalURL checks that external_url is an absolute http(s) URL
+func ValidateExternalURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid external URL %q (expected http(s)://host[:port])", value)
+	}
+	return nil
+}
+
+// ValidateRelayURL checks that a relay URL is an absolute ws(s) URL
+func ValidateRelayURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "ws" && parsed.Scheme != "wss") || parsed.Host == "" {
+		return fmt.Errorf("invalid relay URL %q (expected wss://host)", value)
+	}
+	return nil
+}
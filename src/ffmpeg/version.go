@@ -0,0 +1,80 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinMajorVersion is the oldest FFmpeg release with the HLS options gnostream relies on
+// (fMP4 segments, -hls_fmp4_init_filename, RTMP -listen)
+const MinMajorVersion = 4
+
+// versionPattern extracts the release number from "ffmpeg version 6.1.1-..." / "ffprobe version n5.0 ..."
+var versionPattern = regexp.MustCompile(`version n?(\d+)\.(\d+)`)
+
+// Version runs "<binary> -version" and returns the first line of its output
+func Version(binary string) (string, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("%s not found: %w", binary, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "-version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s -version: %w", path, err)
+	}
+
+	firstLine, _, _ := strings.Cut(string(output), "\n")
+	return strings.TrimSpace(firstLine), nil
+}
+
+// ParseVersion extracts the major/minor release from a -version line. Git builds
+// ("version N-112345-g...") carry no release number and report ok=false.
+func ParseVersion(line string) (major, minor int, ok bool) {
+	match := versionPattern.FindStringSubmatch(line)
+	if match == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(match[1])
+	minor, _ = strconv.Atoi(match[2])
+	return major, minor, true
+}
+
+// Check verifies a binary is installed and recent enough, returning its release ("6.1", or "unknown" for git builds)
+func Check(binary string) (string, error) {
+	line, err := Version(binary)
+	if err != nil {
+		return "", err
+	}
+
+	major, minor, ok := ParseVersion(line)
+	if !ok {
+		return "unknown", nil
+	}
+	if major < MinMajorVersion {
+		return "", fmt.Errorf("%s %d.%d is too old (need %d.0 or newer)", binary, major, minor, MinMajorVersion)
+	}
+
+	return fmt.Sprintf("%d.%d", major, minor), nil
+}
+
+// InstallHint returns platform-specific instructions for installing FFmpeg
+func InstallHint() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "brew install ffmpeg"
+	case "windows":
+		return "download a build from https://ffmpeg.org/download.html and add its bin folder to PATH"
+	default:
+		return "sudo apt install ffmpeg (Debian/Ubuntu), sudo dnf install ffmpeg (Fedora) or use a static build from https://ffmpeg.org/download.html"
+	}
+}
@@ -0,0 +1,124 @@
+package hls
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segmentTags are tags that apply to the segment URI following them
+var segmentTags = []string{"#EXTINF:", "#EXT-X-BYTERANGE:", "#EXT-X-PROGRAM-DATE-TIME:", "#EXT-X-GAP"}
+
+// playlistSegment is a segment URI with the tag lines in front of it
+type playlistSegment struct {
+	lines         []string
+	uri           string
+	discontinuity bool
+}
+
+// CheckSegments looks for segments that aren't usable (ok returns false for their URI) and returns
+// their URIs along with a playlist players can load without them. Media sequence numbers follow
+// from position, so a broken segment can't be cut out of the middle: broken segments at the head
+// are dropped (advancing EXT-X-MEDIA-SEQUENCE) and the playlist ends before the next broken one,
+// which drops off the head in turn as the window slides on. A playlist without broken segments is
+// returned unchanged.
+func CheckSegments(data []byte, ok func(uri string) bool) ([]byte, []string) {
+	var header, pending []string
+	var segments []playlistSegment
+	discontinuity := false
+
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "#EXT-X-DISCONTINUITY":
+			discontinuity = true
+			pending = append(pending, line)
+		case isSegmentTag(trimmed):
+			pending = append(pending, line)
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			if segments == nil && pending == nil {
+				header = append(header, line)
+			} else if trimmed != "#EXT-X-ENDLIST" {
+				pending = append(pending, line)
+			}
+		default:
+			segments = append(segments, playlistSegment{lines: pending, uri: trimmed, discontinuity: discontinuity})
+			pending = nil
+			discontinuity = false
+		}
+	}
+
+	var broken []string
+	usable := make([]bool, len(segments))
+	for i, segment := range segments {
+		usable[i] = ok(segment.uri)
+		if !usable[i] {
+			broken = append(broken, segment.uri)
+		}
+	}
+	if len(broken) == 0 {
+		return data, nil
+	}
+
+	head, droppedDiscontinuities := 0, 0
+	for head < len(segments) && !usable[head] {
+		if segments[head].discontinuity {
+			droppedDiscontinuities++
+		}
+		head++
+	}
+	end := head
+	for end < len(segments) && usable[end] {
+		end++
+	}
+
+	var b strings.Builder
+	hasDiscontinuitySequence := false
+	for _, line := range header {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#EXT-X-MEDIA-SEQUENCE:"):
+			line = "#EXT-X-MEDIA-SEQUENCE:" + strconv.Itoa(tagNumber(trimmed)+head)
+		case strings.HasPrefix(trimmed, "#EXT-X-DISCONTINUITY-SEQUENCE:"):
+			line = "#EXT-X-DISCONTINUITY-SEQUENCE:" + strconv.Itoa(tagNumber(trimmed)+droppedDiscontinuities)
+			hasDiscontinuitySequence = true
+		}
+		b.WriteString(line + "\n")
+	}
+	if head > 0 && !strings.Contains(b.String(), "#EXT-X-MEDIA-SEQUENCE:") {
+		fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", head)
+	}
+	if droppedDiscontinuities > 0 && !hasDiscontinuitySequence {
+		fmt.Fprintf(&b, "#EXT-X-DISCONTINUITY-SEQUENCE:%d\n", droppedDiscontinuities)
+	}
+
+	for _, segment := range segments[head:end] {
+		for _, line := range segment.lines {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString(segment.uri + "\n")
+	}
+	// A finished playlist stays finished only if nothing at its end was cut
+	if end == len(segments) && strings.Contains(string(data), "#EXT-X-ENDLIST") {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+
+	return []byte(b.String()), broken
+}
+
+// isSegmentTag reports whether a tag line applies to the next segment
+func isSegmentTag(line string) bool {
+	for _, tag := range segmentTags {
+		if strings.HasPrefix(line, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagNumber returns the integer value of a "#TAG:<n>" line (0 if it has none)
+func tagNumber(line string) int {
+	_, value, _ := strings.Cut(line, ":")
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return n
+}
@@ -0,0 +1,82 @@
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loopWindow is how many segments a looped playlist lists at once
+const loopWindow = 3
+
+// Segment is one media segment of a playlist
+type Segment struct {
+	Duration float64
+	URI      string
+}
+
+// ParseSegments returns the segments of a media playlist in order
+func ParseSegments(data []byte) []Segment {
+	var segments []Segment
+	duration := -1.0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			if d, err := strconv.ParseFloat(value, 64); err == nil {
+				duration = d
+			}
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			if duration >= 0 {
+				segments = append(segments, Segment{Duration: duration, URI: line})
+			}
+			duration = -1
+		}
+	}
+	return segments
+}
+
+// LoopPlaylist turns a short VOD playlist into an endless live playlist: the media sequence follows
+// the wall clock, the segments repeat in order, and each wrap back to the first segment is marked as
+// a discontinuity. Segment URIs are prefixed with baseURL.
+func LoopPlaylist(segments []Segment, baseURL string, now time.Time) []byte {
+	if len(segments) == 0 {
+		return nil
+	}
+
+	var total, longest float64
+	for _, segment := range segments {
+		total += segment.Duration
+		longest = max(longest, segment.Duration)
+	}
+	step := max(1, int64(math.Round(total/float64(len(segments)))))
+
+	count := int64(len(segments))
+	first := now.Unix() / step
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(longest)))
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", first)
+	fmt.Fprintf(&b, "#EXT-X-DISCONTINUITY-SEQUENCE:%d\n", first/count)
+
+	for sequence := first; sequence < first+loopWindow; sequence++ {
+		if sequence%count == 0 && sequence != first {
+			b.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+		segment := segments[sequence%count]
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n", segment.Duration)
+		b.WriteString(baseURL + segment.URI + "\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,46 @@
+// Package hls contains helpers for reading the HLS playlists FFmpeg produces
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseLatestSegmentSequence returns the media sequence number of the newest segment in an HLS playlist.
+// The second return value is false if the playlist contains no segments.
+func ParseLatestSegmentSequence(data []byte) (int, bool) {
+	mediaSequence := 0
+	segments := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+			if seq, err := strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-MEDIA-SEQUENCE:")); err == nil {
+				mediaSequence = seq
+			}
+		case strings.HasPrefix(line, "#EXTINF:"):
+			segments++
+		}
+	}
+
+	if segments == 0 {
+		return 0, false
+	}
+
+	return mediaSequence + segments - 1, true
+}
+
+// ReadLatestSegmentSequence reads a playlist from disk and returns its newest segment sequence number
+func ReadLatestSegmentSequence(playlistPath string) (int, bool) {
+	data, err := os.ReadFile(playlistPath)
+	if err != nil {
+		return 0, false
+	}
+	return ParseLatestSegmentSequence(data)
+}
@@ -0,0 +1,125 @@
+package hls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLatestSegmentSequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		playlist string
+		want     int
+		wantOK   bool
+	}{
+		{
+			name: "media sequence offset",
+			playlist: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:2
+#EXT-X-MEDIA-SEQUENCE:40
+#EXTINF:2.000000,
+segment040.ts
+#EXTINF:2.000000,
+segment041.ts
+#EXTINF:2.000000,
+segment042.ts
+`,
+			want:   42,
+			wantOK: true,
+		},
+		{
+			name: "no segments",
+			playlist: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:2
+#EXT-X-MEDIA-SEQUENCE:7
+`,
+			wantOK: false,
+		},
+		{
+			name: "sliding window advanced",
+			playlist: `#EXTM3U
+#EXT-X-TARGETDURATION:2
+#EXT-X-MEDIA-SEQUENCE:41
+#EXTINF:2.000000,
+segment041.ts
+#EXTINF:2.000000,
+segment042.ts
+#EXTINF:2.000000,
+segment043.ts
+`,
+			want:   43,
+			wantOK: true,
+		},
+		{
+			name: "missing media sequence",
+			playlist: `#EXTM3U
+#EXT-X-TARGETDURATION:2
+#EXTINF:2.000000,
+segment000.ts
+#EXTINF:2.000000,
+segment001.ts
+`,
+			want:   1,
+			wantOK: true,
+		},
+		{
+			name: "invalid media sequence",
+			playlist: `#EXTM3U
+#EXT-X-MEDIA-SEQUENCE:abc
+#EXTINF:2.000000,
+segment000.ts
+`,
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:     "CRLF line endings",
+			playlist: "#EXTM3U\r\n#EXT-X-MEDIA-SEQUENCE:5\r\n#EXTINF:2.0,\r\nsegment005.ts\r\n#EXTINF:2.0,\r\nsegment006.ts\r\n",
+			want:     6,
+			wantOK:   true,
+		},
+		{
+			name:     "garbage",
+			playlist: "not a playlist\x00\xff\n<html></html>",
+			wantOK:   false,
+		},
+		{
+			name:   "empty",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseLatestSegmentSequence([]byte(tt.playlist))
+			if ok != tt.wantOK {
+				t.Fatalf("ParseLatestSegmentSequence() ok = %t, want %t", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("ParseLatestSegmentSequence() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLatestSegmentSequence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.m3u8")
+
+	if _, ok := ReadLatestSegmentSequence(path); ok {
+		t.Error("ReadLatestSegmentSequence reported a segment for a missing playlist")
+	}
+
+	playlist := "#EXTM3U\n#EXT-X-MEDIA-SEQUENCE:12\n#EXTINF:2.0,\nsegment012.ts\n#EXTINF:2.0,\nsegment013.ts\n"
+	if err := os.WriteFile(path, []byte(playlist), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := ReadLatestSegmentSequence(path)
+	if !ok || got != 13 {
+		t.Errorf("ReadLatestSegmentSequence() = %d, %t, want 13, true", got, ok)
+	}
+}
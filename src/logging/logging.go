@@ -0,0 +1,123 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level is shared by all handlers so it can be changed at runtime
+var level = new(slog.LevelVar)
+
+// logger is the process-wide leveled logger (console output until Setup is called)
+var logger = slog.New(&consoleHandler{})
+
+// Setup configures the log level ("debug", "info", "warn", "error") and format ("text" or "json")
+func Setup(levelName, format string) error {
+	parsed, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+
+	switch strings.ToLower(format) {
+	case "", "text", "console":
+		logger = slog.New(&consoleHandler{})
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	default:
+		return fmt.Errorf("unknown log format %q (use text or json)", format)
+	}
+
+	return nil
+}
+
+// ParseLevel converts a level name into a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+	}
+}
+
+// Debugf logs a formatted message at debug level
+func Debugf(format string, args ...interface{}) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof logs a formatted message at info level
+func Infof(format string, args ...interface{}) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a formatted message at warn level
+func Warnf(format string, args ...interface{}) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a formatted message at error level
+func Errorf(format string, args ...interface{}) {
+	logf(slog.LevelError, format, args...)
+}
+
+func logf(lvl slog.Level, format string, args ...interface{}) {
+	if !logger.Enabled(context.Background(), lvl) {
+		return
+	}
+	logger.Log(context.Background(), lvl, fmt.Sprintf(format, args...))
+}
+
+// consoleHandler writes human-friendly lines through the standard logger, matching the existing output
+type consoleHandler struct {
+	attrs []slog.Attr
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+
+	// Only tag levels that need attention; info stays exactly as before
+	switch {
+	case record.Level >= slog.LevelError:
+		b.WriteString("ERROR ")
+	case record.Level >= slog.LevelWarn:
+		b.WriteString("WARN ")
+	case record.Level < slog.LevelInfo:
+		b.WriteString("DEBUG ")
+	}
+	b.WriteString(record.Message)
+
+	writeAttr := func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	record.Attrs(writeAttr)
+
+	log.Print(b.String())
+	return nil
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &consoleHandler{attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *consoleHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
@@ -0,0 +1,94 @@
+package nip19
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Test vectors from NIP-19
+const (
+	vectorNsec       = "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5"
+	vectorPrivateHex = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+	vectorNpub       = "npub180cvv07tjdrrgpa0j7j7tmnyl2yr6yr7l8j4s3evf6u64th6gkwsyjh6w6"
+	vectorPublicHex  = "3bf0c63fcb93463407af97a5e5ee64fa883d107ef9e558472c4eb9aaaefa459d"
+)
+
+func TestDecodeNsec(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "vector", input: vectorNsec, want: vectorPrivateHex},
+		{name: "uppercase", input: strings.ToUpper(vectorNsec), want: vectorPrivateHex},
+		{name: "surrounding whitespace", input: " " + vectorNsec + "\n", want: vectorPrivateHex},
+		{name: "bad checksum", input: vectorNsec[:len(vectorNsec)-1] + "6", wantErr: "checksum"},
+		{name: "changed data", input: strings.Replace(vectorNsec, "vl029", "vl028", 1), wantErr: "checksum"},
+		{name: "mixed case", input: "nsec1" + strings.ToUpper(vectorNsec[5:8]) + vectorNsec[8:], wantErr: "mixed case"},
+		{name: "npub instead", input: vectorNpub, wantErr: "expected nsec1 prefix"},
+		{name: "invalid character", input: vectorNsec[:10] + "b" + vectorNsec[11:], wantErr: "invalid character"},
+		{name: "empty", input: "", wantErr: "expected nsec1 prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeNsec(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("DecodeNsec(%q) = %q, %v; want an error containing %q", tt.input, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("DecodeNsec(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeNpub(t *testing.T) {
+	got, err := DecodeNpub(vectorNpub)
+	if err != nil || got != vectorPublicHex {
+		t.Errorf("DecodeNpub = %q, %v; want %q", got, err, vectorPublicHex)
+	}
+	if _, err := DecodeNpub(vectorNsec); err == nil {
+		t.Error("DecodeNpub accepted an nsec")
+	}
+}
+
+func TestDecodeKeyRejectsWrongLength(t *testing.T) {
+	short, err := Encode("nsec", bytes.Repeat([]byte{1}, 31))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, err := DecodeNsec(short); err == nil || !strings.Contains(err.Error(), "expected 32 bytes") {
+		t.Errorf("DecodeNsec(31-byte key) error = %v, want a length error", err)
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	// Longer than the 90 characters btcutil's own decoder accepts, like naddr with relay hints
+	data := []byte(strings.Repeat("wss://relay.example.com/", 8))
+
+	encoded, err := Encode("naddr", data)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if len(encoded) <= 90 {
+		t.Fatalf("encoded length %d, want over 90 for this test", len(encoded))
+	}
+
+	decoded, err := Decode("naddr", encoded)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("round trip = %q, want %q", decoded, data)
+	}
+
+	if _, err := Decode("nevent", encoded); err == nil {
+		t.Error("Decode accepted a different prefix")
+	}
+}
@@ -0,0 +1,150 @@
+package nostr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostr "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/logging"
+)
+
+// ownerPresence is how recently the owner's browser must have polled for pending events to be
+// trusted to sign the next one; past that, events go straight to the configured key
+const ownerPresence = 15 * time.Second
+
+// Browser signing errors
+var (
+	ErrNoPendingEvent   = errors.New("no pending event with that ID")
+	ErrInvalidSignature = errors.New("event signature is invalid")
+	ErrWrongSigner      = errors.New("event is not signed by the server owner")
+)
+
+// pendingSignature is a stream event waiting for the owner's browser to sign it
+type pendingSignature struct {
+	event  *nostr.Event
+	signed chan *nostr.Event
+}
+
+// browserSigning tracks stream events handed to the owner's browser (nostr.event_signer: browser)
+type browserSigning struct {
+	mu       sync.Mutex
+	pending  map[string]*pendingSignature // By unsigned event ID
+	lastPoll time.Time
+}
+
+// signStreamEvent signs an event with the owner's key: stream start/update/end, planned and
+// cancel events, deletion requests, the relay and mute lists. With browser signing it is handed
+// to the owner's browser when one is polling, falling back to the configured key (if any) when
+// no browser is connected or it doesn't sign within sign_timeout.
+func (gc *GrainClient) signStreamEvent(event *nostr.Event) error {
+	if !gc.config.BrowserSigning() {
+		return gc.signer.Sign(event)
+	}
+
+	if gc.ownerBrowserPresent() {
+		err := gc.requestBrowserSignature(event)
+		if err == nil {
+			return nil
+		}
+		logging.Warnf("⚠️ Kind %d event was not signed in the browser: %v", event.Kind, err)
+	} else {
+		logging.Warnf("⚠️ No owner browser is connected to sign kind %d event", event.Kind)
+	}
+
+	if gc.localSigner == nil {
+		return fmt.Errorf("no browser signer connected and no private key configured")
+	}
+	logging.Infof("🔑 Signing kind %d event with the configured key instead", event.Kind)
+	return gc.localSigner.Sign(event)
+}
+
+// ownerBrowserPresent reports whether the owner's browser polled for pending events recently
+func (gc *GrainClient) ownerBrowserPresent() bool {
+	gc.browser.mu.Lock()
+	defer gc.browser.mu.Unlock()
+	return time.Since(gc.browser.lastPoll) <= ownerPresence
+}
+
+// requestBrowserSignature queues the event for the owner's browser and waits for the signed copy,
+// which replaces the event's contents
+func (gc *GrainClient) requestBrowserSignature(event *nostr.Event) error {
+	event.PubKey = gc.publicKey
+	event.Sig = ""
+	eventID, err := core.ComputeEventID(event)
+	if err != nil {
+		return fmt.Errorf("failed to compute event ID: %w", err)
+	}
+	event.ID = eventID
+
+	pending := &pendingSignature{event: event, signed: make(chan *nostr.Event, 1)}
+	gc.browser.mu.Lock()
+	if gc.browser.pending == nil {
+		gc.browser.pending = make(map[string]*pendingSignature)
+	}
+	gc.browser.pending[eventID] = pending
+	gc.browser.mu.Unlock()
+
+	defer func() {
+		gc.browser.mu.Lock()
+		delete(gc.browser.pending, eventID)
+		gc.browser.mu.Unlock()
+	}()
+
+	timeout := gc.config.SignTimeoutDuration()
+	logging.Infof("✍️ Waiting up to %v for the owner's browser to sign kind %d event %s", timeout, event.Kind, eventID[:16]+"...")
+
+	select {
+	case signed := <-pending.signed:
+		*event = *signed
+		logging.Infof("✍️ Kind %d event signed in the browser", event.Kind)
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+}
+
+// PendingSignatures returns the stream events waiting for the owner's browser to sign them.
+// Calling it marks the owner's browser as connected.
+func (gc *GrainClient) PendingSignatures() []*nostr.Event {
+	gc.browser.mu.Lock()
+	defer gc.browser.mu.Unlock()
+
+	gc.browser.lastPoll = time.Now()
+	events := make([]*nostr.Event, 0, len(gc.browser.pending))
+	for _, pending := range gc.browser.pending {
+		unsigned := *pending.event
+		events = append(events, &unsigned)
+	}
+	return events
+}
+
+// SubmitSignature completes a pending stream event with the browser's signed copy. The event
+// must match a pending one exactly (same ID) and carry a valid signature by the server owner.
+func (gc *GrainClient) SubmitSignature(event *nostr.Event) error {
+	if event == nil {
+		return ErrNoPendingEvent
+	}
+	if event.PubKey != gc.publicKey {
+		return ErrWrongSigner
+	}
+	if !core.VerifyEventSignature(event) {
+		return ErrInvalidSignature
+	}
+
+	gc.browser.mu.Lock()
+	pending, ok := gc.browser.pending[event.ID]
+	if ok {
+		delete(gc.browser.pending, event.ID)
+	}
+	gc.browser.mu.Unlock()
+	if !ok {
+		return ErrNoPendingEvent
+	}
+
+	pending.signed <- event
+	return nil
+}
@@ -0,0 +1,117 @@
+package nostr
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/0ceanslim/grain/client/session"
+	nostr "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/config"
+)
+
+// testOwnerKey is the NIP-19 test vector private key
+const testOwnerKey = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+
+// newBrowserSigningClient returns a dry-run client with nostr.event_signer: browser and,
+// optionally, the configured key as the fallback
+func newBrowserSigningClient(t *testing.T, withKey bool) (*GrainClient, *LocalSigner) {
+	t.Helper()
+	owner, err := NewLocalSigner(testOwnerKey)
+	if err != nil {
+		t.Fatalf("NewLocalSigner: %v", err)
+	}
+
+	gc := &GrainClient{
+		config: &config.NostrRelayConfig{
+			EventSigner: config.EventSignerBrowser,
+			SignTimeout: 1,
+			DryRun:      true,
+		},
+		signer:    NewExternalSigner(owner.PublicKey(), session.BrowserExtension),
+		publicKey: owner.PublicKey(),
+		isEnabled: true,
+	}
+	if withKey {
+		gc.signer = owner
+		gc.localSigner = owner
+	}
+	return gc, owner
+}
+
+// signInBrowser plays the owner's browser: it polls for one pending event and signs it
+func signInBrowser(t *testing.T, gc *GrainClient, owner *LocalSigner) {
+	t.Helper()
+	gc.PendingSignatures() // The browser is connected
+	go func() {
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			for _, event := range gc.PendingSignatures() {
+				if err := owner.Sign(event); err != nil {
+					t.Errorf("Sign: %v", err)
+					return
+				}
+				if err := gc.SubmitSignature(event); err != nil {
+					t.Errorf("SubmitSignature: %v", err)
+				}
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+}
+
+func TestOwnerEventsUseBrowserSigning(t *testing.T) {
+	metadata := &config.StreamMetadata{Dtag: "planned-dtag", Title: "Planned", Starts: "1700000000"}
+	eventID := "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9"
+
+	tests := []struct {
+		name    string
+		kind    int
+		publish func(gc *GrainClient) string
+	}{
+		{name: "planned event", kind: 30311, publish: func(gc *GrainClient) string {
+			eventJSON, _ := gc.BroadcastPlannedEventWithResponse(metadata)
+			return eventJSON
+		}},
+		{name: "deletion request", kind: 5, publish: func(gc *GrainClient) string {
+			eventJSON, _ := gc.BroadcastDeletionEventWithResponse(eventID, "test")
+			return eventJSON
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" without browser or key", func(t *testing.T) {
+			gc, _ := newBrowserSigningClient(t, false)
+			if eventJSON := tt.publish(gc); eventJSON != "" {
+				t.Errorf("published %s with no signer available", eventJSON)
+			}
+		})
+
+		t.Run(tt.name+" signed in browser", func(t *testing.T) {
+			gc, owner := newBrowserSigningClient(t, false)
+			signInBrowser(t, gc, owner)
+
+			eventJSON := tt.publish(gc)
+			if eventJSON == "" {
+				t.Fatal("event was not published")
+			}
+			var event nostr.Event
+			if err := json.Unmarshal([]byte(eventJSON), &event); err != nil {
+				t.Fatalf("unmarshal event: %v", err)
+			}
+			if event.Kind != tt.kind || event.PubKey != owner.PublicKey() || event.Sig == "" {
+				t.Errorf("event kind %d, pubkey %s, sig %q - want a kind %d event signed by the owner",
+					event.Kind, event.PubKey, event.Sig, tt.kind)
+			}
+		})
+
+		t.Run(tt.name+" falls back to the configured key", func(t *testing.T) {
+			gc, _ := newBrowserSigningClient(t, true)
+			if eventJSON := tt.publish(gc); eventJSON == "" {
+				t.Error("event was not signed with the configured key")
+			}
+		})
+	}
+}
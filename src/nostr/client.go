@@ -1,19 +1,19 @@
 package nostr
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/0ceanslim/grain/client/core"
-	"github.com/0ceanslim/grain/client/core/tools"
 	"github.com/0ceanslim/grain/client/session"
 	nostr "github.com/0ceanslim/grain/server/types"
 
 	"gnostream/src/config"
+	"gnostream/src/logging"
+	"gnostream/src/nip19"
 )
 
 // Event represents a Nostr event
@@ -35,26 +35,56 @@ type Client interface {
 	BroadcastUpdateEventWithResponse(metadata *config.StreamMetadata) (string, []string)
 	BroadcastEndEvent(metadata *config.StreamMetadata)
 	BroadcastEndEventWithResponse(metadata *config.StreamMetadata) (string, []string)
+	BroadcastPlannedEventWithResponse(metadata *config.StreamMetadata) (string, []string)
 	BroadcastCancelEvent(dtag string)
 	BroadcastDeletionEvent(eventID string, reason string)
 	BroadcastDeletionEventWithResponse(eventID string, reason string) (string, []string)
+	BroadcastRelayListEventWithResponse() (string, []string)
+	PublishTestEvent() (string, []RelayTestResult, error)
+	GetMuteList() ([]string, error)
+	UpdateMuteList(pubkey string, mute bool) (*MuteListResult, error)
 	Subscribe(filters []nostr.Filter, relayHints []string) (*core.Subscription, error)
 	GetUserProfile(pubkey string, relayHints []string) (*nostr.Event, error)
 	IsEnabled() bool
 	GetConnectedRelays() []string
+	OnRelaysReconnected(listener func(relays []string))
+	PendingSignatures() []*nostr.Event
+	SubmitSignature(event *nostr.Event) error
 	Close() error
 }
 
 // GrainClient wraps Grain's Nostr client with gnostream-specific functionality
 type GrainClient struct {
 	client      *core.Client
-	signer      *core.EventSigner
+	signer      Signer
+	localSigner Signer // The configured key, nil when browser signing runs without one
 	userSession *session.UserSession
 	config      *config.NostrRelayConfig
 	publicKey   string
 	isEnabled   bool
+
+	// Relay health watchdog
+	reconnectListeners []func(relays []string)
+	listenersMux       sync.Mutex
+	stopWatchdog       chan struct{}
+	closeOnce          sync.Once
+
+	// Serializes mute list read-modify-publish cycles
+	muteListMux sync.Mutex
+
+	// Stream events waiting for the owner's browser to sign them
+	browser browserSigning
 }
 
+// relayHealthInterval is how often the watchdog checks for dropped relays
+const relayHealthInterval = 30 * time.Second
+
+// slowReconnectThreshold is the reconnect time above which publishing is reported as delayed
+const slowReconnectThreshold = time.Second
+
+// categoryLabelNamespace is the NIP-32 label namespace for the stream category
+const categoryLabelNamespace = "category"
+
 // NewClient creates a new Nostr client (uses Grain implementation)
 func NewClient(cfg *config.NostrRelayConfig) (Client, error) {
 	return NewGrainClient(cfg)
@@ -62,55 +92,65 @@ func NewClient(cfg *config.NostrRelayConfig) (Client, error) {
 
 // NewGrainClient creates a new Grain-based Nostr client
 func NewGrainClient(cfg *config.NostrRelayConfig) (*GrainClient, error) {
-	// Check for placeholder values
-	if cfg.PrivateKey == "your-nostr-private-key-nsec" || cfg.PrivateKey == "" {
-		log.Println("⚠️ Nostr keys not configured, running in disabled mode")
+	// Resolve the key from the environment, key file or config (placeholder counts as unset)
+	privateKeyHex, err := cfg.PrivateKeyHex()
+	if err != nil {
+		return nil, err
+	}
+	ownerPublicKey, err := cfg.OwnerPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if ownerPublicKey == "" {
+		logging.Warnf("⚠️ Nostr keys not configured, running in disabled mode")
 		return &GrainClient{
 			config:    cfg,
 			isEnabled: false,
 		}, nil
 	}
 
-	log.Println("🔑 Initializing Grain Nostr client...")
+	logging.Infof("🔑 Initializing Grain Nostr client...")
 
 	// Create Grain client with configuration
+	connection := cfg.Connection.Defaults()
 	grainConfig := &core.Config{
-		DefaultRelays:     cfg.Relays,
-		ConnectionTimeout: 15 * time.Second,
-		ReadTimeout:       45 * time.Second,
-		WriteTimeout:      15 * time.Second,
-		MaxConnections:    20,
-		RetryAttempts:     3,
-		RetryDelay:        2 * time.Second,
+		DefaultRelays:     cfg.AllRelays(),
+		ConnectionTimeout: connection.ConnectTimeout,
+		ReadTimeout:       connection.ReadTimeout,
+		WriteTimeout:      connection.WriteTimeout,
+		MaxConnections:    connection.MaxConnections,
+		RetryAttempts:     connection.RetryAttempts,
+		RetryDelay:        connection.RetryDelay,
 		UserAgent:         "gnostream/1.0",
 	}
 
 	client := core.NewClient(grainConfig)
 
-	// Connect to relays
-	if err := client.ConnectToRelaysWithRetry(cfg.Relays, 3); err != nil {
-		log.Printf("⚠️ Some relays failed to connect: %v", err)
+	// Connect to relays (write and read subsets included)
+	allRelays := cfg.AllRelays()
+	if err := client.ConnectToRelaysWithRetry(allRelays, connection.RetryAttempts); err != nil {
+		logging.Warnf("⚠️ Some relays failed to connect: %v", err)
 	}
 
 	connectedCount := len(client.GetConnectedRelays())
-	log.Printf("🌐 Connected to %d/%d Nostr relays", connectedCount, len(cfg.Relays))
-
-	// Decode private key
-	privateKeyHex, err := DecodeNsec(cfg.PrivateKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode nsec: %w", err)
-	}
-
-	// Create signer
-	signer, err := core.NewEventSigner(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create signer: %w", err)
-	}
-
-	// Derive public key
-	publicKey, err := tools.DerivePublicKey(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive public key: %w", err)
+	logging.Infof("🌐 Connected to %d/%d Nostr relays", connectedCount, len(allRelays))
+	if len(cfg.WriteRelays) > 0 || len(cfg.ReadRelays) > 0 {
+		logging.Infof("🌐 Publishing to %d write relays, reading from %d read relays (others are fallbacks)",
+			len(cfg.WriteRelayList()), len(cfg.ReadRelayList()))
+	}
+
+	// Create signer; without a private key only the owner's browser can sign (stream events)
+	publicKey := ownerPublicKey
+	var signer, localSigner Signer
+	if privateKeyHex != "" {
+		localSigner, err = NewLocalSigner(privateKeyHex)
+		if err != nil {
+			return nil, err
+		}
+		signer = localSigner
+	} else {
+		signer = NewExternalSigner(publicKey, session.BrowserExtension)
+		logging.Warnf("⚠️ No Nostr private key - only stream events signed in the owner's browser are published")
 	}
 
 	// Create user session
@@ -119,44 +159,213 @@ func NewGrainClient(cfg *config.NostrRelayConfig) (*GrainClient, error) {
 		LastActive:      time.Now(),
 		Mode:            session.WriteMode,
 		SigningMethod:   session.BrowserExtension, // We'll update this when we find the right constant
-		ConnectedRelays: cfg.Relays,
+		ConnectedRelays: allRelays,
 	}
 
 	// Update config with derived public key
 	cfg.PublicKey = publicKey
 
-	log.Printf("🔑 Grain client initialized successfully")
-	log.Printf("🔑 Public key: %s", publicKey)
+	logging.Infof("🔑 Grain client initialized successfully")
+	logging.Infof("🔑 Public key: %s", publicKey)
+	if cfg.DryRun {
+		logging.Warnf("🧪 Nostr dry run enabled - events are logged, not published")
+	}
+
+	gc := &GrainClient{
+		client:       client,
+		signer:       signer,
+		localSigner:  localSigner,
+		userSession:  userSession,
+		config:       cfg,
+		publicKey:    publicKey,
+		isEnabled:    true,
+		stopWatchdog: make(chan struct{}),
+	}
+
+	go gc.watchRelays()
+
+	return gc, nil
+}
+
+// OnRelaysReconnected registers a callback run after dropped relays come back, so callers
+// can re-establish subscriptions that died with the connection
+func (gc *GrainClient) OnRelaysReconnected(listener func(relays []string)) {
+	gc.listenersMux.Lock()
+	defer gc.listenersMux.Unlock()
+	gc.reconnectListeners = append(gc.reconnectListeners, listener)
+}
+
+// watchRelays periodically reconnects configured relays that have dropped
+func (gc *GrainClient) watchRelays() {
+	ticker := time.NewTicker(relayHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gc.stopWatchdog:
+			return
+		case <-ticker.C:
+			gc.reconnectDroppedRelays()
+		}
+	}
+}
+
+// reconnectDroppedRelays reconnects missing relays and notifies listeners of any that came back
+func (gc *GrainClient) reconnectDroppedRelays() {
+	connected := make(map[string]bool)
+	for _, relay := range gc.client.GetConnectedRelays() {
+		connected[relay] = true
+	}
+
+	var dropped []string
+	for _, relay := range gc.config.AllRelays() {
+		if !connected[relay] {
+			dropped = append(dropped, relay)
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	logging.Warnf("🔌 %d relay(s) disconnected - reconnecting: %v", len(dropped), dropped)
+	if err := gc.client.ConnectToRelaysWithRetry(dropped, 2); err != nil {
+		logging.Warnf("⚠️ Some relays failed to reconnect: %v", err)
+	}
+
+	connected = make(map[string]bool)
+	for _, relay := range gc.client.GetConnectedRelays() {
+		connected[relay] = true
+	}
+
+	var restored []string
+	for _, relay := range dropped {
+		if connected[relay] {
+			restored = append(restored, relay)
+		}
+	}
+	if len(restored) == 0 {
+		return
+	}
+
+	logging.Infof("🔌 Reconnected to %d relay(s): %v", len(restored), restored)
+
+	gc.listenersMux.Lock()
+	listeners := append([]func(relays []string){}, gc.reconnectListeners...)
+	gc.listenersMux.Unlock()
 
-	return &GrainClient{
-		client:      client,
-		signer:      signer,
-		userSession: userSession,
-		config:      cfg,
-		publicKey:   publicKey,
-		isEnabled:   true,
-	}, nil
+	for _, listener := range listeners {
+		listener(restored)
+	}
 }
 
-// ensureConnections ensures all relays are connected before publishing
+// ensureConnections runs the configured reconnect check before publishing and logs how long it took
 func (gc *GrainClient) ensureConnections() {
-	if err := gc.client.ConnectToRelaysWithRetry(gc.config.Relays, 3); err != nil {
-		log.Printf("⚠️ Some relays failed to reconnect: %v", err)
+	start := time.Now()
+
+	switch gc.config.ReconnectBeforePublish {
+	case config.ReconnectOff:
+		return
+	case config.ReconnectAll:
+		if err := gc.client.ConnectToRelaysWithRetry(gc.config.AllRelays(), gc.config.Connection.Defaults().RetryAttempts); err != nil {
+			logging.Warnf("⚠️ Some relays failed to reconnect: %v", err)
+		}
+	default:
+		gc.reconnectDroppedRelays()
+	}
+
+	elapsed := time.Since(start)
+	if elapsed > slowReconnectThreshold {
+		logging.Warnf("🐢 Relay reconnect before publish took %v", elapsed.Round(time.Millisecond))
+	} else {
+		logging.Debugf("🔌 Relay reconnect check took %v", elapsed.Round(time.Millisecond))
+	}
+}
+
+// RepublishEvent re-sends an already signed event to the write relays and returns per-relay results
+func (gc *GrainClient) RepublishEvent(event *nostr.Event) ([]core.BroadcastResult, error) {
+	if !gc.isEnabled {
+		return nil, fmt.Errorf("nostr client not enabled")
+	}
+
+	return gc.PublishEvent(event, nil)
+}
+
+// PublishEvent sends a signed event to relays. With nil relays it goes to the connected write
+// relays, and on to the other configured relays only if none of those accepted it. In dry-run
+// mode the event is only logged and no relay results are returned.
+func (gc *GrainClient) PublishEvent(event *nostr.Event, relays []string) ([]core.BroadcastResult, error) {
+	if gc.config.DryRun {
+		eventJSON, _ := json.Marshal(event)
+		logging.Infof("🧪 [dry run] Not publishing kind %d event: %s", event.Kind, eventJSON)
+		return []core.BroadcastResult{}, nil
+	}
+
+	gc.ensureConnections()
+
+	if relays != nil {
+		return gc.client.PublishEvent(event, relays)
+	}
+
+	primary, fallback := gc.connectedSubset(gc.config.WriteRelayList())
+	results, err := gc.client.PublishEvent(event, primary)
+	if len(fallback) == 0 || (err == nil && core.SummarizeBroadcast(results).Successful > 0) {
+		return results, err
 	}
+
+	logging.Warnf("⚠️ No write relay accepted kind %d event - falling back to %d other relays", event.Kind, len(fallback))
+	fallbackResults, fallbackErr := gc.client.PublishEvent(event, fallback)
+	if fallbackErr != nil {
+		return results, err
+	}
+	return append(results, fallbackResults...), nil
+}
+
+// connectedSubset splits the connected configured relays into those in preferred and the rest.
+// If none of preferred is connected, the rest become the preferred set.
+func (gc *GrainClient) connectedSubset(preferred []string) (primary, fallback []string) {
+	connected := make(map[string]bool)
+	for _, relay := range gc.client.GetConnectedRelays() {
+		connected[relay] = true
+	}
+
+	inPreferred := make(map[string]bool, len(preferred))
+	for _, relay := range preferred {
+		inPreferred[relay] = true
+		if connected[relay] {
+			primary = append(primary, relay)
+		}
+	}
+	for _, relay := range gc.config.AllRelays() {
+		if !inPreferred[relay] && connected[relay] {
+			fallback = append(fallback, relay)
+		}
+	}
+
+	if len(primary) == 0 {
+		return fallback, nil
+	}
+	return primary, fallback
 }
 
-// Helper method to build streaming event
-func (gc *GrainClient) buildStreamingEvent(metadata *config.StreamMetadata, status string) *nostr.Event {
+// buildStreamingEvent builds an unsigned NIP-53 live event (kind 30311). It depends only on its
+// inputs, so tag output can be checked without a client: "recording" and "image" appear only when
+// set, "ends" only once the stream is no longer live, and each hashtag becomes a "t" tag. A
+// category adds its own "t" tag plus a NIP-32 "category" label.
+func buildStreamingEvent(metadata *config.StreamMetadata, status string) *nostr.Event {
 	eventBuilder := core.NewEventBuilder(30311).
 		Content("").
 		DTag(metadata.Dtag).
 		Tag("title", metadata.Title).
 		Tag("summary", metadata.Summary).
 		Tag("streaming", metadata.StreamURL).
-		Tag("recording", metadata.RecordingURL).
 		Tag("starts", metadata.Starts).
 		Tag("status", status)
 
+	// Recording URL is only set once the archive exists
+	if metadata.RecordingURL != "" {
+		eventBuilder = eventBuilder.Tag("recording", metadata.RecordingURL)
+	}
+
 	if metadata.Image != "" {
 		eventBuilder = eventBuilder.Tag("image", metadata.Image)
 	}
@@ -166,13 +375,31 @@ func (gc *GrainClient) buildStreamingEvent(metadata *config.StreamMetadata, stat
 	}
 
 	// Add hashtags
+	categoryTagged := false
 	for _, tag := range metadata.Tags {
 		eventBuilder = eventBuilder.TTag(tag)
+		if strings.EqualFold(tag, metadata.Category) {
+			categoryTagged = true
+		}
+	}
+
+	if metadata.Category != "" {
+		if !categoryTagged {
+			eventBuilder = eventBuilder.TTag(metadata.Category)
+		}
+		eventBuilder = eventBuilder.
+			Tag("L", categoryLabelNamespace).
+			Tag("l", metadata.Category, categoryLabelNamespace)
 	}
 
 	return eventBuilder.Build()
 }
 
+// GetSigner returns the signer used for server-side broadcasts
+func (gc *GrainClient) GetSigner() Signer {
+	return gc.signer
+}
+
 // GetUserSession returns the current user session
 func (gc *GrainClient) GetUserSession() *session.UserSession {
 	return gc.userSession
@@ -199,51 +426,47 @@ func (gc *GrainClient) GetConnectedRelays() []string {
 // BroadcastStartEvent broadcasts a stream start event using Grain
 func (gc *GrainClient) BroadcastStartEvent(metadata *config.StreamMetadata) {
 	if !gc.isEnabled {
-		log.Println("⚠️ Nostr broadcasting disabled - keys not configured")
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
 		return
 	}
 
-	log.Println("📡 Broadcasting stream start event via Grain...")
+	logging.Infof("📡 Broadcasting stream start event via Grain...")
 
-	event := gc.buildStreamingEvent(metadata, "live")
+	event := buildStreamingEvent(metadata, "live")
 
-	if err := gc.signer.SignEvent(event); err != nil {
-		log.Printf("❌ Failed to sign start event: %v", err)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign start event: %v", err)
 		return
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
-		log.Printf("❌ Failed to publish start event: %v", err)
+		logging.Errorf("❌ Failed to publish start event: %v", err)
 		return
 	}
 
 	summary := core.SummarizeBroadcast(results)
-	log.Printf("📡 Start event published to %d/%d relays (%.1f%% success)",
+	logging.Infof("📡 Start event published to %d/%d relays (%.1f%% success)",
 		summary.Successful, summary.TotalRelays, summary.SuccessRate)
 }
 
 // BroadcastStartEventWithResponse broadcasts a start event and returns event info
 func (gc *GrainClient) BroadcastStartEventWithResponse(metadata *config.StreamMetadata) (string, []string) {
 	if !gc.isEnabled {
-		log.Println("⚠️ Nostr broadcasting disabled - keys not configured")
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
 		return "", []string{}
 	}
 
-	event := gc.buildStreamingEvent(metadata, "live")
+	event := buildStreamingEvent(metadata, "live")
 
-	if err := gc.signer.SignEvent(event); err != nil {
-		log.Printf("❌ Failed to sign start event: %v", err)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign start event: %v", err)
 		return "", []string{}
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
-		log.Printf("❌ Failed to publish start event: %v", err)
+		logging.Errorf("❌ Failed to publish start event: %v", err)
 		return "", []string{}
 	}
 
@@ -256,7 +479,7 @@ func (gc *GrainClient) BroadcastStartEventWithResponse(metadata *config.StreamMe
 	}
 
 	summary := core.SummarizeBroadcast(results)
-	log.Printf("📡 Start event published to %d/%d relays", summary.Successful, summary.TotalRelays)
+	logging.Infof("📡 Start event published to %d/%d relays", summary.Successful, summary.TotalRelays)
 
 	return string(eventJSON), successfulRelays
 }
@@ -264,29 +487,27 @@ func (gc *GrainClient) BroadcastStartEventWithResponse(metadata *config.StreamMe
 // BroadcastUpdateEvent broadcasts a stream metadata update
 func (gc *GrainClient) BroadcastUpdateEvent(metadata *config.StreamMetadata) {
 	if !gc.isEnabled {
-		log.Println("⚠️ Nostr broadcasting disabled - keys not configured")
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
 		return
 	}
 
-	log.Println("📡 Broadcasting stream update event via Grain...")
+	logging.Infof("📡 Broadcasting stream update event via Grain...")
 
-	event := gc.buildStreamingEvent(metadata, metadata.Status)
+	event := buildStreamingEvent(metadata, metadata.Status)
 
-	if err := gc.signer.SignEvent(event); err != nil {
-		log.Printf("❌ Failed to sign update event: %v", err)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign update event: %v", err)
 		return
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
-		log.Printf("❌ Failed to publish update event: %v", err)
+		logging.Errorf("❌ Failed to publish update event: %v", err)
 		return
 	}
 
 	summary := core.SummarizeBroadcast(results)
-	log.Printf("📡 Update event published to %d/%d relays (%.1f%% success)",
+	logging.Infof("📡 Update event published to %d/%d relays (%.1f%% success)",
 		summary.Successful, summary.TotalRelays, summary.SuccessRate)
 }
 
@@ -296,15 +517,13 @@ func (gc *GrainClient) BroadcastUpdateEventWithResponse(metadata *config.StreamM
 		return "", []string{}
 	}
 
-	event := gc.buildStreamingEvent(metadata, metadata.Status)
+	event := buildStreamingEvent(metadata, metadata.Status)
 
-	if err := gc.signer.SignEvent(event); err != nil {
+	if err := gc.signStreamEvent(event); err != nil {
 		return "", []string{}
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
 		return "", []string{}
 	}
@@ -323,29 +542,27 @@ func (gc *GrainClient) BroadcastUpdateEventWithResponse(metadata *config.StreamM
 // BroadcastEndEvent broadcasts a stream end event
 func (gc *GrainClient) BroadcastEndEvent(metadata *config.StreamMetadata) {
 	if !gc.isEnabled {
-		log.Println("⚠️ Nostr broadcasting disabled - keys not configured")
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
 		return
 	}
 
-	log.Println("📡 Broadcasting stream end event via Grain...")
+	logging.Infof("📡 Broadcasting stream end event via Grain...")
 
-	event := gc.buildStreamingEvent(metadata, "ended")
+	event := buildStreamingEvent(metadata, "ended")
 
-	if err := gc.signer.SignEvent(event); err != nil {
-		log.Printf("❌ Failed to sign end event: %v", err)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign end event: %v", err)
 		return
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
-		log.Printf("❌ Failed to publish end event: %v", err)
+		logging.Errorf("❌ Failed to publish end event: %v", err)
 		return
 	}
 
 	summary := core.SummarizeBroadcast(results)
-	log.Printf("📡 End event published to %d/%d relays (%.1f%% success)",
+	logging.Infof("📡 End event published to %d/%d relays (%.1f%% success)",
 		summary.Successful, summary.TotalRelays, summary.SuccessRate)
 }
 
@@ -355,15 +572,42 @@ func (gc *GrainClient) BroadcastEndEventWithResponse(metadata *config.StreamMeta
 		return "", []string{}
 	}
 
-	event := gc.buildStreamingEvent(metadata, "ended")
+	event := buildStreamingEvent(metadata, "ended")
 
-	if err := gc.signer.SignEvent(event); err != nil {
+	if err := gc.signStreamEvent(event); err != nil {
 		return "", []string{}
 	}
 
-	gc.ensureConnections()
+	results, err := gc.PublishEvent(event, nil)
+	if err != nil {
+		return "", []string{}
+	}
+
+	eventJSON, _ := json.Marshal(event)
+	var successfulRelays []string
+	for _, result := range results {
+		if result.Success {
+			successfulRelays = append(successfulRelays, result.RelayURL)
+		}
+	}
+
+	return string(eventJSON), successfulRelays
+}
+
+// BroadcastPlannedEventWithResponse announces a scheduled stream (NIP-53 status "planned") and returns event info
+func (gc *GrainClient) BroadcastPlannedEventWithResponse(metadata *config.StreamMetadata) (string, []string) {
+	if !gc.isEnabled {
+		return "", []string{}
+	}
+
+	event := buildStreamingEvent(metadata, "planned")
+
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign planned event: %v", err)
+		return "", []string{}
+	}
 
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
 		return "", []string{}
 	}
@@ -382,11 +626,11 @@ func (gc *GrainClient) BroadcastEndEventWithResponse(metadata *config.StreamMeta
 // BroadcastCancelEvent broadcasts a cancellation event
 func (gc *GrainClient) BroadcastCancelEvent(dtag string) {
 	if !gc.isEnabled {
-		log.Println("⚠️ Nostr broadcasting disabled - keys not configured")
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
 		return
 	}
 
-	log.Println("📡 Broadcasting stream cancellation event via Grain...")
+	logging.Infof("📡 Broadcasting stream cancellation event via Grain...")
 
 	event := core.NewEventBuilder(30311).
 		Content("").
@@ -395,31 +639,29 @@ func (gc *GrainClient) BroadcastCancelEvent(dtag string) {
 		Tag("summary", "Stream was incorrectly marked as live").
 		Build()
 
-	if err := gc.signer.SignEvent(event); err != nil {
-		log.Printf("❌ Failed to sign cancel event: %v", err)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign cancel event: %v", err)
 		return
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
-		log.Printf("❌ Failed to publish cancel event: %v", err)
+		logging.Errorf("❌ Failed to publish cancel event: %v", err)
 		return
 	}
 
 	summary := core.SummarizeBroadcast(results)
-	log.Printf("📡 Cancel event published to %d/%d relays", summary.Successful, summary.TotalRelays)
+	logging.Infof("📡 Cancel event published to %d/%d relays", summary.Successful, summary.TotalRelays)
 }
 
 // BroadcastDeletionEvent broadcasts a NIP-09 deletion request event
 func (gc *GrainClient) BroadcastDeletionEvent(eventID string, reason string) {
 	if !gc.isEnabled {
-		log.Println("⚠️ Nostr broadcasting disabled - keys not configured")
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
 		return
 	}
 
-	log.Printf("🗑️ Broadcasting NIP-09 deletion request for event: %s", eventID)
+	logging.Infof("🗑️ Broadcasting NIP-09 deletion request for event: %s", eventID)
 
 	content := reason
 	if content == "" {
@@ -432,21 +674,19 @@ func (gc *GrainClient) BroadcastDeletionEvent(eventID string, reason string) {
 					Tag("k", "30311"). // kind 30311 (live streaming event)
 					Build()
 
-	if err := gc.signer.SignEvent(event); err != nil {
-		log.Printf("❌ Failed to sign deletion event: %v", err)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign deletion event: %v", err)
 		return
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
-		log.Printf("❌ Failed to publish deletion event: %v", err)
+		logging.Errorf("❌ Failed to publish deletion event: %v", err)
 		return
 	}
 
 	summary := core.SummarizeBroadcast(results)
-	log.Printf("🗑️ Deletion request sent to %d/%d relays", summary.Successful, summary.TotalRelays)
+	logging.Infof("🗑️ Deletion request sent to %d/%d relays", summary.Successful, summary.TotalRelays)
 }
 
 // BroadcastDeletionEventWithResponse broadcasts a deletion request and returns event info
@@ -466,13 +706,12 @@ func (gc *GrainClient) BroadcastDeletionEventWithResponse(eventID string, reason
 		Tag("k", "30311").
 		Build()
 
-	if err := gc.signer.SignEvent(event); err != nil {
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign deletion event: %v", err)
 		return "", []string{}
 	}
 
-	gc.ensureConnections()
-
-	results, err := gc.client.PublishEvent(event, nil)
+	results, err := gc.PublishEvent(event, nil)
 	if err != nil {
 		return "", []string{}
 	}
@@ -494,6 +733,11 @@ func (gc *GrainClient) Subscribe(filters []nostr.Filter, relayHints []string) (*
 		return nil, fmt.Errorf("nostr client not enabled")
 	}
 
+	// Without hints, query the connected read relays (or the others if none of them is up)
+	if len(relayHints) == 0 {
+		relayHints, _ = gc.connectedSubset(gc.config.ReadRelayList())
+	}
+
 	return gc.client.Subscribe(filters, relayHints)
 }
 
@@ -503,11 +747,19 @@ func (gc *GrainClient) GetUserProfile(pubkey string, relayHints []string) (*nost
 		return nil, fmt.Errorf("nostr client not enabled")
 	}
 
+	// Consult the user's NIP-65 relay list when no hints were given
+	if len(relayHints) == 0 && gc.config.UseRelayHints {
+		relayHints = WriteRelayHints(gc.client, pubkey)
+	}
+
 	return gc.client.GetUserProfile(pubkey, relayHints)
 }
 
 // Close closes all relay connections
 func (gc *GrainClient) Close() error {
+	if gc.stopWatchdog != nil {
+		gc.closeOnce.Do(func() { close(gc.stopWatchdog) })
+	}
 	if gc.client != nil {
 		return gc.client.Close()
 	}
@@ -528,47 +780,5 @@ func DecodeNsec(nsec string) (string, error) {
 	if !strings.HasPrefix(nsec, "nsec1") {
 		return "", fmt.Errorf("invalid nsec format: must start with 'nsec1'")
 	}
-
-	// Remove the nsec1 prefix and decode bech32
-	data := nsec[5:] // Remove "nsec1" prefix
-	
-	// Simple base32 decode for nsec (this is a simplified implementation)
-	// In production, you should use a proper bech32 decoder
-	decoded := make([]byte, 32)
-	if err := decodeBech32(data, decoded); err != nil {
-		return "", fmt.Errorf("failed to decode bech32: %w", err)
-	}
-	
-	return hex.EncodeToString(decoded), nil
-}
-
-// Simple bech32 decoder (minimal implementation for nsec)
-func decodeBech32(data string, output []byte) error {
-	// This is a very basic implementation - in production use a proper bech32 library
-	const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
-	
-	values := make([]int, len(data))
-	for i, c := range data {
-		pos := strings.IndexRune(charset, c)
-		if pos == -1 {
-			return fmt.Errorf("invalid character: %c", c)
-		}
-		values[i] = pos
-	}
-	
-	// Convert from 5-bit to 8-bit groups
-	var acc, bits int
-	for i := 0; i < len(values)-6; i++ { // -6 for checksum
-		acc = (acc << 5) | values[i]
-		bits += 5
-		if bits >= 8 {
-			bits -= 8
-			if len(output) > 0 {
-				output[0] = byte(acc >> bits)
-				output = output[1:]
-			}
-		}
-	}
-	
-	return nil
-}
\ No newline at end of file
+	return nip19.DecodeNsec(nsec)
+}
@@ -0,0 +1,155 @@
+package nostr
+
+import (
+	"slices"
+	"testing"
+
+	"gnostream/src/config"
+)
+
+// tagsNamed returns the values (everything after the name) of every tag called name, in order
+func tagsNamed(tags [][]string, name string) [][]string {
+	var values [][]string
+	for _, tag := range tags {
+		if len(tag) > 0 && tag[0] == name {
+			values = append(values, tag[1:])
+		}
+	}
+	return values
+}
+
+func TestBuildStreamingEvent(t *testing.T) {
+	base := config.StreamMetadata{
+		Title:     "Test stream",
+		Summary:   "Testing tags",
+		Dtag:      "stream-123",
+		StreamURL: "https://stream.example/live/output.m3u8",
+		Starts:    "1700000000",
+		Tags:      []string{"gnostream", "music"},
+	}
+
+	tests := []struct {
+		name       string
+		status     string
+		edit       func(md *config.StreamMetadata)
+		want       map[string][][]string // Exact values for each listed tag
+		wantAbsent []string
+	}{
+		{
+			name:   "live",
+			status: "live",
+			edit: func(md *config.StreamMetadata) {
+				md.Ends = "1700003600" // A stale end time must not leak into a live event
+			},
+			want: map[string][][]string{
+				"d":         {{"stream-123"}},
+				"title":     {{"Test stream"}},
+				"summary":   {{"Testing tags"}},
+				"streaming": {{"https://stream.example/live/output.m3u8"}},
+				"starts":    {{"1700000000"}},
+				"status":    {{"live"}},
+				"t":         {{"gnostream"}, {"music"}},
+			},
+			wantAbsent: []string{"ends", "recording", "image", "p", "L", "l"},
+		},
+		{
+			name:   "ended with recording",
+			status: "ended",
+			edit: func(md *config.StreamMetadata) {
+				md.Ends = "1700003600"
+				md.RecordingURL = "https://stream.example/archive/2023-11-14/output.m3u8"
+				md.Image = "https://stream.example/thumb.jpg"
+			},
+			want: map[string][][]string{
+				"d":         {{"stream-123"}},
+				"streaming": {{"https://stream.example/live/output.m3u8"}},
+				"starts":    {{"1700000000"}},
+				"ends":      {{"1700003600"}},
+				"status":    {{"ended"}},
+				"recording": {{"https://stream.example/archive/2023-11-14/output.m3u8"}},
+				"image":     {{"https://stream.example/thumb.jpg"}},
+				"t":         {{"gnostream"}, {"music"}},
+			},
+			wantAbsent: []string{"p"},
+		},
+		{
+			name:   "ended without recording",
+			status: "ended",
+			edit: func(md *config.StreamMetadata) {
+				md.Ends = "1700003600"
+			},
+			want: map[string][][]string{
+				"ends":   {{"1700003600"}},
+				"status": {{"ended"}},
+			},
+			wantAbsent: []string{"recording", "image", "p"},
+		},
+		{
+			name:   "planned with category",
+			status: "planned",
+			edit: func(md *config.StreamMetadata) {
+				md.Starts = "1800000000"
+				md.Category = "Chess"
+			},
+			want: map[string][][]string{
+				"d":      {{"stream-123"}},
+				"starts": {{"1800000000"}},
+				"status": {{"planned"}},
+				"t":      {{"gnostream"}, {"music"}, {"Chess"}},
+				"L":      {{categoryLabelNamespace}},
+				"l":      {{"Chess", categoryLabelNamespace}},
+			},
+			wantAbsent: []string{"ends", "recording", "p"},
+		},
+		{
+			name:   "category already a hashtag",
+			status: "live",
+			edit: func(md *config.StreamMetadata) {
+				md.Category = "MUSIC"
+			},
+			want: map[string][][]string{
+				"t": {{"gnostream"}, {"music"}},
+				"l": {{"MUSIC", categoryLabelNamespace}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := base.Clone()
+			if tt.edit != nil {
+				tt.edit(metadata)
+			}
+
+			event := buildStreamingEvent(metadata, tt.status)
+
+			if event.Kind != 30311 {
+				t.Errorf("kind = %d, want 30311", event.Kind)
+			}
+			if event.Content != "" {
+				t.Errorf("content = %q, want empty", event.Content)
+			}
+			for name, want := range tt.want {
+				got := tagsNamed(event.Tags, name)
+				if !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+					t.Errorf("%q tags = %v, want %v", name, got, want)
+				}
+			}
+			for _, name := range tt.wantAbsent {
+				if got := tagsNamed(event.Tags, name); len(got) > 0 {
+					t.Errorf("unexpected %q tags %v", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildStreamingEventDoesNotModifyMetadata(t *testing.T) {
+	metadata := &config.StreamMetadata{Dtag: "stream-123", Tags: []string{"gnostream"}, Category: "Chess"}
+
+	buildStreamingEvent(metadata, "live")
+
+	if !slices.Equal(metadata.Tags, []string{"gnostream"}) {
+		t.Errorf("metadata tags changed to %v", metadata.Tags)
+	}
+}
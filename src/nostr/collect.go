@@ -0,0 +1,66 @@
+package nostr
+
+import (
+	"context"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostr "github.com/0ceanslim/grain/server/types"
+)
+
+// CollectEvents reads events from a subscription until every relay has signalled end of stored
+// events (EOSE), limit events have arrived (0 = no limit) or ctx is done, then closes the
+// subscription. Events seen from more than one relay are returned once.
+//
+// Grain hands EOSE over without buffering, so one can be missed while an event is being handled;
+// the context deadline bounds the wait in that case.
+func CollectEvents(ctx context.Context, subscription *core.Subscription, limit int) []*nostr.Event {
+	defer subscription.Close()
+
+	relays := len(subscription.Relays)
+	if relays == 0 {
+		relays = 1
+	}
+
+	var events []*nostr.Event
+	seen := make(map[string]bool)
+
+	// add records an event and reports whether the limit has been reached
+	add := func(event *nostr.Event) bool {
+		if event == nil || seen[event.ID] {
+			return false
+		}
+		seen[event.ID] = true
+		events = append(events, event)
+		return limit > 0 && len(events) >= limit
+	}
+
+	eose := 0
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok || add(event) {
+				return events
+			}
+
+		case <-subscription.Done:
+			eose++
+			if eose < relays {
+				continue
+			}
+			// Events routed just before the last EOSE may still be buffered
+			for {
+				select {
+				case event, ok := <-subscription.Events:
+					if !ok || add(event) {
+						return events
+					}
+				default:
+					return events
+				}
+			}
+
+		case <-ctx.Done():
+			return events
+		}
+	}
+}
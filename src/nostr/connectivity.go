@@ -0,0 +1,73 @@
+package nostr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core"
+
+	"gnostream/src/logging"
+)
+
+// RelayTestResult reports how one relay answered the connectivity test event
+type RelayTestResult struct {
+	Relay     string `json:"relay"`
+	Accepted  bool   `json:"accepted"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// PublishTestEvent publishes a throwaway, already-ended live event to every configured relay and
+// requests its deletion right away, returning the event ID and each relay's answer
+func (gc *GrainClient) PublishTestEvent() (string, []RelayTestResult, error) {
+	if !gc.isEnabled {
+		return "", nil, fmt.Errorf("nostr client not enabled")
+	}
+
+	relays := gc.config.AllRelays()
+	logging.Infof("🧪 Publishing connectivity test event to %d relays...", len(relays))
+
+	event := core.NewEventBuilder(30311).
+		Content("").
+		DTag(fmt.Sprintf("gnostream-test-%d", time.Now().Unix())).
+		Tag("title", "gnostream connectivity test").
+		Tag("status", "ended").
+		Build()
+
+	if err := gc.signStreamEvent(event); err != nil {
+		return "", nil, fmt.Errorf("failed to sign test event: %w", err)
+	}
+
+	broadcast, err := gc.PublishEvent(event, relays)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to publish test event: %w", err)
+	}
+
+	results := make([]RelayTestResult, 0, len(broadcast))
+	accepted := 0
+	for _, result := range broadcast {
+		relayResult := RelayTestResult{
+			Relay:     result.RelayURL,
+			Accepted:  result.Success,
+			Message:   result.Message,
+			LatencyMs: result.Duration.Milliseconds(),
+		}
+		if result.Error != nil {
+			relayResult.Error = result.Error.Error()
+		}
+		if result.Success {
+			accepted++
+		}
+		results = append(results, relayResult)
+	}
+
+	logging.Infof("🧪 Test event accepted by %d/%d relays", accepted, len(results))
+
+	// Clean up so the test doesn't linger in anyone's stream list
+	if accepted > 0 {
+		gc.BroadcastDeletionEvent(event.ID, "gnostream connectivity test")
+	}
+
+	return event.ID, results, nil
+}
@@ -0,0 +1,94 @@
+package nostr
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostr "github.com/0ceanslim/grain/server/types"
+)
+
+// httpAuthKind is the NIP-98 HTTP Auth event kind
+const httpAuthKind = 27235
+
+// httpAuthMaxAge is how far an auth event's created_at may drift from the server clock
+const httpAuthMaxAge = 60 * time.Second
+
+// BuildHTTPAuthHeader signs a NIP-98 auth event for a request and returns the Authorization header value
+func BuildHTTPAuthHeader(signer Signer, requestURL, method string) (string, error) {
+	event := core.NewEventBuilder(httpAuthKind).
+		Tag("u", requestURL).
+		Tag("method", strings.ToUpper(method)).
+		Build()
+
+	if err := signer.Sign(event); err != nil {
+		return "", fmt.Errorf("failed to sign auth event: %w", err)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode auth event: %w", err)
+	}
+
+	return "Nostr " + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// VerifyHTTPAuthHeader validates a NIP-98 Authorization header for a request path and method
+// and returns the signer's public key. Only the path of the "u" tag is compared, so requests
+// made through a reverse proxy or to a loopback address still verify.
+func VerifyHTTPAuthHeader(header, requestPath, method string) (string, error) {
+	encoded, ok := strings.CutPrefix(header, "Nostr ")
+	if !ok {
+		return "", fmt.Errorf("missing Nostr authorization")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization encoding: %w", err)
+	}
+
+	var event nostr.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", fmt.Errorf("invalid auth event: %w", err)
+	}
+
+	if event.Kind != httpAuthKind {
+		return "", fmt.Errorf("unexpected auth event kind %d", event.Kind)
+	}
+
+	age := time.Since(time.Unix(event.CreatedAt, 0))
+	if age > httpAuthMaxAge || age < -httpAuthMaxAge {
+		return "", fmt.Errorf("auth event expired")
+	}
+
+	var taggedURL, taggedMethod string
+	for _, tag := range event.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "u":
+			taggedURL = tag[1]
+		case "method":
+			taggedMethod = tag[1]
+		}
+	}
+
+	parsed, err := url.Parse(taggedURL)
+	if err != nil || parsed.Path != requestPath {
+		return "", fmt.Errorf("auth event URL does not match request")
+	}
+	if !strings.EqualFold(taggedMethod, method) {
+		return "", fmt.Errorf("auth event method does not match request")
+	}
+
+	if !core.VerifyEventSignature(&event) {
+		return "", fmt.Errorf("invalid auth event signature")
+	}
+
+	return event.PubKey, nil
+}
@@ -0,0 +1,159 @@
+package nostr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostr "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/logging"
+)
+
+// muteListKind is the NIP-51 mute list, a replaceable event holding muted pubkeys as "p" tags
+const muteListKind = 10000
+
+// muteListFetchTimeout bounds the lookup of the current mute list before it is changed
+const muteListFetchTimeout = 5 * time.Second
+
+// MuteListResult reports the mute list after a change
+type MuteListResult struct {
+	Muted            []string `json:"muted"`   // Every pubkey on the list
+	Changed          bool     `json:"changed"` // False when the pubkey was already (un)muted
+	EventID          string   `json:"event_id,omitempty"`
+	SuccessfulRelays []string `json:"successful_relays,omitempty"`
+}
+
+// GetMuteList returns the pubkeys on the owner's current NIP-51 mute list
+func (gc *GrainClient) GetMuteList() ([]string, error) {
+	if !gc.isEnabled {
+		return nil, fmt.Errorf("nostr client not enabled")
+	}
+
+	latest, err := gc.fetchMuteList()
+	if err != nil || latest == nil {
+		return []string{}, err
+	}
+	return mutedPubkeys(latest.Tags), nil
+}
+
+// UpdateMuteList adds (mute) or removes a pubkey on the owner's NIP-51 mute list and republishes
+// it. The list is a replaceable event shared with other clients, so the latest revision is
+// fetched first and every other entry (words, threads, pubkeys muted elsewhere) is kept. Muting
+// a pubkey that is already listed, or unmuting one that isn't, publishes nothing.
+func (gc *GrainClient) UpdateMuteList(pubkey string, mute bool) (*MuteListResult, error) {
+	if !gc.isEnabled {
+		return nil, fmt.Errorf("nostr client not enabled")
+	}
+
+	gc.muteListMux.Lock()
+	defer gc.muteListMux.Unlock()
+
+	latest, err := gc.fetchMuteList()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags [][]string
+	content := ""
+	if latest != nil {
+		tags = latest.Tags
+		content = latest.Content // NIP-51 private (encrypted) entries, kept as they are
+	}
+
+	updated, changed := setMuteTag(tags, pubkey, mute)
+	result := &MuteListResult{Muted: mutedPubkeys(updated), Changed: changed}
+	if !changed {
+		return result, nil
+	}
+
+	eventBuilder := core.NewEventBuilder(muteListKind).Content(content)
+	for _, tag := range updated {
+		if len(tag) > 0 {
+			eventBuilder = eventBuilder.Tag(tag[0], tag[1:]...)
+		}
+	}
+	event := eventBuilder.Build()
+	if err := gc.signStreamEvent(event); err != nil {
+		return nil, fmt.Errorf("failed to sign mute list: %w", err)
+	}
+
+	results, err := gc.PublishEvent(event, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish mute list: %w", err)
+	}
+
+	result.EventID = event.ID
+	for _, r := range results {
+		if r.Success {
+			result.SuccessfulRelays = append(result.SuccessfulRelays, r.RelayURL)
+		}
+	}
+
+	action := "Muted"
+	if !mute {
+		action = "Unmuted"
+	}
+	logging.Infof("🔇 %s %s... - mute list (%d entries) published to %d/%d relays",
+		action, pubkey[:8], len(result.Muted), len(result.SuccessfulRelays), len(results))
+
+	return result, nil
+}
+
+// fetchMuteList returns the newest mute list revision on the relays, or nil if there is none
+func (gc *GrainClient) fetchMuteList() (*nostr.Event, error) {
+	limit := 1
+	filters := []nostr.Filter{{
+		Authors: []string{gc.publicKey},
+		Kinds:   []int{muteListKind},
+		Limit:   &limit,
+	}}
+
+	subscription, err := gc.client.Subscribe(filters, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mute list: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), muteListFetchTimeout)
+	defer cancel()
+
+	var latest *nostr.Event
+	for _, event := range CollectEvents(ctx, subscription, 0) {
+		if latest == nil || event.CreatedAt > latest.CreatedAt {
+			latest = event
+		}
+	}
+	return latest, nil
+}
+
+// setMuteTag returns tags with pubkey's "p" entry added or removed, and whether anything changed
+func setMuteTag(tags [][]string, pubkey string, mute bool) ([][]string, bool) {
+	updated := make([][]string, 0, len(tags)+1)
+	found := false
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == "p" && tag[1] == pubkey {
+			if found || !mute {
+				continue // Drop duplicates, or the entry itself when unmuting
+			}
+			found = true
+		}
+		updated = append(updated, tag)
+	}
+
+	if mute && !found {
+		return append(updated, []string{"p", pubkey}), true
+	}
+	return updated, !mute && len(updated) != len(tags)
+}
+
+// mutedPubkeys returns the pubkeys listed in mute list tags
+func mutedPubkeys(tags [][]string) []string {
+	pubkeys := []string{}
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == "p" {
+			pubkeys = append(pubkeys, tag[1])
+		}
+	}
+	return pubkeys
+}
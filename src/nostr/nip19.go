@@ -0,0 +1,137 @@
+package nostr
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"gnostream/src/nip19"
+)
+
+// NIP-19 TLV types
+const (
+	tlvSpecial = 0 // d tag (naddr) or event ID (nevent)
+	tlvRelay   = 1
+	tlvAuthor  = 2
+	tlvKind    = 3
+)
+
+// EncodeNaddr encodes an addressable event coordinate (e.g. the 30311 live event) as a NIP-19 naddr
+func EncodeNaddr(kind int, pubkey, dtag string, relays []string) (string, error) {
+	author, err := decodeHex32(pubkey)
+	if err != nil {
+		return "", fmt.Errorf("invalid pubkey: %w", err)
+	}
+
+	var tlv []byte
+	tlv = appendTLV(tlv, tlvSpecial, []byte(dtag))
+	for _, relay := range relays {
+		tlv = appendTLV(tlv, tlvRelay, []byte(relay))
+	}
+	tlv = appendTLV(tlv, tlvAuthor, author)
+	tlv = appendTLV(tlv, tlvKind, kindBytes(kind))
+
+	return nip19.Encode("naddr", tlv)
+}
+
+// EncodeNevent encodes an event ID with author, kind and relay hints as a NIP-19 nevent
+func EncodeNevent(eventID, pubkey string, kind int, relays []string) (string, error) {
+	id, err := decodeHex32(eventID)
+	if err != nil {
+		return "", fmt.Errorf("invalid event ID: %w", err)
+	}
+
+	var tlv []byte
+	tlv = appendTLV(tlv, tlvSpecial, id)
+	for _, relay := range relays {
+		tlv = appendTLV(tlv, tlvRelay, []byte(relay))
+	}
+	if pubkey != "" {
+		author, err := decodeHex32(pubkey)
+		if err != nil {
+			return "", fmt.Errorf("invalid pubkey: %w", err)
+		}
+		tlv = appendTLV(tlv, tlvAuthor, author)
+	}
+	tlv = appendTLV(tlv, tlvKind, kindBytes(kind))
+
+	return nip19.Encode("nevent", tlv)
+}
+
+// NormalizePubkey accepts a public key as 64-character hex, npub or nprofile and returns it as
+// lowercase hex
+func NormalizePubkey(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "nostr:")
+
+	switch {
+	case strings.HasPrefix(strings.ToLower(value), "npub1"):
+		data, err := nip19.Decode("npub", value)
+		if err != nil {
+			return "", fmt.Errorf("invalid npub: %w", err)
+		}
+		if len(data) != 32 {
+			return "", fmt.Errorf("invalid npub: expected 32 bytes, got %d", len(data))
+		}
+		return hex.EncodeToString(data), nil
+
+	case strings.HasPrefix(strings.ToLower(value), "nprofile1"):
+		data, err := nip19.Decode("nprofile", value)
+		if err != nil {
+			return "", fmt.Errorf("invalid nprofile: %w", err)
+		}
+		pubkey, ok := findTLV(data, tlvSpecial)
+		if !ok || len(pubkey) != 32 {
+			return "", fmt.Errorf("invalid nprofile: missing public key")
+		}
+		return hex.EncodeToString(pubkey), nil
+
+	default:
+		if _, err := decodeHex32(value); err != nil {
+			return "", fmt.Errorf("invalid public key (expected npub, nprofile or 64-character hex)")
+		}
+		return strings.ToLower(value), nil
+	}
+}
+
+// appendTLV appends one type-length-value entry (values over 255 bytes are truncated)
+func appendTLV(buf []byte, typ byte, value []byte) []byte {
+	if len(value) > 255 {
+		value = value[:255]
+	}
+	buf = append(buf, typ, byte(len(value)))
+	return append(buf, value...)
+}
+
+func kindBytes(kind int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(kind))
+	return b
+}
+
+func decodeHex32(value string) ([]byte, error) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != 32 {
+		return nil, fmt.Errorf("expected 32 bytes, got %d", len(decoded))
+	}
+	return decoded, nil
+}
+
+// findTLV returns the first value of the given type in a TLV sequence
+func findTLV(tlv []byte, typ byte) ([]byte, bool) {
+	for len(tlv) >= 2 {
+		t, length := tlv[0], int(tlv[1])
+		if len(tlv) < 2+length {
+			return nil, false
+		}
+		if t == typ {
+			return tlv[2 : 2+length], true
+		}
+		tlv = tlv[2+length:]
+	}
+	return nil, false
+}
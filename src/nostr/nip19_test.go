@@ -0,0 +1,172 @@
+package nostr
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"gnostream/src/nip19"
+)
+
+// NIP-19 test vector: the same key as npub and as nprofile with two relay hints
+const (
+	vectorPubkey   = "3bf0c63fcb93463407af97a5e5ee64fa883d107ef9e558472c4eb9aaaefa459d"
+	vectorNpub     = "npub180cvv07tjdrrgpa0j7j7tmnyl2yr6yr7l8j4s3evf6u64th6gkwsyjh6w6"
+	vectorNprofile = "nprofile1qqsrhuxx8l9ex335q7he0f09aej04zpazpl0ne2cgukyawd24mayt8gpp4mhxue69uhhytnc9e3k7mgpz4mhxue69uhkg6nzv9ejuumpv34kytnrdaksjlyr9p"
+)
+
+type tlvEntry struct {
+	typ   byte
+	value []byte
+}
+
+// decodeTLVs decodes a NIP-19 string and splits it into its TLV entries, in order
+func decodeTLVs(t *testing.T, hrp, value string) []tlvEntry {
+	t.Helper()
+
+	data, err := nip19.Decode(hrp, value)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", value, err)
+	}
+
+	var entries []tlvEntry
+	for len(data) > 0 {
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			t.Fatalf("truncated TLV in %s", value)
+		}
+		length := int(data[1])
+		entries = append(entries, tlvEntry{typ: data[0], value: data[2 : 2+length]})
+		data = data[2+length:]
+	}
+	return entries
+}
+
+func TestEncodeNaddr(t *testing.T) {
+	relays := []string{"wss://relay.one", "wss://relay.two"}
+
+	naddr, err := EncodeNaddr(30311, vectorPubkey, "stream-123", relays)
+	if err != nil {
+		t.Fatalf("EncodeNaddr: %v", err)
+	}
+	if !strings.HasPrefix(naddr, "naddr1") {
+		t.Fatalf("naddr = %q, want naddr1 prefix", naddr)
+	}
+
+	entries := decodeTLVs(t, "naddr", naddr)
+	want := []tlvEntry{
+		{tlvSpecial, []byte("stream-123")},
+		{tlvRelay, []byte("wss://relay.one")},
+		{tlvRelay, []byte("wss://relay.two")},
+		{tlvAuthor, mustHex(t, vectorPubkey)},
+		{tlvKind, []byte{0, 0, 0x76, 0x67}}, // 30311, 32-bit big-endian
+	}
+	assertTLVs(t, entries, want)
+}
+
+func TestEncodeNevent(t *testing.T) {
+	eventID := strings.Repeat("ab", 32)
+
+	t.Run("with author", func(t *testing.T) {
+		nevent, err := EncodeNevent(eventID, vectorPubkey, 30311, []string{"wss://relay.one"})
+		if err != nil {
+			t.Fatalf("EncodeNevent: %v", err)
+		}
+
+		entries := decodeTLVs(t, "nevent", nevent)
+		assertTLVs(t, entries, []tlvEntry{
+			{tlvSpecial, mustHex(t, eventID)},
+			{tlvRelay, []byte("wss://relay.one")},
+			{tlvAuthor, mustHex(t, vectorPubkey)},
+			{tlvKind, binary.BigEndian.AppendUint32(nil, 30311)},
+		})
+	})
+
+	t.Run("without author or relays", func(t *testing.T) {
+		nevent, err := EncodeNevent(eventID, "", 1, nil)
+		if err != nil {
+			t.Fatalf("EncodeNevent: %v", err)
+		}
+
+		entries := decodeTLVs(t, "nevent", nevent)
+		assertTLVs(t, entries, []tlvEntry{
+			{tlvSpecial, mustHex(t, eventID)},
+			{tlvKind, []byte{0, 0, 0, 1}},
+		})
+	})
+}
+
+func TestEncodeRejectsInvalidKeys(t *testing.T) {
+	if _, err := EncodeNaddr(30311, "not-hex", "stream-123", nil); err == nil {
+		t.Error("EncodeNaddr accepted a non-hex pubkey")
+	}
+	if _, err := EncodeNaddr(30311, vectorPubkey[:62], "stream-123", nil); err == nil {
+		t.Error("EncodeNaddr accepted a 31-byte pubkey")
+	}
+	if _, err := EncodeNevent("abcd", vectorPubkey, 30311, nil); err == nil {
+		t.Error("EncodeNevent accepted a short event ID")
+	}
+	if _, err := EncodeNevent(strings.Repeat("ab", 32), "npub1xyz", 30311, nil); err == nil {
+		t.Error("EncodeNevent accepted a non-hex pubkey")
+	}
+}
+
+func TestNormalizePubkey(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "hex", input: vectorPubkey},
+		{name: "uppercase hex", input: strings.ToUpper(vectorPubkey)},
+		{name: "npub", input: vectorNpub},
+		{name: "nostr: URI", input: "nostr:" + vectorNpub},
+		{name: "nprofile with relays", input: vectorNprofile},
+		{name: "padded", input: "  " + vectorNpub + "\n"},
+		{name: "bad checksum", input: vectorNpub[:len(vectorNpub)-1] + "q", wantErr: true},
+		{name: "mixed case", input: "npub1" + strings.ToUpper(vectorNpub[5:10]) + vectorNpub[10:], wantErr: true},
+		{name: "short hex", input: vectorPubkey[:60], wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePubkey(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizePubkey(%q) = %q, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizePubkey(%q): %v", tt.input, err)
+			}
+			if got != vectorPubkey {
+				t.Errorf("NormalizePubkey(%q) = %q, want %q", tt.input, got, vectorPubkey)
+			}
+		})
+	}
+}
+
+func mustHex(t *testing.T, value string) []byte {
+	t.Helper()
+
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		t.Fatalf("decoding hex %q: %v", value, err)
+	}
+	return decoded
+}
+
+func assertTLVs(t *testing.T, got, want []tlvEntry) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d TLV entries, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].typ != want[i].typ || string(got[i].value) != string(want[i].value) {
+			t.Errorf("TLV %d = type %d %x, want type %d %x", i, got[i].typ, got[i].value, want[i].typ, want[i].value)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package nostr
+
+import (
+	"encoding/json"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostr "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/logging"
+)
+
+// maxRelayHints caps how many discovered relays are added to a query
+const maxRelayHints = 5
+
+// buildRelayListEvent builds a NIP-65 relay list (kind 10002) from the write and read relays.
+// Unmarked "r" tags mean the relay is used for both reading and writing.
+func buildRelayListEvent(write, read []string) *nostr.Event {
+	isWrite := make(map[string]bool, len(write))
+	for _, relay := range write {
+		isWrite[relay] = true
+	}
+	isRead := make(map[string]bool, len(read))
+	for _, relay := range read {
+		isRead[relay] = true
+	}
+
+	tagged := make(map[string]bool)
+	eventBuilder := core.NewEventBuilder(10002).Content("")
+	for _, relay := range append(append([]string{}, write...), read...) {
+		if tagged[relay] {
+			continue
+		}
+		tagged[relay] = true
+
+		switch {
+		case isWrite[relay] && isRead[relay]:
+			eventBuilder = eventBuilder.Tag("r", relay)
+		case isWrite[relay]:
+			eventBuilder = eventBuilder.Tag("r", relay, "write")
+		case isRead[relay]:
+			eventBuilder = eventBuilder.Tag("r", relay, "read")
+		}
+	}
+	return eventBuilder.Build()
+}
+
+// BroadcastRelayListEventWithResponse publishes the configured relays as the owner's NIP-65 relay list
+func (gc *GrainClient) BroadcastRelayListEventWithResponse() (string, []string) {
+	if !gc.isEnabled {
+		logging.Warnf("⚠️ Nostr broadcasting disabled - keys not configured")
+		return "", []string{}
+	}
+
+	write, read := gc.config.WriteRelayList(), gc.config.ReadRelayList()
+	logging.Infof("📡 Broadcasting NIP-65 relay list (%d write, %d read relays)...", len(write), len(read))
+
+	event := buildRelayListEvent(write, read)
+	if err := gc.signStreamEvent(event); err != nil {
+		logging.Errorf("❌ Failed to sign relay list event: %v", err)
+		return "", []string{}
+	}
+
+	results, err := gc.PublishEvent(event, nil)
+	if err != nil {
+		logging.Errorf("❌ Failed to publish relay list event: %v", err)
+		return "", []string{}
+	}
+
+	summary := core.SummarizeBroadcast(results)
+	logging.Infof("📡 Relay list published to %d/%d relays", summary.Successful, summary.TotalRelays)
+
+	eventJSON, _ := json.Marshal(event)
+	var successfulRelays []string
+	for _, result := range results {
+		if result.Success {
+			successfulRelays = append(successfulRelays, result.RelayURL)
+		}
+	}
+
+	return string(eventJSON), successfulRelays
+}
+
+// WriteRelayHints looks up a user's NIP-65 relay list and returns relays to query for their events:
+// the client's connected relays plus up to maxRelayHints of the user's write relays.
+// Returns nil (use connected relays) if the user has no relay list.
+func WriteRelayHints(client *core.Client, pubkey string) []string {
+	if client == nil {
+		return nil
+	}
+
+	mailboxes, err := client.GetUserRelays(pubkey)
+	if err != nil || mailboxes == nil {
+		return nil
+	}
+
+	hints := client.GetConnectedRelays()
+	seen := make(map[string]bool, len(hints))
+	for _, relay := range hints {
+		seen[relay] = true
+	}
+
+	var discovered []string
+	for _, relay := range append(mailboxes.Write, mailboxes.Both...) {
+		if seen[relay] || len(discovered) >= maxRelayHints {
+			continue
+		}
+		seen[relay] = true
+		discovered = append(discovered, relay)
+	}
+
+	if len(discovered) == 0 {
+		return nil
+	}
+
+	// Only relays we're connected to can serve the subscription
+	if err := client.ConnectToRelays(discovered); err != nil {
+		logging.Debugf("🔍 Some relay hints for %s failed to connect: %v", pubkey[:8], err)
+	}
+
+	return client.GetConnectedRelays()
+}
@@ -0,0 +1,100 @@
+package nostr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/0ceanslim/grain/client/core"
+	"github.com/0ceanslim/grain/client/session"
+	nostr "github.com/0ceanslim/grain/server/types"
+)
+
+// ErrExternalSigning is returned when an event has to be signed outside the server
+// (browser extension, Amber, bunker). The event is left unsigned with its pubkey set.
+var ErrExternalSigning = errors.New("event must be signed by an external signer")
+
+// Signer signs Nostr events on behalf of a key holder
+type Signer interface {
+	Sign(event *nostr.Event) error
+	PublicKey() string
+}
+
+// LocalSigner signs events with a private key held by the server
+type LocalSigner struct {
+	signer *core.EventSigner
+}
+
+// NewLocalSigner creates a signer from a hex private key
+func NewLocalSigner(privateKeyHex string) (*LocalSigner, error) {
+	signer, err := core.NewEventSigner(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+	return &LocalSigner{signer: signer}, nil
+}
+
+// Sign signs the event and sets its ID, PubKey and Sig
+func (s *LocalSigner) Sign(event *nostr.Event) error {
+	return s.signer.SignEvent(event)
+}
+
+// PublicKey returns the hex public key of the signer
+func (s *LocalSigner) PublicKey() string {
+	return s.signer.GetPublicKey()
+}
+
+// ExternalSigner prepares events for signing by the user's own signer (extension/Amber)
+type ExternalSigner struct {
+	publicKey string
+	method    session.SigningMethod
+}
+
+// NewExternalSigner creates a signer that hands events back for external signing
+func NewExternalSigner(publicKey string, method session.SigningMethod) *ExternalSigner {
+	return &ExternalSigner{
+		publicKey: publicKey,
+		method:    method,
+	}
+}
+
+// Sign sets the event pubkey and returns ErrExternalSigning so the caller can
+// pass the unsigned event to the client
+func (s *ExternalSigner) Sign(event *nostr.Event) error {
+	event.PubKey = s.publicKey
+	return fmt.Errorf("%w (%s)", ErrExternalSigning, s.method)
+}
+
+// PublicKey returns the hex public key of the external signer
+func (s *ExternalSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// SignerForSession returns the signer matching a logged-in user's signing method
+func SignerForSession(userSession *session.UserSession) (Signer, error) {
+	if userSession == nil {
+		return nil, fmt.Errorf("no user session")
+	}
+
+	switch userSession.SigningMethod {
+	case session.EncryptedKey:
+		if userSession.EncryptedPrivateKey == "" {
+			return nil, fmt.Errorf("session has no private key")
+		}
+		privateKeyHex := userSession.EncryptedPrivateKey
+		if strings.HasPrefix(privateKeyHex, "nsec") {
+			decoded, err := DecodeNsec(privateKeyHex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode session nsec: %w", err)
+			}
+			privateKeyHex = decoded
+		}
+		return NewLocalSigner(privateKeyHex)
+
+	case session.BrowserExtension, session.AmberSigning, session.BunkerSigning:
+		return NewExternalSigner(userSession.PublicKey, userSession.SigningMethod), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %s", userSession.SigningMethod)
+	}
+}
@@ -0,0 +1,84 @@
+package rtmp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gnostream/src/logging"
+)
+
+// authorizePublish asks rtmp.auth_url whether the encoder publishing with streamKey may go live,
+// the way nginx-rtmp's on_publish does: a form-encoded POST with call=publish, app and name,
+// where any 2xx answer allows the stream. Errors and timeouts deny it. Without an auth_url
+// every stream is allowed.
+func (s *Server) authorizePublish(streamKey string) bool {
+	rtmpDefaults := s.config.GetRTMPDefaults()
+	if rtmpDefaults.AuthURL == "" {
+		return true
+	}
+
+	if err := postPublishAuth(s.ctx, rtmpDefaults.AuthURL, streamKey, rtmpDefaults.AuthTimeout); err != nil {
+		logging.Warnf("🚫 Publish denied for %s: %v", streamKey, err)
+		return false
+	}
+
+	logging.Infof("🔑 Publish authorized for %s", streamKey)
+	return true
+}
+
+// postPublishAuth makes the callback request, returning an error unless it answers 2xx
+func postPublishAuth(ctx context.Context, authURL, streamKey string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	form := url.Values{
+		"call": {"publish"},
+		"app":  {"live"},
+		"name": {streamKey},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, authURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("invalid auth_url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "gnostream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("auth_url unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("auth_url answered %s", resp.Status)
+	}
+	return nil
+}
+
+// clearRejectedOutput deletes any playlists and segments a denied publisher produced in the
+// output directory while auth_url was answering, so none of it is served. Subdirectories (the
+// archive) are left alone.
+func (s *Server) clearRejectedOutput() {
+	outputDir := s.config.GetStreamDefaults().OutputDir
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".m3u8", ".ts", ".m4s", ".mp4":
+			if !entry.IsDir() {
+				os.Remove(filepath.Join(outputDir, entry.Name()))
+			}
+		}
+	}
+}
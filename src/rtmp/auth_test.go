@@ -0,0 +1,118 @@
+package rtmp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gnostream/src/config"
+)
+
+// authRecorder is an auth_url stub that answers with status and records the forms it was sent
+type authRecorder struct {
+	mu     sync.Mutex
+	forms  []map[string]string
+	status int
+}
+
+func (a *authRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	a.mu.Lock()
+	a.forms = append(a.forms, map[string]string{
+		"call": r.PostForm.Get("call"),
+		"app":  r.PostForm.Get("app"),
+		"name": r.PostForm.Get("name"),
+	})
+	a.mu.Unlock()
+	w.WriteHeader(a.status)
+}
+
+// newAuthTestServer returns an RTMP server configured with an auth_url answering status
+func newAuthTestServer(t *testing.T, status int, streamKey string) (*Server, *authRecorder) {
+	t.Helper()
+
+	recorder := &authRecorder{status: status}
+	httpServer := httptest.NewServer(recorder)
+	t.Cleanup(httpServer.Close)
+
+	cfg := &config.Config{}
+	cfg.RTMP.AuthURL = httpServer.URL
+	cfg.RTMP.StreamKey = streamKey
+
+	server := NewServer(cfg)
+	server.ctx = context.Background()
+	return server, recorder
+}
+
+func TestAuthorizePublishSendsPublishedKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		stderr   string
+		wantName string
+	}{
+		{name: "configured key", stderr: "Input #0, flv, from 'rtmp://0.0.0.0:1935/live/obs':", wantName: "obs"},
+		{name: "encoder's own key", stderr: "[rtmp @ 0x1] Unexpected stream secret-key, expecting obs\nInput #0, flv", wantName: "secret-key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := newAuthTestServer(t, http.StatusOK, "obs")
+
+			rtmpDefaults := server.config.GetRTMPDefaults()
+			if !server.authorizePublish(publishKey(tt.stderr, rtmpDefaults)) {
+				t.Fatal("authorizePublish denied a stream auth_url allowed")
+			}
+
+			if len(recorder.forms) != 1 {
+				t.Fatalf("auth_url called %d times, want 1", len(recorder.forms))
+			}
+			want := map[string]string{"call": "publish", "app": "live", "name": tt.wantName}
+			for field, value := range want {
+				if got := recorder.forms[0][field]; got != value {
+					t.Errorf("%s = %q, want %q", field, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizePublishDenies(t *testing.T) {
+	for _, status := range []int{http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError, http.StatusFound} {
+		server, _ := newAuthTestServer(t, status, "obs")
+		if server.authorizePublish("obs") {
+			t.Errorf("authorizePublish allowed a stream when auth_url answered %d", status)
+		}
+	}
+}
+
+func TestAuthorizePublishDeniesUnreachableOrSlow(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Second)
+	}))
+	defer slow.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	for name, authURL := range map[string]string{"slow": slow.URL, "unreachable": unreachable.URL} {
+		cfg := &config.Config{}
+		cfg.RTMP.AuthURL = authURL
+		cfg.RTMP.AuthTimeout = 1
+
+		server := NewServer(cfg)
+		server.ctx = context.Background()
+		if server.authorizePublish("stream") {
+			t.Errorf("%s auth_url: authorizePublish allowed the stream", name)
+		}
+	}
+}
+
+func TestAuthorizePublishWithoutAuthURL(t *testing.T) {
+	server := NewServer(&config.Config{})
+	server.ctx = context.Background()
+	if !server.authorizePublish("stream") {
+		t.Error("authorizePublish denied a stream with no auth_url configured")
+	}
+}
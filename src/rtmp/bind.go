@@ -0,0 +1,149 @@
+package rtmp
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+const (
+	// bindFailureWindow is how soon after launch an FFmpeg exit can be a failed listen
+	bindFailureWindow = 5 * time.Second
+	// maxBindFailures is how many failed listens in a row are retried before giving up
+	maxBindFailures = 6
+	// maxBindBackoff caps the wait between listen attempts
+	maxBindBackoff = time.Minute
+	// stderrTailSize is how much of FFmpeg's stderr is kept to explain an exit
+	stderrTailSize = 8 << 10
+)
+
+// bindErrorMarkers are FFmpeg (and OS) messages for a port it couldn't listen on
+var bindErrorMarkers = []string{
+	"address already in use",
+	"only one usage of each socket address", // Windows WSAEADDRINUSE
+	"cannot assign requested address",
+	"permission denied",
+	"address family not supported", // IPv6 disabled on the host
+}
+
+// tailBuffer keeps the last bytes written to it, so FFmpeg's stderr can be inspected after it
+// exits without holding a whole stream's worth of log output
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > stderrTailSize {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-stderrTailSize:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+// bindError returns FFmpeg's error line if it exited right after launch because it couldn't
+// listen on the RTMP port, or "" for any other exit
+func bindError(startedAt time.Time, stderr string) string {
+	if time.Since(startedAt) > bindFailureWindow {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.ToLower(lines[i])
+		for _, marker := range bindErrorMarkers {
+			if strings.Contains(line, marker) {
+				return strings.TrimSpace(lines[i])
+			}
+		}
+	}
+	return ""
+}
+
+// recordBindFailure counts a failed listen and returns how long to wait before the next attempt,
+// doubling from restart_delay up to a minute. It returns false once maxBindFailures is reached.
+func (s *Server) recordBindFailure(restartDelay time.Duration) (time.Duration, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.bindFailures++
+	if s.bindFailures >= maxBindFailures {
+		return 0, false
+	}
+
+	delay := restartDelay << (s.bindFailures - 1)
+	if delay <= 0 || delay > maxBindBackoff {
+		delay = maxBindBackoff
+	}
+	return delay, true
+}
+
+// resetBindFailures clears the failed listen count once FFmpeg is listening (or restarted by hand)
+func (s *Server) resetBindFailures() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bindFailures = 0
+}
+
+// handleBindFailure logs a failed listen and reports whether the listener should be relaunched
+// after the returned delay
+func (s *Server) handleBindFailure(streamKey, ffmpegError string) (time.Duration, bool) {
+	rtmpDefaults := s.config.GetRTMPDefaults()
+	delay, retry := s.recordBindFailure(rtmpDefaults.RestartDelay)
+	if !retry {
+		logging.Errorf("❌ FFmpeg cannot listen on RTMP port %d - gave up after %d attempts (FFmpeg: %s)",
+			rtmpDefaults.Port, maxBindFailures, ffmpegError)
+		logging.Errorf("💡 %s", bindHint(ffmpegError))
+		return 0, false
+	}
+
+	logging.Warnf("⚠️ FFmpeg could not listen on RTMP port %d for %s (%s) - retrying in %v",
+		rtmpDefaults.Port, streamKey, ffmpegError, delay)
+	return delay, true
+}
+
+// bindHint suggests a fix for the reason FFmpeg couldn't listen
+func bindHint(ffmpegError string) string {
+	ffmpegError = strings.ToLower(ffmpegError)
+	switch {
+	case strings.Contains(ffmpegError, "cannot assign requested address"):
+		return "rtmp.host (or rtmp.interface) is not an address of this machine: use one of its own IPs, or 0.0.0.0 to listen on all of them, then restart gnostream"
+	case strings.Contains(ffmpegError, "address family not supported"):
+		return "IPv6 is disabled on this machine: set rtmp.ipv6 to false and rtmp.host to an IPv4 address (or 0.0.0.0), then restart gnostream"
+	default:
+		return "The port is most likely held by another gnostream instance or a leftover FFmpeg: stop it (or change rtmp.port/rtmp.host), then restart FFmpeg from the dashboard or restart gnostream"
+	}
+}
+
+// describeBindAddress explains the address FFmpeg listens on, which may differ from rtmp.host
+func describeBindAddress(rtmpDefaults *config.RTMPDefaults) string {
+	var notes []string
+	if configured := strings.TrimSpace(rtmpDefaults.ConfiguredHost); configured != "" && configured != rtmpDefaults.Host {
+		notes = append(notes, fmt.Sprintf("rtmp.host %s resolved to %s", configured, rtmpDefaults.Host))
+	}
+	switch rtmpDefaults.Host {
+	case "0.0.0.0":
+		notes = append(notes, "all IPv4 addresses; set rtmp.host to :: for IPv6")
+	case "::":
+		notes = append(notes, "all IPv6 addresses, plus IPv4 where the system allows dual-stack sockets")
+	}
+	if len(notes) == 0 {
+		return net.JoinHostPort(rtmpDefaults.Host, strconv.Itoa(rtmpDefaults.Port))
+	}
+	return fmt.Sprintf("%s (%s)", net.JoinHostPort(rtmpDefaults.Host, strconv.Itoa(rtmpDefaults.Port)), strings.Join(notes, ", "))
+}
@@ -0,0 +1,171 @@
+package rtmp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gnostream/src/logging"
+)
+
+// inputOpenedMarker is how FFmpeg announces an input it has opened; for the listening RTMP input
+// it is printed as soon as an encoder has connected and sent its stream header
+var inputOpenedMarker = []byte("Input #0")
+
+// connectionWatcher scans FFmpeg's stderr for the moment an encoder connects, seconds before the
+// first HLS segment shows up. onConnect runs once, in its own goroutine.
+type connectionWatcher struct {
+	mu        sync.Mutex
+	carry     []byte // End of the previous write, in case the marker is split across writes
+	seen      bool
+	onConnect func()
+}
+
+func (c *connectionWatcher) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seen {
+		return len(p), nil
+	}
+
+	window := append(c.carry, p...)
+	if bytes.Contains(window, inputOpenedMarker) {
+		c.seen = true
+		c.carry = nil
+		go c.onConnect()
+		return len(p), nil
+	}
+
+	if keep := len(inputOpenedMarker) - 1; len(window) > keep {
+		window = window[len(window)-keep:]
+	}
+	c.carry = append(c.carry[:0], window...)
+	return len(p), nil
+}
+
+// recordConnection notes an encoder connecting to the FFmpeg listener cmd, so status and health
+// can tell "connected, no video yet" from "nothing connected"
+func (s *Server) recordConnection(streamKey string, cmd *exec.Cmd, port int) {
+	remoteAddr := rtmpPeerAddress(port)
+
+	s.mutex.Lock()
+	stream, exists := s.activeStreams[streamKey]
+	current := exists && stream.FFmpegCmd == cmd
+	if current {
+		stream.ConnectedAt = time.Now()
+		stream.RemoteAddr = remoteAddr
+	}
+	s.mutex.Unlock()
+
+	if !current {
+		return
+	}
+	if remoteAddr == "" {
+		remoteAddr = "an encoder"
+	}
+	logging.Infof("🎥 Incoming RTMP connection from %s for: %s", remoteAddr, streamKey)
+}
+
+// connectionInfo returns when and from where an encoder connected to cmd, if one did
+func (s *Server) connectionInfo(streamKey string, cmd *exec.Cmd) (time.Time, string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if stream, exists := s.activeStreams[streamKey]; exists && stream.FFmpegCmd == cmd {
+		return stream.ConnectedAt, stream.RemoteAddr
+	}
+	return time.Time{}, ""
+}
+
+// lastStderrLine returns FFmpeg's last non-empty output line, where it explains why it exited
+func lastStderrLine(stderr string) string {
+	lines := strings.FieldsFunc(stderr, func(r rune) bool { return r == '\n' || r == '\r' })
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return "no output"
+}
+
+// rtmpPeerAddress returns the remote address of an established connection to the RTMP port.
+// FFmpeg doesn't log its peer, so this reads the kernel's socket table; it is Linux only and
+// returns "" elsewhere or when no connection is found.
+func rtmpPeerAddress(port int) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	for _, table := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		if addr := establishedPeer(table, port); addr != "" {
+			return addr
+		}
+	}
+	return ""
+}
+
+// establishedPeer scans a /proc/net/tcp style table for an established socket on a local port
+func establishedPeer(table string, port int) string {
+	file, err := os.Open(table)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != "01" { // 01 = ESTABLISHED
+			continue
+		}
+		_, localPort, err := parseProcAddress(fields[1])
+		if err != nil || localPort != port {
+			continue
+		}
+		ip, remotePort, err := parseProcAddress(fields[2])
+		if err != nil {
+			continue
+		}
+		if v4 := ip.To4(); v4 != nil {
+			ip = v4 // Show IPv4-mapped peers of a dual-stack socket as plain IPv4
+		}
+		return net.JoinHostPort(ip.String(), strconv.Itoa(remotePort))
+	}
+	return ""
+}
+
+// parseProcAddress decodes "0100007F:0797": the address is stored as 32-bit words in host
+// (little-endian) byte order, the port as big-endian hex
+func parseProcAddress(value string) (net.IP, int, error) {
+	hexIP, hexPort, found := strings.Cut(value, ":")
+	if !found {
+		return nil, 0, fmt.Errorf("malformed address %q", value)
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("malformed address %q", value)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("malformed port %q", value)
+	}
+
+	ip := make(net.IP, len(raw))
+	for word := 0; word < len(raw); word += 4 {
+		for i := 0; i < 4; i++ {
+			ip[word+i] = raw[word+3-i]
+		}
+	}
+	return ip, int(port), nil
+}
@@ -0,0 +1,129 @@
+//go:build integration
+
+package rtmp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"gnostream/src/config"
+)
+
+// Run with: go test -tags integration ./src/rtmp
+// Needs ffmpeg on PATH; it pushes a synthetic lavfi source through the real RTMP listener.
+
+// freePort returns a TCP port on the loopback interface that nothing is listening on
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// pushTestPattern publishes seconds of a test pattern with a tone to url. The listener may
+// still be starting, so connection failures are retried until the deadline.
+func pushTestPattern(ffmpeg, url string, seconds int, deadline time.Time) error {
+	for {
+		started := time.Now()
+		cmd := exec.Command(ffmpeg, "-hide_banner", "-loglevel", "error", "-re",
+			"-f", "lavfi", "-i", "testsrc=size=320x240:rate=25",
+			"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=48000",
+			"-t", strconv.Itoa(seconds),
+			"-c:v", "libx264", "-preset", "ultrafast", "-g", "25", "-c:a", "aac",
+			"-f", "flv", url)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		// A quick failure means nothing was listening yet; anything longer broke mid-stream
+		if time.Since(started) > 2*time.Second || time.Now().After(deadline) {
+			return fmt.Errorf("publishing the test pattern: %w\n%s", err, output)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func TestRTMPToHLSWithSyntheticSource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping FFmpeg integration test in short mode")
+	}
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not found on PATH")
+	}
+
+	cfg := &config.Config{
+		FFmpeg:     config.FFmpegConfig{Binary: ffmpeg},
+		Storage:    config.StorageConfig{DataDir: t.TempDir()},
+		RTMP:       config.RTMPConfig{Host: "127.0.0.1", Port: freePort(t), IdleTimeout: 5, RestartDelay: 1},
+		StreamInfo: &config.StreamInfo{Title: "Integration test", HLS: config.HLSConfig{SegmentTime: 1}},
+	}
+	outputDir := cfg.GetStreamDefaults().OutputDir
+
+	started := make(chan string, 1)
+	stopped := make(chan string, 1)
+	server := NewServer(cfg)
+	server.SetStreamHandlers(
+		func(streamKey string) { started <- streamKey },
+		func(streamKey string) { stopped <- streamKey },
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serverDone := make(chan error, 1)
+	go func() { serverDone <- server.Start(ctx) }()
+	defer func() {
+		cancel()
+		select {
+		case <-serverDone:
+		case <-time.After(ffmpegStopTimeout + 5*time.Second):
+			t.Error("RTMP server did not stop")
+		}
+	}()
+
+	pushDone := make(chan error, 1)
+	go func() {
+		pushDone <- pushTestPattern(ffmpeg, cfg.GetRTMPDefaults().ListenURL(), 8, time.Now().Add(15*time.Second))
+	}()
+
+	select {
+	case streamKey := <-started:
+		if streamKey != "default" {
+			t.Errorf("onStreamStart got key %q, want default", streamKey)
+		}
+	case err := <-pushDone:
+		t.Fatalf("publisher exited before onStreamStart was called: %v", err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("onStreamStart was not called")
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "output.m3u8")); err != nil {
+		t.Errorf("output.m3u8 missing after the stream started: %v", err)
+	}
+	segments, _ := filepath.Glob(filepath.Join(outputDir, "*.ts"))
+	if len(segments) == 0 {
+		t.Error("no .ts segments were written")
+	}
+
+	if err := <-pushDone; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case streamKey := <-stopped:
+		if streamKey != "default" {
+			t.Errorf("onStreamStop got key %q, want default", streamKey)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("onStreamStop was not called after the publisher disconnected")
+	}
+}
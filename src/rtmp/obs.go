@@ -0,0 +1,63 @@
+package rtmp
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+// logOBSSettings prints the Server and Stream Key values to enter in OBS, since a guessed RTMP URL
+// is the most common reason an encoder can't connect
+func (s *Server) logOBSSettings(rtmpDefaults *config.RTMPDefaults) {
+	serverURL := func(host string) string {
+		return fmt.Sprintf("rtmp://%s/%s", net.JoinHostPort(host, strconv.Itoa(rtmpDefaults.Port)), config.RTMPApp)
+	}
+
+	logging.Infof("📺 ─── OBS settings (Settings → Stream → Service: Custom) ───")
+
+	// A specific bind address is the only one that works; otherwise list the likely candidates
+	switch rtmpDefaults.Host {
+	case "0.0.0.0", "::", "":
+		if lanIP := lanAddress(); lanIP != "" {
+			logging.Infof("📺 Server:     %s", serverURL(lanIP))
+		}
+		logging.Infof("📺 Same machine: %s", serverURL("localhost"))
+	default:
+		logging.Infof("📺 Server:     %s", serverURL(rtmpDefaults.Host))
+	}
+	logging.Infof("📺 Stream Key: %s", rtmpDefaults.StreamKey)
+
+	if external, err := url.Parse(s.config.Server.ExternalURL); err == nil && external.Hostname() != "" {
+		logging.Infof("📺 Over the internet: %s (port %d must reach this machine; HTTP proxies don't forward RTMP)",
+			serverURL(external.Hostname()), rtmpDefaults.Port)
+	}
+
+	switch rtmpDefaults.Host {
+	case "localhost", "127.0.0.1", "::1":
+		logging.Infof("💡 rtmp.host is %s, so only encoders on this machine can connect - set it to 0.0.0.0 to accept OBS on other devices", rtmpDefaults.Host)
+	default:
+		logging.Infof("💡 If OBS runs on another device, use this machine's LAN IP in Server, not localhost")
+	}
+}
+
+// lanAddress returns this machine's first private IPv4 address, or "" if it has none
+func lanAddress() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil && ip.IsPrivate() {
+			return ip.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package rtmp
+
+import (
+	"regexp"
+	"strings"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+var (
+	// appMismatchPattern is FFmpeg's error for an encoder connecting to another RTMP app than the
+	// one it listens on, e.g. "App field don't match up: stream <-> live"
+	appMismatchPattern = regexp.MustCompile(`App field don't match up: (\S*) <-> (\S*)`)
+	// unexpectedKeyPattern is FFmpeg's warning for a stream key other than the one in its URL;
+	// the publish still goes ahead
+	unexpectedKeyPattern = regexp.MustCompile(`Unexpected stream (\S*), expecting (\S*)`)
+)
+
+// appMismatch returns the RTMP app an encoder tried to publish to when FFmpeg turned it away
+// for not matching /live, and false if that isn't why FFmpeg exited
+func appMismatch(stderr string) (string, bool) {
+	matches := appMismatchPattern.FindAllStringSubmatch(stderr, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return matches[len(matches)-1][1], true
+}
+
+// publishedStreamKey returns the stream key the encoder sent when it differs from rtmp.stream_key
+func publishedStreamKey(stderr string) string {
+	matches := unexpectedKeyPattern.FindAllStringSubmatch(stderr, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
+
+// publishKey returns the stream key the encoder is publishing with: the one FFmpeg reported
+// when it differs from rtmp.stream_key, or rtmp.stream_key itself
+func publishKey(stderr string, rtmpDefaults *config.RTMPDefaults) string {
+	if key := strings.TrimSpace(publishedStreamKey(stderr)); key != "" {
+		return key
+	}
+	return rtmpDefaults.StreamKey
+}
+
+// logAppMismatch explains a rejected connection: OBS splits the URL into Server and Stream Key,
+// and everything after the app in Server is sent as part of the app name
+func logAppMismatch(app string, rtmpDefaults *config.RTMPDefaults) {
+	if app == "" {
+		app = "(none)"
+	}
+	logging.Errorf("❌ An encoder connected to RTMP app %q, but gnostream only accepts %q - the connection was dropped", app, config.RTMPApp)
+	logging.Errorf("💡 In OBS set Server to rtmp://<this machine>:%d/%s and Stream Key to %s (the key goes in its own field, not at the end of Server)",
+		rtmpDefaults.Port, config.RTMPApp, rtmpDefaults.StreamKey)
+}
+
+// logPublishedStreamKey notes an encoder using a different stream key, which FFmpeg accepts
+func logPublishedStreamKey(stderr string, rtmpDefaults *config.RTMPDefaults) {
+	key := strings.TrimSpace(publishedStreamKey(stderr))
+	if key == "" {
+		return
+	}
+	logging.Infof("🔑 Encoder is publishing with stream key %q (rtmp.stream_key is %q) - accepted; use rtmp.auth_url to restrict who can publish",
+		key, rtmpDefaults.StreamKey)
+}
@@ -0,0 +1,28 @@
+package rtmp
+
+import (
+	"testing"
+
+	"gnostream/src/config"
+)
+
+func TestPublishKey(t *testing.T) {
+	rtmpDefaults := &config.RTMPDefaults{StreamKey: "stream"}
+
+	tests := []struct {
+		name   string
+		stderr string
+		want   string
+	}{
+		{name: "no output", stderr: "", want: "stream"},
+		{name: "configured key", stderr: "Input #0, flv, from 'rtmp://0.0.0.0:1935/live/stream':", want: "stream"},
+		{name: "other key", stderr: "[rtmp @ 0x55] Unexpected stream abc123, expecting stream\n", want: "abc123"},
+		{name: "last reported key wins", stderr: "Unexpected stream first, expecting stream\nUnexpected stream second, expecting stream\n", want: "second"},
+	}
+
+	for _, tt := range tests {
+		if got := publishKey(tt.stderr, rtmpDefaults); got != tt.want {
+			t.Errorf("%s: publishKey = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -3,15 +3,20 @@ package rtmp
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"gnostream/src/config"
+	"gnostream/src/hls"
+	"gnostream/src/logging"
 )
 
 // Server represents a simple RTMP-like server that uses FFmpeg for RTMP handling
@@ -29,15 +34,46 @@ type Server struct {
 	currentHLSConfig     *config.HLSConfig
 	currentRecordSetting bool
 	configMutex          sync.RWMutex
+
+	stopOnce sync.Once
+
+	bindFailures int // FFmpeg listens in a row that failed on an occupied port (guarded by mutex)
 }
 
 // StreamContext holds information about an active stream
 type StreamContext struct {
-	StreamKey string
-	StartTime time.Time
-	FFmpegCmd *exec.Cmd
+	StreamKey    string
+	StartTime    time.Time // When FFmpeg started listening
+	PublishStart time.Time // When HLS output started flowing (zero until a publisher connects)
+	ConnectedAt  time.Time // When FFmpeg opened the encoder's input (zero until one connects)
+	RemoteAddr   string    // Encoder address, when it could be found
+	FFmpegCmd    *exec.Cmd
+	OutputPath   string
+	done         chan struct{} // Closed once FFmpeg has exited
 }
 
+// StreamStatus is a point-in-time view of one stream key
+type StreamStatus struct {
+	StreamKey       string     `json:"stream_key"`
+	ListeningSince  time.Time  `json:"listening_since"`
+	PublishingSince *time.Time `json:"publishing_since,omitempty"`
+	ConnectedSince  *time.Time `json:"connected_since,omitempty"` // Encoder connected; HLS output may not have started yet
+	RemoteAddr      string     `json:"remote_addr,omitempty"`
+	UptimeSeconds   int64      `json:"uptime_seconds"` // Time since publishing started, 0 while waiting
+	HLSActive       bool       `json:"hls_active"`
+	FFmpegPID       int        `json:"ffmpeg_pid,omitempty"`
+}
+
+// ServerStatus is a point-in-time view of the RTMP server
+type ServerStatus struct {
+	Running   bool           `json:"running"`
+	ListenURL string         `json:"listen_url"`
+	Streams   []StreamStatus `json:"streams"`
+}
+
+// ffmpegStopTimeout is how long FFmpeg gets to finish writing after an interrupt before it is killed
+const ffmpegStopTimeout = 10 * time.Second
+
 // NewServer creates a new RTMP server
 func NewServer(cfg *config.Config) *Server {
 	return &Server{
@@ -57,7 +93,8 @@ func (s *Server) Start(ctx context.Context) error {
 	s.ctx, s.cancel = context.WithCancel(ctx)
 
 	rtmpDefaults := s.config.GetRTMPDefaults()
-	log.Printf("🎬 RTMP server (FFmpeg-based) starting on port %d", rtmpDefaults.Port)
+	logging.Infof("🎬 RTMP server (FFmpeg-based) starting on port %d", rtmpDefaults.Port)
+	s.logOBSSettings(rtmpDefaults)
 
 	// Initialize current settings
 	s.configMutex.Lock()
@@ -78,23 +115,56 @@ func (s *Server) Start(ctx context.Context) error {
 	return s.Stop()
 }
 
-// Stop stops the RTMP server
+// Stop stops the RTMP server, giving each FFmpeg process time to finish its output.
+// It is safe to call more than once.
 func (s *Server) Stop() error {
-	log.Println("🛑 Stopping RTMP server...")
+	s.stopOnce.Do(func() {
+		logging.Infof("🛑 Stopping RTMP server...")
+
+		// Take the streams first so monitor goroutines exit instead of restarting FFmpeg
+		s.mutex.Lock()
+		streamsToStop := s.activeStreams
+		s.activeStreams = make(map[string]*StreamContext)
+		s.mutex.Unlock()
+
+		for streamKey, stream := range streamsToStop {
+			s.stopFFmpegGracefully(streamKey, stream)
+		}
+
+		if s.cancel != nil {
+			s.cancel()
+		}
+	})
+
+	return nil
+}
 
-	if s.cancel != nil {
-		s.cancel()
+// stopFFmpegGracefully interrupts FFmpeg and waits for it to exit, killing it after ffmpegStopTimeout
+func (s *Server) stopFFmpegGracefully(streamKey string, stream *StreamContext) {
+	if stream.FFmpegCmd == nil || stream.FFmpegCmd.Process == nil {
+		return
 	}
 
-	// Stop all active streams
-	s.mutex.Lock()
-	for streamKey, stream := range s.activeStreams {
-		s.stopStreamProcessing(streamKey, stream)
+	logging.Infof("⏹️ Stopping FFmpeg for: %s", streamKey)
+	if err := interruptProcess(stream.FFmpegCmd.Process); err != nil {
+		stream.FFmpegCmd.Process.Kill()
 	}
-	s.activeStreams = make(map[string]*StreamContext)
-	s.mutex.Unlock()
 
-	return nil
+	select {
+	case <-stream.done:
+		logging.Infof("✅ FFmpeg exited cleanly for: %s", streamKey)
+	case <-time.After(ffmpegStopTimeout):
+		logging.Warnf("⚠️ FFmpeg did not exit within %v - killing: %s", ffmpegStopTimeout, streamKey)
+		stream.FFmpegCmd.Process.Kill()
+	}
+}
+
+// interruptProcess asks a process to exit cleanly; Windows has no SIGINT, so it is killed there
+func interruptProcess(process *os.Process) error {
+	if runtime.GOOS == "windows" {
+		return process.Kill()
+	}
+	return process.Signal(os.Interrupt)
 }
 
 
@@ -104,11 +174,11 @@ func (s *Server) startRTMPToHLSConversion(streamKey string) error {
 	defer s.mutex.Unlock()
 
 	if _, exists := s.activeStreams[streamKey]; exists {
-		log.Printf("⚠️ RTMP server already running for stream: %s", streamKey)
+		logging.Warnf("⚠️ RTMP server already running for stream: %s", streamKey)
 		return nil // Stream already processing
 	}
 
-	log.Printf("🎥 Starting RTMP server for stream: %s", streamKey)
+	logging.Infof("🎥 Starting RTMP server for stream: %s", streamKey)
 
 	// Get defaults
 	streamDefaults := s.config.GetStreamDefaults()
@@ -119,73 +189,159 @@ func (s *Server) startRTMPToHLSConversion(streamKey string) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Output path for HLS
-	outputPath := filepath.Join(streamDefaults.OutputDir, "output.m3u8")
-
-	// Use a simple "live" path - no complex stream key needed for personal server
-	rtmpURL := fmt.Sprintf("rtmp://%s:%d/live", rtmpDefaults.Host, rtmpDefaults.Port)
+	// Listen on /live/<stream key>: FFmpeg needs the app (live) to match what the encoder
+	// connects to, while any stream key is accepted
+	rtmpURL := rtmpDefaults.ListenURL()
 	
 	// Check for any stream info changes before starting
 	_, _, err := s.config.CheckAndReloadStreamInfo()
 	if err != nil {
-		log.Printf("Warning: failed to reload stream info: %v", err)
+		logging.Warnf("Warning: failed to reload stream info: %v", err)
 	}
 	
 	// Get HLS config from stream info
 	hlsConfig := s.config.GetHLSConfig()
 
+	// FFmpeg is the RTMP listener, so the input can't be probed up front - multi-track audio
+	// maps the configured number of tracks, which the encoder must send
+	audioTracks := hlsConfig.AudioTrackCount()
+
+	// The ABR ladder is built for abr.max_height; renditions are capped at the input height
+	var ladder []config.Rendition
+	if s.config.ABREnabled(hlsConfig) {
+		ladder = s.config.ABRLadder(0)
+		logging.Infof("📶 ABR ladder: %s (capped at the input resolution)", config.DescribeLadder(ladder))
+	}
+	playlistName, segmentName, initName := hlsConfig.OutputPattern(max(audioTracks, len(ladder)))
+
+	// The media playlist watched for activity (output.m3u8 unless it is a master playlist)
+	outputPath := filepath.Join(streamDefaults.OutputDir, config.MediaPlaylistName(audioTracks))
+	if ladder != nil {
+		outputPath = filepath.Join(streamDefaults.OutputDir, config.ABRMediaPlaylistName(ladder))
+	}
+
 	// Build FFmpeg arguments
 	args := []string{
 		"-f", "flv",
 		"-listen", "1",
 		"-i", rtmpURL,
-		"-c:v", "libx264",
-		"-crf", "18",
-		"-preset", "veryfast",
-		"-c:a", "aac",
-		"-b:a", "160k",
+	}
+	args = append(args, hlsConfig.AudioTrackArgs(audioTracks)...)
+	if ladder != nil {
+		args = append(args, s.config.ABRArgs(ladder)...)
+	}
+	args = append(args, s.config.FFmpeg.EncodeArgs(ladder != nil)...)
+	args = append(args,
 		"-f", "hls",
 		"-hls_time", fmt.Sprintf("%d", hlsConfig.SegmentTime),
+	)
+
+	// fMP4/CMAF segments need an init segment alongside the media segments
+	if hlsConfig.SegmentType == "fmp4" {
+		args = append(args, "-hls_segment_type", "fmp4", "-hls_fmp4_init_filename", initName)
+	}
+
+	// Optional segment naming and absolute segment URIs for proxied/CDN playback
+	if segmentName != "" {
+		args = append(args, "-hls_segment_filename", filepath.Join(streamDefaults.OutputDir, segmentName))
+	}
+	if baseURL := s.config.SegmentBaseURL(hlsConfig); baseURL != "" {
+		args = append(args, "-hls_base_url", baseURL)
 	}
 
 	// Configure HLS behavior based on recording setting
+	var hlsFlags []string
 	if s.config.StreamInfo != nil && s.config.StreamInfo.Record {
 		// Recording enabled: keep all segments, don't delete
 		args = append(args, "-hls_list_size", "0") // 0 = unlimited playlist size
 		// Don't add delete_segments flag - keep all segments for archival
+		if hlsConfig.LowLatency {
+			args = append(args, "-hls_playlist_type", "event")
+		}
 	} else {
-		// Live only: use playlist size limit and delete old segments
-		args = append(args,
-			"-hls_list_size", fmt.Sprintf("%d", hlsConfig.PlaylistSize),
-			"-hls_flags", "delete_segments",
-		)
+		// Live only: use playlist size limit (or DVR window) and delete old segments
+		listSize, deleteThreshold := hlsConfig.LiveRetention()
+		args = append(args, "-hls_list_size", fmt.Sprintf("%d", listSize))
+		if deleteThreshold > 0 {
+			args = append(args, "-hls_delete_threshold", fmt.Sprintf("%d", deleteThreshold))
+		}
+		hlsFlags = append(hlsFlags, "delete_segments")
 	}
 
-	args = append(args, "-y", outputPath)
+	// Low-latency mode: keyframe on every segment boundary so short segments cut exactly
+	if hlsConfig.LowLatency {
+		args = append(args, "-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", hlsConfig.SegmentTime))
+		hlsFlags = append(hlsFlags, "independent_segments", "program_date_time")
+	}
+	if len(hlsFlags) > 0 {
+		args = append(args, "-hls_flags", strings.Join(hlsFlags, "+"))
+	}
 
-	// Start FFmpeg as an RTMP server that accepts connections and converts to HLS
-	cmd := exec.CommandContext(s.ctx, "ffmpeg", args...)
+	if ladder != nil {
+		args = append(args, s.config.FFmpeg.AudioFilterArgs()...)
+	} else {
+		args = append(args, s.config.FFmpeg.FilterArgs()...)
+	}
+	args = append(args, s.config.FFmpeg.ExtraArgs...)
+	args = append(args, "-y", filepath.Join(streamDefaults.OutputDir, playlistName))
+
+	// Start FFmpeg as an RTMP server that accepts connections and converts to HLS.
+	// On cancellation FFmpeg gets an interrupt first so it can finalize the playlist.
+	cmd := exec.CommandContext(s.ctx, s.config.FFmpegBinary(), args...)
+	cmd.Cancel = func() error { return interruptProcess(cmd.Process) }
+	cmd.WaitDelay = ffmpegStopTimeout
+
+	// Keep the end of FFmpeg's output to tell a port that is already taken from other exits
+	stderr := &tailBuffer{}
+	// The publisher is authorized as soon as it connects, before FFmpeg writes any output
+	authorized := make(chan bool, 1)
+	connection := &connectionWatcher{onConnect: func() {
+		s.recordConnection(streamKey, cmd, rtmpDefaults.Port)
+		authorized <- s.authorizePublish(publishKey(stderr.String(), rtmpDefaults))
+	}}
+	cmd.Stderr = io.MultiWriter(stderr, connection)
 	
-	log.Printf("✅ RTMP server listening on %s", rtmpURL)
+	logging.Infof("✅ RTMP server listening on %s, bound to %s", rtmpURL, describeBindAddress(rtmpDefaults))
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start FFmpeg RTMP server: %w", err)
 	}
 
-	log.Printf("✅ FFmpeg RTMP server started, waiting for connection on %s", rtmpURL)
+	logging.Infof("✅ FFmpeg RTMP server started, waiting for connection on %s", rtmpURL)
+
+	// Reap the process so its exit can be observed
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
 
 	// Store stream context
+	startedAt := time.Now()
 	s.activeStreams[streamKey] = &StreamContext{
-		StreamKey: streamKey,
-		StartTime: time.Now(),
-		FFmpegCmd: cmd,
+		StreamKey:  streamKey,
+		StartTime:  startedAt,
+		FFmpegCmd:  cmd,
+		OutputPath: outputPath,
+		done:       done,
+	}
+
+	// A stream whose segment sequence stops advancing for this long is considered stalled
+	stallThreshold := time.Duration(hlsConfig.SegmentTime*3) * time.Second
+	if stallThreshold < 20*time.Second {
+		stallThreshold = 20 * time.Second
 	}
 
 	// Monitor FFmpeg process and HLS output to detect when stream actually starts/stops
 	go func() {
 		streamStarted := false
+		publishAuthorized := false
+		listening := false
 		lastHLSUpdate := time.Time{}
+		lastSequence := -1
+		lastSequenceAdvance := time.Time{}
+		lastPlaylistModTime := time.Time{}
 		ticker := time.NewTicker(2 * time.Second)
 		defer ticker.Stop()
 
@@ -193,14 +349,52 @@ func (s *Server) startRTMPToHLSConversion(streamKey string) error {
 			select {
 			case <-s.ctx.Done():
 				return
+			case allowed := <-authorized:
+				if !s.isCurrentProcess(streamKey, cmd) {
+					return
+				}
+				if !allowed {
+					s.rejectPublish(streamKey, cmd)
+					return
+				}
+				publishAuthorized = true
 			case <-ticker.C:
+				// This FFmpeg was replaced (restart or settings change) - its replacement has its own monitor
+				if !s.isCurrentProcess(streamKey, cmd) {
+					return
+				}
+
 				currentHLSActive := s.hasActiveHLSOutput(outputPath)
 
+				// FFmpeg that outlives the bind window got the port
+				if !listening && time.Since(startedAt) > bindFailureWindow {
+					listening = true
+					s.resetBindFailures()
+				}
+
 				// Check if stream just started
+				if !streamStarted && currentHLSActive && !publishAuthorized {
+					if connectedAt, _ := s.connectionInfo(streamKey, cmd); !connectedAt.IsZero() {
+						continue // The connect-time authorization is still waiting for auth_url
+					}
+					// FFmpeg's connect line was missed, so authorize now instead
+					if !s.authorizePublish(publishKey(stderr.String(), rtmpDefaults)) {
+						s.rejectPublish(streamKey, cmd)
+						return
+					}
+					publishAuthorized = true
+				}
 				if !streamStarted && currentHLSActive {
 					streamStarted = true
 					lastHLSUpdate = time.Now()
-					log.Printf("🔴 RTMP stream connected for: %s", streamKey)
+					s.mutex.Lock()
+					if stream, exists := s.activeStreams[streamKey]; exists && stream.FFmpegCmd == cmd {
+						stream.PublishStart = lastHLSUpdate
+					}
+					s.mutex.Unlock()
+					s.resetBindFailures()
+					logging.Infof("🔴 RTMP stream connected for: %s", streamKey)
+					logPublishedStreamKey(stderr.String(), rtmpDefaults)
 					if s.onStreamStart != nil {
 						go s.onStreamStart(streamKey)
 					}
@@ -211,45 +405,105 @@ func (s *Server) startRTMPToHLSConversion(streamKey string) error {
 					lastHLSUpdate = time.Now()
 				}
 
-				// Check if stream has ended (no HLS updates for 15 seconds)
-				if streamStarted && !currentHLSActive && time.Since(lastHLSUpdate) > 15*time.Second {
-					log.Printf("⚫ RTMP stream ended (no HLS activity): %s", streamKey)
-					if s.onStreamStop != nil {
-						go s.onStreamStop(streamKey)
+				// Check that FFmpeg is still producing new segments, not just touching the playlist
+				if streamStarted && currentHLSActive {
+					playlistModTime := time.Time{}
+					if info, err := os.Stat(outputPath); err == nil {
+						playlistModTime = info.ModTime()
 					}
-					
-					// Force kill FFmpeg first, then restart
-					log.Printf("🔄 Killing FFmpeg and restarting RTMP server for: %s", streamKey)
-					if cmd.Process != nil {
-						cmd.Process.Kill()
+
+					if sequence, ok := hls.ReadLatestSegmentSequence(outputPath); ok && sequence != lastSequence {
+						lastSequence = sequence
+						lastSequenceAdvance = time.Now()
+						lastPlaylistModTime = playlistModTime
+					} else if !lastSequenceAdvance.IsZero() &&
+						playlistModTime.After(lastPlaylistModTime) &&
+						time.Since(lastSequenceAdvance) > stallThreshold {
+						logging.Warnf("🧟 FFmpeg stalled for %s: segment sequence stuck at %d for %v - restarting",
+							streamKey, lastSequence, time.Since(lastSequenceAdvance).Round(time.Second))
+
+						if cmd.Process != nil {
+							cmd.Process.Kill()
+						}
+						s.mutex.RLock()
+						stream := s.activeStreams[streamKey]
+						s.mutex.RUnlock()
+						s.stopStreamProcessing(streamKey, stream) // Notifies the stop handler
+
+						go func() {
+							time.Sleep(rtmpDefaults.RestartDelay) // Ensure port is freed
+							logging.Infof("🔄 Restarting RTMP server for: %s", streamKey)
+							s.startRTMPToHLSConversion(streamKey)
+						}()
+						return
 					}
-					s.stopStreamProcessing(streamKey, s.activeStreams[streamKey])
-					
+				}
+
+				// Check if stream has ended (no HLS updates for the idle timeout)
+				if streamStarted && !currentHLSActive && time.Since(lastHLSUpdate) > rtmpDefaults.IdleTimeout {
+					logging.Infof("⚫ RTMP stream ended (no HLS activity): %s", streamKey)
+
+					// Let FFmpeg close out the playlist before the stop handler archives it,
+					// so the recording is complete when the end event is published
+					logging.Infof("🔄 Stopping FFmpeg and restarting RTMP server for: %s", streamKey)
+					s.mutex.RLock()
+					stream := s.activeStreams[streamKey]
+					s.mutex.RUnlock()
+					if stream != nil {
+						s.stopFFmpegGracefully(streamKey, stream)
+					}
+					s.stopStreamProcessing(streamKey, stream) // Notifies the stop handler
+
 					// Restart RTMP server automatically after a brief delay
 					go func() {
-						time.Sleep(3 * time.Second) // Longer delay to ensure port is freed
-						log.Printf("🔄 Restarting RTMP server for: %s", streamKey)
+						time.Sleep(rtmpDefaults.RestartDelay) // Ensure port is freed
+						logging.Infof("🔄 Restarting RTMP server for: %s", streamKey)
 						s.startRTMPToHLSConversion(streamKey)
 					}()
 					return
 				}
 
 				// Check if FFmpeg process has ended
-				if cmd.ProcessState != nil {
+				processEnded := false
+				select {
+				case <-done:
+					processEnded = true
+				default:
+				}
+				if processEnded {
+					restartDelay := rtmpDefaults.RestartDelay
 					if streamStarted {
-						log.Printf("⚫ RTMP stream ended (FFmpeg stopped): %s", streamKey)
-						if s.onStreamStop != nil {
-							go s.onStreamStop(streamKey)
+						logging.Infof("⚫ RTMP stream ended (FFmpeg stopped): %s", streamKey)
+					} else if ffmpegError := bindError(startedAt, stderr.String()); ffmpegError != "" {
+						// The port is taken: back off instead of relaunching every few seconds
+						delay, retry := s.handleBindFailure(streamKey, ffmpegError)
+						if !retry {
+							s.mutex.Lock()
+							delete(s.activeStreams, streamKey)
+							s.mutex.Unlock()
+							return
+						}
+						restartDelay = delay
+					} else if app, mismatched := appMismatch(stderr.String()); mismatched {
+						logAppMismatch(app, rtmpDefaults)
+					} else if connectedAt, remoteAddr := s.connectionInfo(streamKey, cmd); !connectedAt.IsZero() {
+						if remoteAddr == "" {
+							remoteAddr = "the encoder"
 						}
+						logging.Warnf("⚠️ RTMP connection from %s closed before any video was written (FFmpeg: %s): %s",
+							remoteAddr, lastStderrLine(stderr.String()), streamKey)
 					} else {
-						log.Printf("📡 RTMP server stopped (no stream received): %s", streamKey)
+						logging.Infof("📡 RTMP server stopped (no stream received): %s", streamKey)
 					}
-					s.stopStreamProcessing(streamKey, s.activeStreams[streamKey])
+					s.mutex.RLock()
+					stream := s.activeStreams[streamKey]
+					s.mutex.RUnlock()
+					s.stopStreamProcessing(streamKey, stream) // Notifies the stop handler
 					
 					// Restart RTMP server automatically after a brief delay
 					go func() {
-						log.Printf("🔄 Restarting RTMP server for: %s", streamKey)
-						time.Sleep(2 * time.Second)
+						logging.Infof("🔄 Restarting RTMP server for: %s", streamKey)
+						time.Sleep(restartDelay)
 						s.startRTMPToHLSConversion(streamKey)
 					}()
 					return
@@ -261,6 +515,28 @@ func (s *Server) startRTMPToHLSConversion(streamKey string) error {
 	return nil
 }
 
+// rejectPublish drops a publisher that auth_url denied: FFmpeg is killed before the stream is
+// announced, anything it already wrote is cleared so nothing is served, and the listener is restarted
+func (s *Server) rejectPublish(streamKey string, cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+
+	s.mutex.Lock()
+	if stream, exists := s.activeStreams[streamKey]; exists && stream.FFmpegCmd == cmd {
+		delete(s.activeStreams, streamKey)
+	}
+	s.mutex.Unlock()
+
+	s.clearRejectedOutput()
+
+	go func() {
+		time.Sleep(s.config.GetRTMPDefaults().RestartDelay) // Ensure port is freed
+		logging.Infof("🔄 Restarting RTMP server for: %s", streamKey)
+		s.startRTMPToHLSConversion(streamKey)
+	}()
+}
+
 // hasActiveHLSOutput checks if HLS files are being actively created
 func (s *Server) hasActiveHLSOutput(outputPath string) bool {
 	// Check if the m3u8 file exists and has recent modification time
@@ -271,9 +547,12 @@ func (s *Server) hasActiveHLSOutput(outputPath string) bool {
 		}
 	}
 
-	// Also check for .ts segment files which are created more frequently
+	// Also check for .ts/.m4s segment files which are created more frequently
 	dir := filepath.Dir(outputPath)
-	if files, err := filepath.Glob(filepath.Join(dir, "*.ts")); err == nil && len(files) > 0 {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.ts"))
+	fmp4Files, _ := filepath.Glob(filepath.Join(dir, "*.m4s"))
+	files = append(files, fmp4Files...)
+	if len(files) > 0 {
 		// Check if any .ts file was modified recently
 		for _, file := range files {
 			if info, err := os.Stat(file); err == nil {
@@ -293,12 +572,16 @@ func (s *Server) stopStreamProcessing(streamKey string, stream *StreamContext) {
 		return
 	}
 
-	log.Printf("⏹️ Stopping stream processing for: %s", streamKey)
+	logging.Infof("⏹️ Stopping stream processing for: %s", streamKey)
 
-	// Kill FFmpeg process
-	if stream.FFmpegCmd != nil && stream.FFmpegCmd.Process != nil {
-		if err := stream.FFmpegCmd.Process.Kill(); err != nil {
-			log.Printf("Error killing FFmpeg process: %v", err)
+	// Kill FFmpeg process unless it has already exited
+	select {
+	case <-stream.done:
+	default:
+		if stream.FFmpegCmd != nil && stream.FFmpegCmd.Process != nil {
+			if err := stream.FFmpegCmd.Process.Kill(); err != nil {
+				logging.Errorf("Error killing FFmpeg process: %v", err)
+			}
 		}
 	}
 
@@ -312,7 +595,74 @@ func (s *Server) stopStreamProcessing(streamKey string, stream *StreamContext) {
 		go s.onStreamStop(streamKey)
 	}
 
-	log.Printf("✅ Stream processing stopped for: %s", streamKey)
+	logging.Infof("✅ Stream processing stopped for: %s", streamKey)
+}
+
+// isCurrentProcess reports whether cmd is still the FFmpeg process registered for streamKey
+func (s *Server) isCurrentProcess(streamKey string, cmd *exec.Cmd) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	stream, exists := s.activeStreams[streamKey]
+	return exists && stream.FFmpegCmd == cmd
+}
+
+// RestartFFmpeg kills the running FFmpeg process(es) and relaunches the RTMP listener
+func (s *Server) RestartFFmpeg() error {
+	if s.ctx == nil || s.ctx.Err() != nil {
+		return fmt.Errorf("RTMP server is not running")
+	}
+
+	logging.Infof("🔄 Manual FFmpeg restart requested")
+	s.resetBindFailures()
+
+	s.mutex.Lock()
+	streamsToStop := s.activeStreams
+	s.activeStreams = make(map[string]*StreamContext)
+	s.mutex.Unlock()
+
+	for streamKey, stream := range streamsToStop {
+		if stream.FFmpegCmd != nil && stream.FFmpegCmd.Process != nil {
+			if err := stream.FFmpegCmd.Process.Kill(); err != nil {
+				logging.Errorf("Error killing FFmpeg process for %s: %v", streamKey, err)
+			}
+		}
+		if s.onStreamStop != nil {
+			go s.onStreamStop(streamKey)
+		}
+	}
+
+	time.Sleep(s.config.GetRTMPDefaults().RestartDelay) // Ensure port is freed
+	return s.startRTMPToHLSConversion("default")
+}
+
+// StopStream ends a publishing stream: FFmpeg finishes its output, the stop handler runs and the
+// RTMP listener is relaunched for the next connection
+func (s *Server) StopStream(streamKey string) error {
+	if s.ctx == nil || s.ctx.Err() != nil {
+		return fmt.Errorf("RTMP server is not running")
+	}
+
+	// Take the stream first so its monitor goroutine exits instead of handling the exit itself
+	s.mutex.Lock()
+	stream, exists := s.activeStreams[streamKey]
+	delete(s.activeStreams, streamKey)
+	s.mutex.Unlock()
+
+	if !exists {
+		return fmt.Errorf("stream %s is not active", streamKey)
+	}
+
+	s.stopFFmpegGracefully(streamKey, stream)
+	if s.onStreamStop != nil {
+		go s.onStreamStop(streamKey)
+	}
+
+	go func() {
+		time.Sleep(s.config.GetRTMPDefaults().RestartDelay) // Ensure port is freed
+		logging.Infof("🔄 Restarting RTMP server for: %s", streamKey)
+		s.startRTMPToHLSConversion(streamKey)
+	}()
+	return nil
 }
 
 // GetActiveStreams returns a list of currently active stream keys
@@ -327,6 +677,52 @@ func (s *Server) GetActiveStreams() []string {
 	return keys
 }
 
+// GetStreamStatuses returns the status of every stream key with a running FFmpeg, sorted by key
+func (s *Server) GetStreamStatuses() []StreamStatus {
+	s.mutex.RLock()
+	streams := make([]StreamContext, 0, len(s.activeStreams))
+	for _, stream := range s.activeStreams {
+		streams = append(streams, *stream)
+	}
+	s.mutex.RUnlock()
+
+	statuses := make([]StreamStatus, 0, len(streams))
+	for _, stream := range streams {
+		status := StreamStatus{
+			StreamKey:      stream.StreamKey,
+			ListeningSince: stream.StartTime,
+			HLSActive:      stream.OutputPath != "" && s.hasActiveHLSOutput(stream.OutputPath),
+		}
+		if stream.FFmpegCmd != nil && stream.FFmpegCmd.Process != nil {
+			status.FFmpegPID = stream.FFmpegCmd.Process.Pid
+		}
+		if !stream.ConnectedAt.IsZero() {
+			connectedAt := stream.ConnectedAt
+			status.ConnectedSince = &connectedAt
+			status.RemoteAddr = stream.RemoteAddr
+		}
+		if !stream.PublishStart.IsZero() {
+			publishStart := stream.PublishStart
+			status.PublishingSince = &publishStart
+			status.UptimeSeconds = int64(time.Since(publishStart).Seconds())
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].StreamKey < statuses[j].StreamKey })
+	return statuses
+}
+
+// Status returns the listener address and the status of every stream key
+func (s *Server) Status() ServerStatus {
+	rtmpDefaults := s.config.GetRTMPDefaults()
+	return ServerStatus{
+		Running:   s.ctx != nil && s.ctx.Err() == nil,
+		ListenURL: rtmpDefaults.ListenURL(),
+		Streams:   s.GetStreamStatuses(),
+	}
+}
+
 // IsStreamActive checks if a specific stream is active
 func (s *Server) IsStreamActive(streamKey string) bool {
 	s.mutex.RLock()
@@ -340,16 +736,16 @@ func (s *Server) watchForConfigChanges() {
 	ticker := time.NewTicker(3 * time.Second) // Check every 3 seconds like the stream monitor
 	defer ticker.Stop()
 
-	log.Println("👁️ RTMP config watcher started")
+	logging.Infof("👁️ RTMP config watcher started")
 
 	for {
 		select {
 		case <-s.ctx.Done():
-			log.Println("📁 RTMP config watcher stopping...")
+			logging.Infof("📁 RTMP config watcher stopping...")
 			return
 		case <-ticker.C:
 			if err := s.checkConfigChanges(); err != nil {
-				log.Printf("RTMP config check error: %v", err)
+				logging.Errorf("RTMP config check error: %v", err)
 			}
 		}
 	}
@@ -376,16 +772,14 @@ func (s *Server) checkConfigChanges() error {
 
 	// Compare with current settings
 	s.configMutex.RLock()
-	hlsChanged := s.currentHLSConfig == nil || 
-		s.currentHLSConfig.SegmentTime != newHLSConfig.SegmentTime ||
-		s.currentHLSConfig.PlaylistSize != newHLSConfig.PlaylistSize
+	hlsChanged := s.currentHLSConfig == nil || s.currentHLSConfig.FFmpegSettingsChanged(newHLSConfig)
 	recordChanged := s.currentRecordSetting != newRecordSetting
 	s.configMutex.RUnlock()
 
 	// If HLS or recording settings changed, restart FFmpeg
 	if hlsChanged || recordChanged {
-		log.Printf("🔄 HLS/Recording settings changed - restarting RTMP server...")
-		log.Printf("   HLS: %ds segments, %d playlist size, Record: %t", 
+		logging.Infof("🔄 HLS/Recording settings changed - restarting RTMP server...")
+		logging.Infof("   HLS: %ds segments, %d playlist size, Record: %t", 
 			newHLSConfig.SegmentTime, newHLSConfig.PlaylistSize, newRecordSetting)
 
 		// Update stored settings
@@ -398,7 +792,7 @@ func (s *Server) checkConfigChanges() error {
 		s.mutex.Lock()
 		streamsToStop := make(map[string]*StreamContext)
 		for streamKey, stream := range s.activeStreams {
-			log.Printf("🔄 Restarting FFmpeg for stream: %s", streamKey)
+			logging.Infof("🔄 Restarting FFmpeg for stream: %s", streamKey)
 			streamsToStop[streamKey] = stream
 		}
 		// Clear active streams before releasing lock
@@ -410,7 +804,7 @@ func (s *Server) checkConfigChanges() error {
 			// Kill FFmpeg process directly
 			if stream.FFmpegCmd != nil && stream.FFmpegCmd.Process != nil {
 				if err := stream.FFmpegCmd.Process.Kill(); err != nil {
-					log.Printf("Error killing FFmpeg process for %s: %v", streamKey, err)
+					logging.Errorf("Error killing FFmpeg process for %s: %v", streamKey, err)
 				}
 			}
 			// Notify stream stop
@@ -421,7 +815,7 @@ func (s *Server) checkConfigChanges() error {
 
 		// Start a new RTMP server after brief delay
 		go func() {
-			time.Sleep(2 * time.Second)
+			time.Sleep(s.config.GetRTMPDefaults().RestartDelay)
 			s.startRTMPToHLSConversion("default")
 		}()
 	}
@@ -4,16 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"gnostream/src/config"
+	"gnostream/src/logging"
 	"gnostream/src/nostr"
+	"gnostream/src/util"
 )
 
 // Monitor manages stream monitoring and HLS conversion
@@ -26,20 +28,28 @@ type Monitor struct {
 	mutex        sync.RWMutex
 	isActive     bool
 	streamKey    string // Current active stream key
+	archiveName  string // Archive folder name for the current recording, fixed at stream start
+	inputHealth  *InputHealth // Probe result for the connected stream (nil until checked)
+	broadcasts   sync.WaitGroup // In-flight start/end-event broadcasts and webhooks, drained on shutdown
+	ending       map[string]bool // Dtags whose end event is being published; the keepalive skips them
+	liveEventMu  sync.Mutex      // Orders a keepalive publish before the end event of the same stream
+	stopper      func(streamKey string) error // Ends the active ingest stream (set in RTMP mode)
+
+	// Callbacks notified on status transitions and metadata updates
+	statusListeners []func(metadata config.MetadataSnapshot)
 }
 
-// NewMonitor creates a new stream monitor
-func NewMonitor(cfg *config.Config) (*Monitor, error) {
-	// Initialize Nostr client with integrated config
-	nostrClient, err := nostr.NewClient(&cfg.Nostr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize nostr client: %w", err)
+// NewMonitor creates a new stream monitor that broadcasts through the given Nostr client
+func NewMonitor(cfg *config.Config, nostrClient nostr.Client) (*Monitor, error) {
+	if nostrClient == nil {
+		return nil, fmt.Errorf("nostr client is required")
 	}
 
 	monitor := &Monitor{
 		config:       cfg,
 		streamConfig: cfg.GetStreamDefaults(),
 		nostrClient:  nostrClient,
+		ending:       make(map[string]bool),
 	}
 
 	// Check if there's any existing metadata that indicates a "live" stream that shouldn't be
@@ -57,23 +67,33 @@ func (m *Monitor) cleanupIncorrectLiveEvents() {
 	// NOTE: We no longer delete metadata.json here as users want to access
 	// chat history from ended streams. The metadata file contains valuable
 	// information about the previous stream that should be preserved.
-	log.Printf("📝 Preserving existing stream metadata for chat history access")
+	logging.Debugf("📝 Preserving existing stream metadata for chat history access")
 }
 
 // Start begins monitoring the RTMP stream
 func (m *Monitor) Start(ctx context.Context) error {
-	log.Println("🎬 Stream monitor started")
+	logging.Infof("🎬 Stream monitor started")
 
 	// Start stream info watcher in a separate goroutine
 	go m.watchStreamInfo(ctx)
 
+	// Optionally keep the live event fresh on relays during long streams
+	if m.config.Nostr.RepublishInterval > 0 {
+		go m.republishLiveEvent(ctx)
+	}
+
+	// Optionally end forgotten streams after a maximum duration
+	if m.config.Stream.MaxDuration > 0 {
+		go m.enforceMaxDuration(ctx)
+	}
+
 	// Check if RTMP is enabled - if so, only do file watching, not stream detection
 	rtmpDefaults := m.config.GetRTMPDefaults()
 	if rtmpDefaults.Enabled {
-		log.Println("📡 RTMP mode: Only running file watcher (stream detection handled by RTMP server)")
+		logging.Infof("📡 RTMP mode: Only running file watcher (stream detection handled by RTMP server)")
 		// Just wait for context cancellation - RTMP server handles stream detection
 		<-ctx.Done()
-		log.Println("📁 File watcher stopping...")
+		logging.Infof("📁 File watcher stopping...")
 		return nil
 	}
 
@@ -84,14 +104,14 @@ func (m *Monitor) Start(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("📡 Stream monitor stopping...")
+			logging.Infof("📡 Stream monitor stopping...")
 			if m.isActive {
 				m.stopStream()
 			}
 			return nil
 		case <-ticker.C:
 			if err := m.checkStream(); err != nil {
-				log.Printf("Stream check error: %v", err)
+				logging.Errorf("Stream check error: %v", err)
 			}
 		}
 	}
@@ -106,11 +126,11 @@ func (m *Monitor) checkStream() error {
 
 	if streamActive && !m.isActive {
 		// Stream just started
-		log.Println("🔴 Stream detected - starting HLS conversion")
+		logging.Infof("🔴 Stream detected - starting HLS conversion")
 		return m.startStream()
 	} else if !streamActive && m.isActive {
 		// Stream just stopped
-		log.Println("⚫ Stream stopped - stopping HLS conversion")
+		logging.Infof("⚫ Stream stopped - stopping HLS conversion")
 		return m.stopStream()
 	}
 
@@ -123,26 +143,20 @@ func (m *Monitor) startStream() error {
 	metadata := m.config.GetStreamMetadata()
 
 	// Generate unique stream identifier
-	metadata.Dtag = fmt.Sprintf("%d", rand.Intn(900000)+100000)
+	metadata.Dtag = m.nextDtag()
 	metadata.Status = "live"
 	metadata.Starts = fmt.Sprintf("%d", time.Now().Unix())
 	metadata.Ends = ""
-	// Use external URL if configured, otherwise use localhost
-	baseURL := m.config.Server.ExternalURL
-	if baseURL == "" {
-		baseURL = fmt.Sprintf("http://localhost:%d", m.config.Server.Port)
-	}
-	
-	metadata.StreamURL = fmt.Sprintf("%s/live/output.m3u8", baseURL)
+	metadata.StreamURL = fmt.Sprintf("%s/live/output.m3u8", m.baseURL())
 
-	// Only set recording URL if recording is enabled
+	// The recording URL is only published once the archive has been written (see finalizeRecording),
+	// so clients never follow a recording link that 404s while the stream is live
+	metadata.RecordingURL = ""
 	if m.config.StreamInfo.Record {
-		metadata.RecordingURL = fmt.Sprintf("%s/past-streams/%s-%s/output.m3u8",
-			baseURL,
-			time.Now().Format("1-2-2006"),
-			metadata.Dtag)
+		// Fix the archive directory name now so it matches the stream's start date
+		m.archiveName = archiveDirName(metadata)
 	} else {
-		metadata.RecordingURL = "" // No recording URL when recording disabled
+		m.archiveName = ""
 	}
 
 	m.metadata = metadata
@@ -153,7 +167,7 @@ func (m *Monitor) startStream() error {
 	}
 
 	// Save metadata to JSON
-	metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
+	metadataPath := m.streamConfig.MetadataPath
 	if err := config.SaveStreamMetadata(metadataPath, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
@@ -163,21 +177,31 @@ func (m *Monitor) startStream() error {
 		return fmt.Errorf("failed to start FFmpeg: %w", err)
 	}
 
-	// Broadcast Nostr start event and capture response
+	// Broadcast Nostr start event and capture response. The broadcast gets its own copy: the
+	// metadata keeps changing under the lock while relays are contacted.
+	started := metadata.Clone()
+	m.broadcasts.Add(1)
 	go func() {
-		eventJSON, successfulRelays := m.nostrClient.BroadcastStartEventWithResponse(metadata)
+		defer m.broadcasts.Done()
+		eventJSON, successfulRelays := m.nostrClient.BroadcastStartEventWithResponse(started)
 		m.mutex.Lock()
+		// Skip the bookkeeping if the stream ended or restarted while publishing
+		if m.metadata == nil || m.metadata.Dtag != started.Dtag || m.metadata.Status != "live" {
+			m.mutex.Unlock()
+			return
+		}
 		m.metadata.LastNostrEvent = eventJSON
 		m.metadata.SuccessfulRelays = successfulRelays
+		snapshot := m.metadata.Clone()
 		m.mutex.Unlock()
 
 		// Save updated metadata with Nostr info
-		metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
-		config.SaveStreamMetadata(metadataPath, m.metadata)
+		metadataPath := m.streamConfig.MetadataPath
+		config.SaveStreamMetadata(metadataPath, snapshot)
 	}()
 
 	m.isActive = true
-	log.Println("✅ Stream started successfully")
+	logging.Infof("✅ Stream started successfully")
 	return nil
 }
 
@@ -186,122 +210,264 @@ func (m *Monitor) stopStream() error {
 	if m.ffmpegCmd != nil {
 		// Stop FFmpeg
 		if err := m.ffmpegCmd.Process.Kill(); err != nil {
-			log.Printf("Error killing FFmpeg: %v", err)
+			logging.Errorf("Error killing FFmpeg: %v", err)
 		}
 		m.ffmpegCmd.Wait()
 		m.ffmpegCmd = nil
 	}
 
 	if m.metadata != nil {
+		// The keepalive must not re-publish this stream as live once it is ending
+		m.ending[m.metadata.Dtag] = true
+
 		// Update metadata
 		m.metadata.Status = "ended"
 		m.metadata.Ends = fmt.Sprintf("%d", time.Now().Unix())
 
 		// Save final metadata
-		metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
+		metadataPath := m.streamConfig.MetadataPath
 		config.SaveStreamMetadata(metadataPath, m.metadata)
 
-		// Archive the stream only if recording is enabled
+		// Archive the stream only if recording is enabled. This runs before the end event is
+		// broadcast so the "ended" event carries the final, reachable recording URL.
 		if m.config.StreamInfo.Record {
-			if err := m.archiveStream(); err != nil {
-				log.Printf("Error archiving stream: %v", err)
-			}
+			m.finalizeRecording(metadataPath)
 		} else {
-			log.Println("📡 Recording disabled - skipping archive process")
+			logging.Infof("📡 Recording disabled - skipping archive process")
 		}
 
 		// Broadcast Nostr end event and capture response
+		m.broadcasts.Add(1)
 		go func() {
-			eventJSON, successfulRelays := m.nostrClient.BroadcastEndEventWithResponse(m.metadata)
-			m.mutex.Lock()
-			m.metadata.LastNostrEvent = eventJSON
-			m.metadata.SuccessfulRelays = successfulRelays
-			m.mutex.Unlock()
-
-			// Check if we should send a deletion request for non-recorded streams
-			if m.config.Nostr.DeleteNonRecorded && m.metadata.RecordingURL == "" && eventJSON != "" {
-				// Extract the ID of the end event we just published
-				if endEventID, err := nostr.ExtractEventID(eventJSON); err == nil {
-					log.Printf("🗑️ Stream ended without recording - sending deletion request")
-					deletionJSON, deletionRelays := m.nostrClient.BroadcastDeletionEventWithResponse(
-						endEventID, 
-						"Stream ended without recording - removing temporary live event",
-					)
-					log.Printf("🗑️ Deletion request sent: %s to %d relays", deletionJSON, len(deletionRelays))
-				} else {
-					log.Printf("❌ Failed to extract event ID from end event for deletion: %v", err)
-				}
-			}
-
-			// Save final metadata with Nostr info
-			metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
-			config.SaveStreamMetadata(metadataPath, m.metadata)
+			defer m.broadcasts.Done()
+			m.broadcastEndEvent()
 		}()
 	}
 
 	m.isActive = false
 	if m.config.StreamInfo.Record {
-		log.Println("✅ Stream stopped and archived")
+		logging.Infof("✅ Stream stopped and archived")
 	} else {
-		log.Println("✅ Stream stopped")
+		logging.Infof("✅ Stream stopped")
 	}
 	return nil
 }
 
+// broadcastEndEvent publishes the "ended" event and, for unrecorded streams, optionally deletes it
+func (m *Monitor) broadcastEndEvent() {
+	m.mutex.RLock()
+	ended := m.metadata.Clone()
+	m.mutex.RUnlock()
+
+	// Wait for a keepalive already publishing this stream, so relays get the end event last
+	m.liveEventMu.Lock()
+	eventJSON, successfulRelays := m.nostrClient.BroadcastEndEventWithResponse(ended)
+	m.liveEventMu.Unlock()
+
+	m.mutex.Lock()
+	delete(m.ending, ended.Dtag)
+	var snapshot *config.StreamMetadata
+	// Skip the bookkeeping if another stream started while publishing
+	if m.metadata != nil && m.metadata.Dtag == ended.Dtag {
+		m.metadata.LastNostrEvent = eventJSON
+		m.metadata.SuccessfulRelays = successfulRelays
+		snapshot = m.metadata.Clone()
+	}
+	m.mutex.Unlock()
+
+	// Check if we should send a deletion request for non-recorded streams
+	if m.config.Nostr.DeleteNonRecorded && ended.RecordingURL == "" && eventJSON != "" {
+		// Extract the ID of the end event we just published
+		if endEventID, err := nostr.ExtractEventID(eventJSON); err == nil {
+			logging.Infof("🗑️ Stream ended without recording - sending deletion request")
+			deletionJSON, deletionRelays := m.nostrClient.BroadcastDeletionEventWithResponse(
+				endEventID, 
+				"Stream ended without recording - removing temporary live event",
+			)
+			logging.Infof("🗑️ Deletion request sent: %s to %d relays", deletionJSON, len(deletionRelays))
+		} else {
+			logging.Errorf("❌ Failed to extract event ID from end event for deletion: %v", err)
+		}
+	}
+
+	// Save final metadata with Nostr info
+	if snapshot != nil {
+		metadataPath := m.streamConfig.MetadataPath
+		config.SaveStreamMetadata(metadataPath, snapshot)
+	}
+}
+
+// Shutdown ends a live stream (final "ended" event, archive) and waits for pending
+// Nostr broadcasts to finish, giving up after timeout
+func (m *Monitor) Shutdown(timeout time.Duration) {
+	m.mutex.Lock()
+	if m.isActive {
+		logging.Infof("🛑 Ending live stream before shutdown")
+		if m.streamKey != "" {
+			if err := m.stopStreamsrc(); err != nil {
+				logging.Errorf("Failed to stop stream processing: %v", err)
+			}
+		} else if err := m.stopStream(); err != nil {
+			logging.Errorf("Failed to stop stream: %v", err)
+		}
+		m.isActive = false
+		m.streamKey = ""
+		m.inputHealth = nil
+		m.notifyStatusChange()
+	}
+	m.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.broadcasts.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logging.Infof("✅ Pending Nostr broadcasts flushed")
+	case <-time.After(timeout):
+		logging.Warnf("⚠️ Timed out waiting for Nostr broadcasts to finish")
+	}
+}
+
 // startFFmpeg starts the FFmpeg HLS conversion process
 func (m *Monitor) startFFmpeg() error {
-	outputPath := filepath.Join(m.streamConfig.OutputDir, "output.m3u8")
-
 	// Get HLS config from stream info
 	hlsConfig := m.config.GetHLSConfig()
 
+	// Map every input audio track up to hls.audio_tracks
+	audioTracks := hlsConfig.AudioTrackCount()
+	if audioTracks > 1 {
+		if input, err := probeStreams(m.config.FFprobeBinary(), m.streamConfig.RTMPUrl); err == nil && input.AudioTracks < audioTracks {
+			audioTracks = max(input.AudioTracks, 1)
+		}
+		logging.Infof("🎧 Mapping %d audio track(s) into the HLS output", audioTracks)
+	}
+
+	// Build the ABR ladder from the probed input height, never upscaling
+	var ladder []config.Rendition
+	if m.config.ABREnabled(hlsConfig) {
+		sourceHeight := 0
+		if input, err := probeStreams(m.config.FFprobeBinary(), m.streamConfig.RTMPUrl); err == nil {
+			sourceHeight = input.Height
+		} else {
+			logging.Warnf("⚠️ Could not probe the input for the ABR ladder, capping at abr.max_height: %v", err)
+		}
+		ladder = m.config.ABRLadder(sourceHeight)
+		logging.Infof("📶 ABR ladder for %dp input: %s", sourceHeight, config.DescribeLadder(ladder))
+	}
+	playlistName, segmentName, initName := hlsConfig.OutputPattern(max(audioTracks, len(ladder)))
+
 	// Build FFmpeg arguments
 	args := []string{
 		"-i", m.streamConfig.RTMPUrl,
-		"-c:v", "libx264",
-		"-crf", "18",
-		"-preset", "veryfast",
-		"-c:a", "aac",
-		"-b:a", "160k",
+	}
+	args = append(args, hlsConfig.AudioTrackArgs(audioTracks)...)
+	if ladder != nil {
+		args = append(args, m.config.ABRArgs(ladder)...)
+	}
+	args = append(args, m.config.FFmpeg.EncodeArgs(ladder != nil)...)
+	args = append(args,
 		"-f", "hls",
 		"-hls_time", fmt.Sprintf("%d", hlsConfig.SegmentTime),
+	)
+
+	// fMP4/CMAF segments need an init segment alongside the media segments
+	if hlsConfig.SegmentType == "fmp4" {
+		args = append(args, "-hls_segment_type", "fmp4", "-hls_fmp4_init_filename", initName)
+	}
+
+	// Optional segment naming and absolute segment URIs for proxied/CDN playback
+	if segmentName != "" {
+		args = append(args, "-hls_segment_filename", filepath.Join(m.streamConfig.OutputDir, segmentName))
+	}
+	if baseURL := m.config.SegmentBaseURL(hlsConfig); baseURL != "" {
+		args = append(args, "-hls_base_url", baseURL)
 	}
 
 	// Configure HLS behavior based on recording setting
+	var hlsFlags []string
 	if m.config.StreamInfo.Record {
 		// Recording enabled: keep all segments, don't delete
 		args = append(args, "-hls_list_size", "0") // 0 = unlimited playlist size
 		// Don't add delete_segments flag - keep all segments for archival
+		if hlsConfig.LowLatency {
+			args = append(args, "-hls_playlist_type", "event")
+		}
 	} else {
-		// Live only: use playlist size limit and delete old segments
-		args = append(args,
-			"-hls_list_size", fmt.Sprintf("%d", hlsConfig.PlaylistSize),
-			"-hls_flags", "delete_segments",
-		)
+		// Live only: use playlist size limit (or DVR window) and delete old segments
+		listSize, deleteThreshold := hlsConfig.LiveRetention()
+		args = append(args, "-hls_list_size", fmt.Sprintf("%d", listSize))
+		if deleteThreshold > 0 {
+			args = append(args, "-hls_delete_threshold", fmt.Sprintf("%d", deleteThreshold))
+		}
+		hlsFlags = append(hlsFlags, "delete_segments")
+	}
+
+	// Low-latency mode: keyframe on every segment boundary so short segments cut exactly
+	if hlsConfig.LowLatency {
+		args = append(args, "-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", hlsConfig.SegmentTime))
+		hlsFlags = append(hlsFlags, "independent_segments", "program_date_time")
+	}
+	if len(hlsFlags) > 0 {
+		args = append(args, "-hls_flags", strings.Join(hlsFlags, "+"))
 	}
 
-	args = append(args, outputPath)
-	m.ffmpegCmd = exec.Command("ffmpeg", args...)
+	if ladder != nil {
+		args = append(args, m.config.FFmpeg.AudioFilterArgs()...)
+	} else {
+		args = append(args, m.config.FFmpeg.FilterArgs()...)
+	}
+	args = append(args, m.config.FFmpeg.ExtraArgs...)
+	args = append(args, filepath.Join(m.streamConfig.OutputDir, playlistName))
+	m.ffmpegCmd = exec.Command(m.config.FFmpegBinary(), args...)
 
 	if err := m.ffmpegCmd.Start(); err != nil {
 		return fmt.Errorf("failed to start FFmpeg: %w", err)
 	}
 
-	log.Println("🎥 FFmpeg HLS conversion started")
+	logging.Infof("🎥 FFmpeg HLS conversion started")
 	return nil
 }
 
+// finalizeRecording archives the stream and, once the files are in place, sets the recording URL
+func (m *Monitor) finalizeRecording(metadataPath string) {
+	if err := m.archiveStream(); err != nil {
+		logging.Errorf("Error archiving stream: %v", err)
+		return
+	}
+
+	// Archive is in place - the end event can now point at it
+	m.metadata.RecordingURL = m.recordingURL(m.archiveName)
+	config.SaveStreamMetadata(metadataPath, m.metadata)
+}
+
+// archiveDirName returns the archive folder name for a stream, dated by its start time (now if unknown)
+func archiveDirName(metadata *config.StreamMetadata) string {
+	started := time.Now()
+	if starts, err := strconv.ParseInt(metadata.Starts, 10, 64); err == nil && starts > 0 {
+		started = time.Unix(starts, 0)
+	}
+	return util.ArchiveFolderName(started, metadata.Dtag)
+}
+
+// recordingURL returns the public playlist URL of an archived recording, matching where archiveStream writes it
+func (m *Monitor) recordingURL(archiveName string) string {
+	return fmt.Sprintf("%s%s%s/output.m3u8", m.baseURL(), m.streamConfig.ArchiveRoute, archiveName)
+}
+
 // archiveStream moves stream files to archive directory
 func (m *Monitor) archiveStream() error {
 	if m.metadata == nil {
 		return fmt.Errorf("no metadata available for archiving")
 	}
 
-	// Create archive directory
-	archiveDir := filepath.Join(m.streamConfig.ArchiveDir,
-		fmt.Sprintf("%s-%s",
-			time.Now().Format("1-2-2006"),
-			m.metadata.Dtag))
+	// Create archive directory, using the name fixed at stream start when available
+	if m.archiveName == "" {
+		m.archiveName = archiveDirName(m.metadata)
+	}
+	archiveDir := filepath.Join(m.streamConfig.ArchiveDir, m.archiveName)
 
 	if err := os.MkdirAll(archiveDir, 0755); err != nil {
 		return fmt.Errorf("failed to create archive directory: %w", err)
@@ -314,25 +480,68 @@ func (m *Monitor) archiveStream() error {
 	}
 
 	for _, file := range files {
+		// The archive directory may live inside the output directory
+		if filepath.Clean(file) == m.streamConfig.ArchiveDir {
+			continue
+		}
+
 		fileName := filepath.Base(file)
 		destPath := filepath.Join(archiveDir, fileName)
 
-		if err := os.Rename(file, destPath); err != nil {
-			log.Printf("Failed to move file %s: %v", file, err)
+		if err := util.MoveFile(file, destPath); err != nil {
+			logging.Errorf("Failed to move file %s: %v", file, err)
 		}
 	}
 
-	log.Printf("📁 Stream archived to: %s", archiveDir)
+	// The recording is only usable if its playlist made it into the archive
+	if _, err := os.Stat(filepath.Join(archiveDir, config.MasterPlaylistName)); err != nil {
+		return fmt.Errorf("archived playlist missing: %w", err)
+	}
+
+	// Multi-track audio leaves a master playlist plus one media playlist per rendition
+	playlists, _ := filepath.Glob(filepath.Join(archiveDir, "*.m3u8"))
+	for _, playlistPath := range playlists {
+		m.finalizeArchivedPlaylist(playlistPath)
+	}
+
+	logging.Infof("📁 Stream archived to: %s", archiveDir)
 	return nil
 }
 
+// finalizeArchivedPlaylist makes an archived playlist playable as VOD from its new location
+func (m *Monitor) finalizeArchivedPlaylist(playlistPath string) {
+	data, err := os.ReadFile(playlistPath)
+	if err != nil {
+		logging.Warnf("⚠️ Failed to read archived playlist: %v", err)
+		return
+	}
+	playlist := string(data)
+
+	// Absolute live segment URLs would point back at /live/ - make the archived playlist relative again
+	if baseURL := m.config.SegmentBaseURL(m.config.GetHLSConfig()); baseURL != "" {
+		playlist = strings.ReplaceAll(playlist, baseURL, "")
+	}
+
+	// A killed FFmpeg never writes ENDLIST; without it players treat the VOD as a stalled live stream.
+	// Master playlists list renditions rather than segments and take no ENDLIST.
+	if !strings.Contains(playlist, "#EXT-X-STREAM-INF") && !strings.Contains(playlist, "#EXT-X-ENDLIST") {
+		playlist = strings.TrimRight(playlist, "\n") + "\n#EXT-X-ENDLIST\n"
+	}
+
+	if playlist != string(data) {
+		if err := os.WriteFile(playlistPath, []byte(playlist), 0644); err != nil {
+			logging.Warnf("⚠️ Failed to finalize archived playlist: %v", err)
+		}
+	}
+}
+
 // isStreamActive checks if the RTMP stream is currently active
 func (m *Monitor) isStreamActive() bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx,
-		"ffprobe",
+		m.config.FFprobeBinary(),
 		"-i", m.streamConfig.RTMPUrl,
 		"-show_streams",
 		"-select_streams", "v",
@@ -372,17 +581,28 @@ func (m *Monitor) isStreamActive() bool {
 	return false
 }
 
-// GetCurrentMetadata returns the current stream metadata
-func (m *Monitor) GetCurrentMetadata() *config.StreamMetadata {
+// baseURL returns the public base URL for stream links
+func (m *Monitor) baseURL() string {
+	return m.config.PublicBaseURL()
+}
+
+// GetCurrentMetadata returns a snapshot of the current stream metadata; the monitor's own copy
+// is updated by broadcast goroutines, so it is never handed out
+func (m *Monitor) GetCurrentMetadata() config.MetadataSnapshot {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
+	return m.snapshotLocked()
+}
+
+// snapshotLocked copies the current metadata (offline placeholder if none); m.mutex must be held
+func (m *Monitor) snapshotLocked() config.MetadataSnapshot {
 	if m.metadata != nil {
-		return m.metadata
+		return m.metadata.Snapshot()
 	}
 
 	// Return offline status if no active stream
-	return &config.StreamMetadata{
+	return config.MetadataSnapshot{
 		Status:  "offline",
 		Title:   "Stream Offline",
 		Summary: "The stream is currently offline",
@@ -396,26 +616,80 @@ func (m *Monitor) IsActive() bool {
 	return m.isActive
 }
 
+// validateStreamInput probes the connected stream and warns about missing tracks or odd formats.
+// In RTMP-listener mode FFmpeg owns the socket, so the probe runs against the HLS it produces.
+func (m *Monitor) validateStreamInput() {
+	playlistPath := filepath.Join(m.streamConfig.OutputDir, "output.m3u8")
+
+	health, err := probeStreams(m.config.FFprobeBinary(), playlistPath)
+	if err != nil {
+		logging.Warnf("⚠️ Stream input validation failed: %v", err)
+		return
+	}
+
+	if len(health.Warnings) == 0 {
+		logging.Infof("✅ Stream input OK: %s %dx%d, audio %s", health.VideoCodec, health.Width, health.Height, health.AudioCodec)
+	}
+	for _, warning := range health.Warnings {
+		logging.Warnf("⚠️ Stream input: %s", warning)
+	}
+
+	m.mutex.Lock()
+	if m.isActive {
+		m.inputHealth = health
+	}
+	m.mutex.Unlock()
+}
+
+// GetInputHealth returns the probe result for the current stream, or nil if not checked
+func (m *Monitor) GetInputHealth() *InputHealth {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.inputHealth
+}
+
+// OnStatusChange registers a callback for stream start/stop and metadata updates
+func (m *Monitor) OnStatusChange(listener func(metadata config.MetadataSnapshot)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.statusListeners = append(m.statusListeners, listener)
+}
+
+// notifyStatusChange calls status listeners with the current metadata (caller holds the mutex)
+func (m *Monitor) notifyStatusChange() {
+	metadata := m.snapshotLocked()
+	for _, listener := range m.statusListeners {
+		go listener(metadata)
+	}
+}
+
 // HandleStreamStart handles when an RTMP stream starts
 func (m *Monitor) HandleStreamStart(streamKey string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	if m.isActive {
-		log.Printf("Stream already active, ignoring new stream: %s", streamKey)
+		logging.Infof("Stream already active, ignoring new stream: %s", streamKey)
 		return
 	}
 
-	log.Printf("🔴 RTMP stream started: %s", streamKey)
+	logging.Infof("🔴 RTMP stream started: %s", streamKey)
 	m.streamKey = streamKey
 
 	// Start stream processing
 	if err := m.startStreamsrc(); err != nil {
-		log.Printf("Failed to start stream processing: %v", err)
+		logging.Errorf("Failed to start stream processing: %v", err)
 		return
 	}
 
 	m.isActive = true
+	m.inputHealth = nil
+	m.notifyStatusChange()
+	m.fireWebhook(m.config.Webhooks.OnStart, "stream.start", m.snapshotLocked())
+
+	if m.config.RTMP.ValidateInput {
+		go m.validateStreamInput()
+	}
 }
 
 // HandleStreamStop handles when an RTMP stream stops
@@ -427,15 +701,18 @@ func (m *Monitor) HandleStreamStop(streamKey string) {
 		return
 	}
 
-	log.Printf("⚫ RTMP stream stopped: %s", streamKey)
+	logging.Infof("⚫ RTMP stream stopped: %s", streamKey)
 
 	// Stop stream processing
 	if err := m.stopStreamsrc(); err != nil {
-		log.Printf("Failed to stop stream processing: %v", err)
+		logging.Errorf("Failed to stop stream processing: %v", err)
 	}
+	m.fireWebhook(m.config.Webhooks.OnStop, "stream.stop", m.snapshotLocked())
 
 	m.isActive = false
 	m.streamKey = ""
+	m.inputHealth = nil
+	m.notifyStatusChange()
 }
 
 // startStreamsrc starts stream processing without checking RTMP
@@ -444,107 +721,91 @@ func (m *Monitor) startStreamsrc() error {
 	metadata := m.config.GetStreamMetadata()
 
 	// Generate unique stream identifier
-	metadata.Dtag = fmt.Sprintf("%d", rand.Intn(900000)+100000)
+	metadata.Dtag = m.nextDtag()
 	metadata.Status = "live"
 	metadata.Starts = fmt.Sprintf("%d", time.Now().Unix())
 	metadata.Ends = ""
-	// Use external URL if configured, otherwise use localhost
-	baseURL := m.config.Server.ExternalURL
-	if baseURL == "" {
-		baseURL = fmt.Sprintf("http://localhost:%d", m.config.Server.Port)
-	}
-	
-	metadata.StreamURL = fmt.Sprintf("%s/live/output.m3u8", baseURL)
+	metadata.StreamURL = fmt.Sprintf("%s/live/output.m3u8", m.baseURL())
 
-	// Only set recording URL if recording is enabled
+	// The recording URL is only published once the archive has been written (see finalizeRecording),
+	// so clients never follow a recording link that 404s while the stream is live
+	metadata.RecordingURL = ""
 	if m.config.StreamInfo.Record {
-		// Create archive directory name that will be used later for consistent naming
-		archiveDirName := fmt.Sprintf("%s-%s", time.Now().Format("1-2-2006"), metadata.Dtag)
-		metadata.RecordingURL = fmt.Sprintf("%s/archive/%s/output.m3u8",
-			baseURL,
-			archiveDirName)
+		// Fix the archive directory name now so it matches the stream's start date
+		m.archiveName = archiveDirName(metadata)
 	} else {
-		metadata.RecordingURL = "" // No recording URL when recording disabled
+		m.archiveName = ""
 	}
 
 	m.metadata = metadata
 
 	// Save metadata to JSON
-	metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
+	metadataPath := m.streamConfig.MetadataPath
 	if err := config.SaveStreamMetadata(metadataPath, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
-	// Broadcast Nostr start event and capture response
+	// Broadcast Nostr start event and capture response. The broadcast gets its own copy: the
+	// metadata keeps changing under the lock while relays are contacted.
+	started := metadata.Clone()
+	m.broadcasts.Add(1)
 	go func() {
-		eventJSON, successfulRelays := m.nostrClient.BroadcastStartEventWithResponse(metadata)
+		defer m.broadcasts.Done()
+		eventJSON, successfulRelays := m.nostrClient.BroadcastStartEventWithResponse(started)
 		m.mutex.Lock()
+		// Skip the bookkeeping if the stream ended or restarted while publishing
+		if m.metadata == nil || m.metadata.Dtag != started.Dtag || m.metadata.Status != "live" {
+			m.mutex.Unlock()
+			return
+		}
 		m.metadata.LastNostrEvent = eventJSON
 		m.metadata.SuccessfulRelays = successfulRelays
+		snapshot := m.metadata.Clone()
 		m.mutex.Unlock()
 
 		// Save updated metadata with Nostr info
-		metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
-		config.SaveStreamMetadata(metadataPath, m.metadata)
+		metadataPath := m.streamConfig.MetadataPath
+		config.SaveStreamMetadata(metadataPath, snapshot)
 	}()
 
-	log.Println("✅ Stream started successfully")
+	logging.Infof("✅ Stream started successfully")
 	return nil
 }
 
 // stopStreamsrc stops stream processing without checking RTMP
 func (m *Monitor) stopStreamsrc() error {
 	if m.metadata != nil {
+		// The keepalive must not re-publish this stream as live once it is ending
+		m.ending[m.metadata.Dtag] = true
+
 		// Update metadata
 		m.metadata.Status = "ended"
 		m.metadata.Ends = fmt.Sprintf("%d", time.Now().Unix())
 
 		// Save final metadata
-		metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
+		metadataPath := m.streamConfig.MetadataPath
 		config.SaveStreamMetadata(metadataPath, m.metadata)
 
-		// Archive the stream only if recording is enabled
+		// Archive the stream only if recording is enabled. This runs before the end event is
+		// broadcast so the "ended" event carries the final, reachable recording URL.
 		if m.config.StreamInfo.Record {
-			if err := m.archiveStream(); err != nil {
-				log.Printf("Error archiving stream: %v", err)
-			}
+			m.finalizeRecording(metadataPath)
 		} else {
-			log.Println("📡 Recording disabled - skipping archive process")
+			logging.Infof("📡 Recording disabled - skipping archive process")
 		}
 
 		// Broadcast Nostr end event and capture response
+		m.broadcasts.Add(1)
 		go func() {
-			eventJSON, successfulRelays := m.nostrClient.BroadcastEndEventWithResponse(m.metadata)
-			m.mutex.Lock()
-			m.metadata.LastNostrEvent = eventJSON
-			m.metadata.SuccessfulRelays = successfulRelays
-			m.mutex.Unlock()
-
-			// Check if we should send a deletion request for non-recorded streams
-			if m.config.Nostr.DeleteNonRecorded && m.metadata.RecordingURL == "" && eventJSON != "" {
-				// Extract the ID of the end event we just published
-				if endEventID, err := nostr.ExtractEventID(eventJSON); err == nil {
-					log.Printf("🗑️ Stream ended without recording - sending deletion request")
-					deletionJSON, deletionRelays := m.nostrClient.BroadcastDeletionEventWithResponse(
-						endEventID, 
-						"Stream ended without recording - removing temporary live event",
-					)
-					log.Printf("🗑️ Deletion request sent: %s to %d relays", deletionJSON, len(deletionRelays))
-				} else {
-					log.Printf("❌ Failed to extract event ID from end event for deletion: %v", err)
-				}
-			}
-
-			// Save final metadata with Nostr info
-			metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
-			config.SaveStreamMetadata(metadataPath, m.metadata)
+			defer m.broadcasts.Done()
+			m.broadcastEndEvent()
 		}()
 	}
 
 	if m.config.StreamInfo.Record {
-		log.Println("✅ Stream stopped and archived")
+		logging.Infof("✅ Stream stopped and archived")
 	} else {
-		log.Println("✅ Stream stopped")
+		logging.Infof("✅ Stream stopped")
 	}
 	return nil
 }
@@ -554,16 +815,163 @@ func (m *Monitor) watchStreamInfo(ctx context.Context) {
 	ticker := time.NewTicker(2 * time.Second) // Check every 2 seconds
 	defer ticker.Stop()
 
-	log.Println("👁️ Stream info watcher started")
+	logging.Infof("👁️ Stream info watcher started")
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("📁 Stream info watcher stopping...")
+			logging.Infof("📁 Stream info watcher stopping...")
 			return
 		case <-ticker.C:
 			if err := m.checkStreamInfoChanges(); err != nil {
-				log.Printf("Stream info check error: %v", err)
+				logging.Errorf("Stream info check error: %v", err)
+			}
+		}
+	}
+}
+
+// minRepublishInterval keeps a misconfigured keepalive from flooding relays
+const minRepublishInterval = 60 * time.Second
+
+// republishLiveEvent periodically re-sends the live event with a fresh created_at. The event
+// keeps its d tag, so relays replace the previous version instead of storing a duplicate.
+func (m *Monitor) republishLiveEvent(ctx context.Context) {
+	interval := time.Duration(m.config.Nostr.RepublishInterval) * time.Second
+	if interval < minRepublishInterval {
+		interval = minRepublishInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logging.Infof("♻️ Live event keepalive enabled (every %v)", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mutex.RLock()
+			var live *config.StreamMetadata
+			if m.isActive && m.metadata != nil && m.metadata.Status == "live" && !m.ending[m.metadata.Dtag] {
+				live = m.metadata.Clone()
+				// Registered under the lock, so a shutdown that ends the stream also waits for it
+				m.broadcasts.Add(1)
+			}
+			m.mutex.RUnlock()
+
+			if live == nil {
+				continue
+			}
+
+			m.publishKeepalive(live)
+		}
+	}
+}
+
+// publishKeepalive re-sends the live event unless the stream started ending in the meantime
+func (m *Monitor) publishKeepalive(live *config.StreamMetadata) {
+	defer m.broadcasts.Done()
+
+	m.liveEventMu.Lock()
+	m.mutex.RLock()
+	// The flag is cleared once the end event is out, so an ended stream also has to be checked
+	ending := m.ending[live.Dtag] || m.metadata == nil || m.metadata.Dtag != live.Dtag || m.metadata.Status != "live"
+	m.mutex.RUnlock()
+	if ending {
+		m.liveEventMu.Unlock()
+		logging.Debugf("♻️ Stream %s is ending - skipping live event keepalive", live.Dtag)
+		return
+	}
+	eventJSON, successfulRelays := m.nostrClient.BroadcastUpdateEventWithResponse(live)
+	m.liveEventMu.Unlock()
+
+	if eventJSON == "" {
+		logging.Warnf("⚠️ Live event keepalive failed to publish")
+		return
+	}
+
+	m.mutex.Lock()
+	// Skip the bookkeeping if the stream ended or restarted while publishing
+	if m.metadata == nil || m.metadata.Dtag != live.Dtag || m.metadata.Status != "live" {
+		m.mutex.Unlock()
+		return
+	}
+	m.metadata.LastNostrEvent = eventJSON
+	m.metadata.SuccessfulRelays = successfulRelays
+	snapshot := m.metadata.Clone()
+	m.mutex.Unlock()
+
+	metadataPath := m.streamConfig.MetadataPath
+	config.SaveStreamMetadata(metadataPath, snapshot)
+	logging.Debugf("♻️ Live event refreshed on %d relays", len(successfulRelays))
+}
+
+const (
+	// maxDurationCheckInterval is how often the stream duration limit is checked
+	maxDurationCheckInterval = 30 * time.Second
+	// maxDurationWarning is how long before the limit a warning is logged
+	maxDurationWarning = 5 * time.Minute
+)
+
+// SetStreamStopper sets the function used to end the active stream when it exceeds
+// stream.max_duration
+func (m *Monitor) SetStreamStopper(stop func(streamKey string) error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.stopper = stop
+}
+
+// enforceMaxDuration ends the live stream once it has run longer than stream.max_duration,
+// warning shortly before the limit is reached
+func (m *Monitor) enforceMaxDuration(ctx context.Context) {
+	limit := time.Duration(m.config.Stream.MaxDuration) * time.Second
+	warnAt := limit - maxDurationWarning
+	if warnAt < limit/2 {
+		warnAt = limit / 2
+	}
+
+	ticker := time.NewTicker(maxDurationCheckInterval)
+	defer ticker.Stop()
+
+	logging.Infof("⏱️ Maximum stream duration: %v", limit)
+
+	warnedDtag := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mutex.RLock()
+			var dtag, starts, streamKey string
+			if m.isActive && m.metadata != nil && m.metadata.Status == "live" {
+				dtag, starts, streamKey = m.metadata.Dtag, m.metadata.Starts, m.streamKey
+			}
+			stop := m.stopper
+			m.mutex.RUnlock()
+
+			startUnix, err := strconv.ParseInt(starts, 10, 64)
+			if dtag == "" || err != nil {
+				continue
+			}
+			elapsed := time.Since(time.Unix(startUnix, 0))
+
+			if elapsed >= limit {
+				logging.Warnf("⏱️ Stream %s reached the maximum duration of %v - ending it", dtag, limit)
+				if stop == nil {
+					logging.Warnf("⚠️ No stream stopper available - the stream must be ended at the encoder")
+					continue
+				}
+				if err := stop(streamKey); err != nil {
+					logging.Errorf("Failed to end stream at maximum duration: %v", err)
+				}
+				continue
+			}
+
+			if elapsed >= warnAt && warnedDtag != dtag {
+				warnedDtag = dtag
+				logging.Warnf("⏱️ Stream %s will be ended in %v (maximum duration %v)",
+					dtag, (limit - elapsed).Round(time.Second), limit)
 			}
 		}
 	}
@@ -576,45 +984,59 @@ func (m *Monitor) checkStreamInfoChanges() error {
 		return err
 	}
 
+	if !changed {
+		return nil
+	}
 
 	// Only broadcast update if we have an active stream and the info actually changed
-	if changed && m.isActive && m.metadata != nil {
-		m.mutex.Lock()
-		// Update the current stream metadata with new info
-		newMetadata := m.config.GetStreamMetadata()
-
-		// Preserve runtime fields from existing metadata
-		newMetadata.Dtag = m.metadata.Dtag
-		newMetadata.Status = m.metadata.Status
-		newMetadata.Starts = m.metadata.Starts
-		newMetadata.Ends = m.metadata.Ends
-		newMetadata.StreamURL = m.metadata.StreamURL
-		newMetadata.RecordingURL = m.metadata.RecordingURL
-
-		m.metadata = newMetadata
+	m.mutex.Lock()
+	if !m.isActive || m.metadata == nil {
 		m.mutex.Unlock()
+		return nil
+	}
+	// Update the current stream metadata with new info
+	newMetadata := m.config.GetStreamMetadata()
+
+	// Preserve runtime fields from existing metadata
+	newMetadata.Dtag = m.metadata.Dtag
+	newMetadata.Status = m.metadata.Status
+	newMetadata.Starts = m.metadata.Starts
+	newMetadata.Ends = m.metadata.Ends
+	newMetadata.StreamURL = m.metadata.StreamURL
+	newMetadata.RecordingURL = m.metadata.RecordingURL
+
+	m.metadata = newMetadata
+	m.notifyStatusChange()
+	updated := m.metadata.Clone()
+	m.mutex.Unlock()
+
+	// Save updated metadata to JSON
+	metadataPath := m.streamConfig.MetadataPath
+	if err := config.SaveStreamMetadata(metadataPath, updated); err != nil {
+		logging.Errorf("Failed to save updated metadata: %v", err)
+	}
 
-		// Save updated metadata to JSON
-		metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
-		if err := config.SaveStreamMetadata(metadataPath, m.metadata); err != nil {
-			log.Printf("Failed to save updated metadata: %v", err)
-		}
-
-		// Broadcast update event to Nostr relays and capture response
-		go func() {
-			eventJSON, successfulRelays := m.nostrClient.BroadcastUpdateEventWithResponse(m.metadata)
-			m.mutex.Lock()
-			m.metadata.LastNostrEvent = eventJSON
-			m.metadata.SuccessfulRelays = successfulRelays
+	// Broadcast update event to Nostr relays and capture response
+	m.broadcasts.Add(1)
+	go func() {
+		defer m.broadcasts.Done()
+		eventJSON, successfulRelays := m.nostrClient.BroadcastUpdateEventWithResponse(updated)
+		m.mutex.Lock()
+		// Skip the write-back if the stream ended or restarted while publishing
+		if m.metadata == nil || m.metadata.Dtag != updated.Dtag || m.metadata.Status != "live" {
 			m.mutex.Unlock()
+			return
+		}
+		m.metadata.LastNostrEvent = eventJSON
+		m.metadata.SuccessfulRelays = successfulRelays
+		snapshot := m.metadata.Clone()
+		m.mutex.Unlock()
 
-			// Save updated metadata with Nostr info
-			metadataPath := filepath.Join(m.streamConfig.OutputDir, "metadata.json")
-			config.SaveStreamMetadata(metadataPath, m.metadata)
-		}()
+		// Save updated metadata with Nostr info
+		config.SaveStreamMetadata(metadataPath, snapshot)
+	}()
 
-		log.Println("🔄 Stream info updated and broadcasted to Nostr relays")
-	}
+	logging.Infof("🔄 Stream info updated and broadcasted to Nostr relays")
 
 	return nil
 }
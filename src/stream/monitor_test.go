@@ -0,0 +1,429 @@
+package stream
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostrtypes "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/config"
+	"gnostream/src/nostr"
+)
+
+// fakeClient is a nostr.Client that records what the monitor publishes instead of talking to relays
+type fakeClient struct {
+	mu        sync.Mutex
+	calls     []string                 // Broadcast kinds in order: start, update, end, planned, cancel, delete
+	events    []string                 // Event JSON returned for each call
+	published []*config.StreamMetadata // Copies of the metadata passed to start/update/end
+	deleted   []string                 // Event IDs deletion was requested for
+	nextID    int
+
+	// onBroadcast, if set, runs before each recorded broadcast returns
+	onBroadcast func(call string, metadata *config.StreamMetadata)
+}
+
+func (f *fakeClient) record(call string, metadata *config.StreamMetadata) (string, []string) {
+	if f.onBroadcast != nil {
+		f.onBroadcast(call, metadata)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	eventJSON := fmt.Sprintf(`{"id":"%064x","kind":30311}`, f.nextID)
+	f.calls = append(f.calls, call)
+	f.events = append(f.events, eventJSON)
+	if metadata != nil {
+		f.published = append(f.published, metadata.Clone())
+	}
+	return eventJSON, []string{"wss://relay.test"}
+}
+
+// Event returns the event JSON returned for the i-th recorded call
+func (f *fakeClient) Event(i int) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.events[i]
+}
+
+// Calls returns the broadcast kinds recorded so far
+func (f *fakeClient) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// Published returns the metadata passed to the recorded start/update/end broadcasts
+func (f *fakeClient) Published() []*config.StreamMetadata {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*config.StreamMetadata(nil), f.published...)
+}
+
+// Deleted returns the event IDs deletion was requested for
+func (f *fakeClient) Deleted() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.deleted...)
+}
+
+func (f *fakeClient) BroadcastStartEvent(metadata *config.StreamMetadata) {
+	f.record("start", metadata)
+}
+
+func (f *fakeClient) BroadcastStartEventWithResponse(metadata *config.StreamMetadata) (string, []string) {
+	return f.record("start", metadata)
+}
+
+func (f *fakeClient) BroadcastUpdateEvent(metadata *config.StreamMetadata) {
+	f.record("update", metadata)
+}
+
+func (f *fakeClient) BroadcastUpdateEventWithResponse(metadata *config.StreamMetadata) (string, []string) {
+	return f.record("update", metadata)
+}
+
+func (f *fakeClient) BroadcastEndEvent(metadata *config.StreamMetadata) {
+	f.record("end", metadata)
+}
+
+func (f *fakeClient) BroadcastEndEventWithResponse(metadata *config.StreamMetadata) (string, []string) {
+	return f.record("end", metadata)
+}
+
+func (f *fakeClient) BroadcastPlannedEventWithResponse(metadata *config.StreamMetadata) (string, []string) {
+	return f.record("planned", metadata)
+}
+
+func (f *fakeClient) BroadcastCancelEvent(dtag string) {
+	f.record("cancel", nil)
+}
+
+func (f *fakeClient) BroadcastDeletionEvent(eventID string, reason string) {
+	f.BroadcastDeletionEventWithResponse(eventID, reason)
+}
+
+func (f *fakeClient) BroadcastDeletionEventWithResponse(eventID string, reason string) (string, []string) {
+	f.mu.Lock()
+	f.deleted = append(f.deleted, eventID)
+	f.mu.Unlock()
+	return f.record("delete", nil)
+}
+
+func (f *fakeClient) BroadcastRelayListEventWithResponse() (string, []string) {
+	return f.record("relay-list", nil)
+}
+
+func (f *fakeClient) PublishTestEvent() (string, []nostr.RelayTestResult, error) {
+	return "", nil, nil
+}
+
+func (f *fakeClient) GetMuteList() ([]string, error) { return nil, nil }
+
+func (f *fakeClient) UpdateMuteList(pubkey string, mute bool) (*nostr.MuteListResult, error) {
+	return &nostr.MuteListResult{}, nil
+}
+
+func (f *fakeClient) Subscribe(filters []nostrtypes.Filter, relayHints []string) (*core.Subscription, error) {
+	return nil, fmt.Errorf("not supported by fakeClient")
+}
+
+func (f *fakeClient) GetUserProfile(pubkey string, relayHints []string) (*nostrtypes.Event, error) {
+	return nil, fmt.Errorf("not supported by fakeClient")
+}
+
+func (f *fakeClient) IsEnabled() bool                                    { return true }
+func (f *fakeClient) GetConnectedRelays() []string                       { return []string{"wss://relay.test"} }
+func (f *fakeClient) OnRelaysReconnected(listener func(relays []string)) {}
+func (f *fakeClient) PendingSignatures() []*nostrtypes.Event             { return nil }
+func (f *fakeClient) SubmitSignature(event *nostrtypes.Event) error      { return nil }
+func (f *fakeClient) Close() error                                       { return nil }
+
+// newTestMonitor returns a monitor in RTMP mode whose data lives in a temporary directory.
+// configure, if set, adjusts the config before the monitor is created.
+func newTestMonitor(t *testing.T, configure func(cfg *config.Config)) (*Monitor, *fakeClient) {
+	t.Helper()
+
+	cfg := &config.Config{
+		Server:     config.ServerConfig{Host: "localhost", Port: 8181},
+		Storage:    config.StorageConfig{DataDir: t.TempDir()},
+		StreamInfo: &config.StreamInfo{Title: "Test stream", Tags: []string{"live"}},
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+
+	if err := os.MkdirAll(cfg.GetStreamDefaults().OutputDir, 0755); err != nil {
+		t.Fatalf("creating output directory: %v", err)
+	}
+
+	client := &fakeClient{}
+	monitor, err := NewMonitor(cfg, client)
+	if err != nil {
+		t.Fatalf("NewMonitor: %v", err)
+	}
+	return monitor, client
+}
+
+func TestGetCurrentMetadataConcurrentWithStartStop(t *testing.T) {
+	monitor, _ := newTestMonitor(t, nil)
+
+	const cycles = 20
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < cycles; i++ {
+			monitor.HandleStreamStart("default")
+			monitor.HandleStreamStop("default")
+		}
+	}()
+
+	// Readers modify their snapshots: that must never reach the monitor or other readers
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < cycles*10; i++ {
+				snapshot := monitor.GetCurrentMetadata()
+				snapshot.Tags = append(snapshot.Tags, "reader")
+				snapshot.SuccessfulRelays = append(snapshot.SuccessfulRelays, "wss://reader.test")
+				snapshot.Title = "changed by reader"
+				_ = monitor.IsActive()
+			}
+		}()
+	}
+
+	wg.Wait()
+	monitor.broadcasts.Wait()
+
+	final := monitor.GetCurrentMetadata()
+	if final.Title != "Test stream" {
+		t.Errorf("Title = %q, a reader's change leaked into the monitor", final.Title)
+	}
+	for _, tag := range final.Tags {
+		if tag == "reader" {
+			t.Errorf("Tags = %v, a reader's change leaked into the monitor", final.Tags)
+		}
+	}
+	if final.Status != "ended" {
+		t.Errorf("Status = %q after the last stop, want ended", final.Status)
+	}
+}
+
+func TestStreamStartBroadcastsStartEvent(t *testing.T) {
+	monitor, client := newTestMonitor(t, nil)
+
+	monitor.HandleStreamStart("default")
+	monitor.broadcasts.Wait()
+
+	if calls := client.Calls(); !slices.Equal(calls, []string{"start"}) {
+		t.Fatalf("broadcasts = %v, want [start]", calls)
+	}
+
+	started := client.Published()[0]
+	if started.Status != "live" || started.Title != "Test stream" || started.Dtag == "" {
+		t.Errorf("start event metadata = status %q, title %q, dtag %q; want live, Test stream and a dtag",
+			started.Status, started.Title, started.Dtag)
+	}
+	if started.RecordingURL != "" || started.Ends != "" {
+		t.Errorf("start event has recording %q, ends %q; want neither", started.RecordingURL, started.Ends)
+	}
+
+	current := monitor.GetCurrentMetadata()
+	if current.LastNostrEvent != client.Event(0) {
+		t.Errorf("LastNostrEvent = %q, want the published start event", current.LastNostrEvent)
+	}
+	if !slices.Equal(current.SuccessfulRelays, []string{"wss://relay.test"}) {
+		t.Errorf("SuccessfulRelays = %v, want [wss://relay.test]", current.SuccessfulRelays)
+	}
+}
+
+func TestStoppingRecordedStreamArchivesBeforeEndEvent(t *testing.T) {
+	monitor, client := newTestMonitor(t, func(cfg *config.Config) {
+		cfg.StreamInfo.Record = true
+		cfg.Nostr.DeleteNonRecorded = true // Never applies to recorded streams
+	})
+	defaults := monitor.config.GetStreamDefaults()
+
+	// The end event must only go out once its recording URL resolves
+	archivedAtEnd := ""
+	client.onBroadcast = func(call string, metadata *config.StreamMetadata) {
+		if call != "end" {
+			return
+		}
+		archiveName := path.Base(path.Dir(metadata.RecordingURL))
+		if data, err := os.ReadFile(filepath.Join(defaults.ArchiveDir, archiveName, config.MasterPlaylistName)); err == nil {
+			archivedAtEnd = string(data)
+		}
+	}
+
+	monitor.HandleStreamStart("default")
+	monitor.broadcasts.Wait()
+
+	writeLiveOutput(t, defaults.OutputDir)
+	monitor.HandleStreamStop("default")
+	monitor.broadcasts.Wait()
+
+	if calls := client.Calls(); !slices.Equal(calls, []string{"start", "end"}) {
+		t.Fatalf("broadcasts = %v, want [start end]", calls)
+	}
+
+	ended := client.Published()[1]
+	if ended.Status != "ended" || ended.Ends == "" {
+		t.Errorf("end event metadata = status %q, ends %q; want ended with an end time", ended.Status, ended.Ends)
+	}
+	wantPrefix := "http://localhost:8181" + defaults.ArchiveRoute
+	if !strings.HasPrefix(ended.RecordingURL, wantPrefix) || !strings.HasSuffix(ended.RecordingURL, "/"+config.MasterPlaylistName) {
+		t.Errorf("recording URL = %q, want %s<archive>/%s", ended.RecordingURL, wantPrefix, config.MasterPlaylistName)
+	}
+	if !strings.Contains(archivedAtEnd, "#EXT-X-ENDLIST") {
+		t.Errorf("archived playlist when the end event was sent = %q, want a finalized VOD playlist", archivedAtEnd)
+	}
+	if _, err := os.Stat(filepath.Join(defaults.OutputDir, "output0.ts")); !os.IsNotExist(err) {
+		t.Errorf("live segment still in the output directory after archiving (err %v)", err)
+	}
+}
+
+func TestStoppingUnrecordedStream(t *testing.T) {
+	tests := []struct {
+		name              string
+		deleteNonRecorded bool
+		wantCalls         []string
+	}{
+		{name: "kept", deleteNonRecorded: false, wantCalls: []string{"start", "end"}},
+		{name: "deleted", deleteNonRecorded: true, wantCalls: []string{"start", "end", "delete"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, client := newTestMonitor(t, func(cfg *config.Config) {
+				cfg.Nostr.DeleteNonRecorded = tt.deleteNonRecorded
+			})
+
+			monitor.HandleStreamStart("default")
+			monitor.broadcasts.Wait()
+			monitor.HandleStreamStop("default")
+			monitor.broadcasts.Wait()
+
+			if calls := client.Calls(); !slices.Equal(calls, tt.wantCalls) {
+				t.Fatalf("broadcasts = %v, want %v", calls, tt.wantCalls)
+			}
+			if ended := client.Published()[1]; ended.RecordingURL != "" {
+				t.Errorf("end event recording URL = %q, want none", ended.RecordingURL)
+			}
+
+			if !tt.deleteNonRecorded {
+				return
+			}
+			endEventID, err := nostr.ExtractEventID(client.Event(1))
+			if err != nil {
+				t.Fatalf("end event ID: %v", err)
+			}
+			if deleted := client.Deleted(); !slices.Equal(deleted, []string{endEventID}) {
+				t.Errorf("deleted event IDs = %v, want the end event %s", deleted, endEventID)
+			}
+		})
+	}
+}
+
+func TestStreamStopIgnoresOtherStreamKeys(t *testing.T) {
+	monitor, client := newTestMonitor(t, nil)
+
+	monitor.HandleStreamStart("default")
+	monitor.HandleStreamStop("other")
+	monitor.broadcasts.Wait()
+
+	if !monitor.IsActive() {
+		t.Error("stopping a different stream key ended the active stream")
+	}
+	if calls := client.Calls(); !slices.Equal(calls, []string{"start"}) {
+		t.Errorf("broadcasts = %v, want [start]", calls)
+	}
+}
+
+func TestKeepaliveSkipsEndingStream(t *testing.T) {
+	monitor, client := newTestMonitor(t, nil)
+
+	monitor.HandleStreamStart("default")
+	monitor.broadcasts.Wait()
+
+	// The keepalive picked up the live stream just before it ended
+	monitor.mutex.RLock()
+	live := monitor.metadata.Clone()
+	monitor.mutex.RUnlock()
+
+	monitor.HandleStreamStop("default")
+	monitor.broadcasts.Add(1)
+	monitor.publishKeepalive(live)
+	monitor.broadcasts.Wait()
+
+	if calls := client.Calls(); !slices.Equal(calls, []string{"start", "end"}) {
+		t.Errorf("broadcasts = %v, want [start end] (no live event after the end)", calls)
+	}
+}
+
+func TestEndEventWaitsForKeepalive(t *testing.T) {
+	monitor, client := newTestMonitor(t, nil)
+
+	monitor.HandleStreamStart("default")
+	monitor.broadcasts.Wait()
+
+	// Hold the keepalive publish until the stream has been stopped
+	publishing := make(chan struct{})
+	release := make(chan struct{})
+	client.onBroadcast = func(call string, metadata *config.StreamMetadata) {
+		if call == "update" {
+			close(publishing)
+			<-release
+		}
+	}
+
+	monitor.mutex.RLock()
+	live := monitor.metadata.Clone()
+	monitor.mutex.RUnlock()
+	monitor.broadcasts.Add(1)
+	go monitor.publishKeepalive(live)
+	<-publishing
+
+	monitor.HandleStreamStop("default")
+	time.Sleep(50 * time.Millisecond) // Give the end event a chance to overtake
+	close(release)
+	monitor.broadcasts.Wait()
+
+	if calls := client.Calls(); !slices.Equal(calls, []string{"start", "update", "end"}) {
+		t.Errorf("broadcasts = %v, want [start update end] (end event last)", calls)
+	}
+	if status := monitor.GetCurrentMetadata().Status; status != "ended" {
+		t.Errorf("Status = %q after the keepalive finished, want ended", status)
+	}
+}
+
+// writeLiveOutput leaves a two-segment live playlist in dir, as FFmpeg would mid-stream
+func writeLiveOutput(t *testing.T, dir string) {
+	t.Helper()
+
+	playlist := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:10\n#EXT-X-MEDIA-SEQUENCE:0\n" +
+		"#EXTINF:10.0,\noutput0.ts\n#EXTINF:10.0,\noutput1.ts\n"
+	files := map[string]string{
+		config.MasterPlaylistName: playlist,
+		"output0.ts":              "segment 0",
+		"output1.ts":              "segment 1",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
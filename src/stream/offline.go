@@ -0,0 +1,115 @@
+package stream
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+const (
+	// OfflinePlaylist is the pre-encoded placeholder playlist in StreamDefaults.OfflineDir
+	OfflinePlaylist = "offline.m3u8"
+	// offlineStamp records which source and duration the placeholder was encoded from
+	offlineStamp = "source.txt"
+	// defaultImageDuration is how much of a still image is encoded before it loops
+	defaultImageDuration = 10
+)
+
+// imageExtensions are sources encoded as a still image rather than a video
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".webp": true, ".bmp": true, ".gif": true,
+}
+
+// GenerateOfflinePlaceholder encodes stream.offline.source to a short VOD playlist in OfflineDir,
+// which the web server loops at the live URL while nobody is streaming. It is skipped when the
+// existing encode is from the same source file and duration.
+func GenerateOfflinePlaceholder(cfg *config.Config) error {
+	offline := cfg.Stream.Offline
+	if !offline.Enabled() {
+		return nil
+	}
+
+	info, err := os.Stat(offline.Source)
+	if err != nil {
+		return fmt.Errorf("offline placeholder source: %w", err)
+	}
+
+	outputDir := cfg.GetStreamDefaults().OfflineDir
+	isImage := imageExtensions[strings.ToLower(filepath.Ext(offline.Source))]
+	duration := offline.Duration
+	if duration <= 0 && isImage {
+		duration = defaultImageDuration
+	}
+
+	stamp := fmt.Sprintf("%s|%d|%d|%d\n", offline.Source, info.Size(), info.ModTime().Unix(), duration)
+	if existing, err := os.ReadFile(filepath.Join(outputDir, offlineStamp)); err == nil && string(existing) == stamp {
+		if _, err := os.Stat(filepath.Join(outputDir, OfflinePlaylist)); err == nil {
+			logging.Debugf("📺 Offline placeholder is up to date")
+			return nil
+		}
+	}
+
+	// Start from an empty directory so segments from a longer previous encode don't linger
+	if err := os.RemoveAll(outputDir); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", outputDir, err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputDir, err)
+	}
+
+	var args []string
+	if isImage {
+		args = []string{
+			"-loop", "1", "-framerate", "30", "-i", offline.Source,
+			"-f", "lavfi", "-i", "anullsrc=channel_layout=stereo:sample_rate=48000",
+			"-tune", "stillimage", "-shortest",
+		}
+	} else {
+		args = []string{
+			"-i", offline.Source,
+			"-map", "0:v:0", "-map", "0:a:0?",
+		}
+	}
+	if duration > 0 {
+		args = append(args, "-t", strconv.Itoa(duration))
+	}
+	args = append(args,
+		"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2",
+		"-c:v", "libx264", "-preset", "veryfast", "-pix_fmt", "yuv420p",
+		"-g", "60", "-keyint_min", "60", "-sc_threshold", "0",
+		"-c:a", "aac", "-b:a", "128k", "-ar", "48000",
+		"-f", "hls",
+		"-hls_time", "2",
+		"-hls_list_size", "0",
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outputDir, "offline_%03d.ts"),
+		"-y", filepath.Join(outputDir, OfflinePlaylist),
+	)
+
+	logging.Infof("📺 Encoding offline placeholder from %s...", offline.Source)
+	output, err := exec.Command(cfg.FFmpegBinary(), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg failed to encode offline placeholder: %w\n%s", err, lastLines(string(output), 5))
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, offlineStamp), []byte(stamp), 0644); err != nil {
+		logging.Warnf("⚠️ Failed to record offline placeholder source: %v", err)
+	}
+	logging.Infof("📺 Offline placeholder ready in %s", outputDir)
+	return nil
+}
+
+// lastLines returns the last n lines of FFmpeg output, where its error is
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+	"gnostream/src/nostr"
+)
+
+// SchedulePlannedStream announces an upcoming stream (NIP-53 status "planned") and saves it so
+// the live event published when the stream starts reuses its dtag and replaces the announcement
+func SchedulePlannedStream(cfg *config.Config, client nostr.Client, title, summary string, starts time.Time) (*config.StreamMetadata, error) {
+	if !starts.After(time.Now()) {
+		return nil, fmt.Errorf("scheduled start must be in the future")
+	}
+
+	metadata := cfg.GetStreamMetadata()
+	if title != "" {
+		metadata.Title = title
+	}
+	if summary != "" {
+		metadata.Summary = summary
+	}
+
+	// Re-scheduling keeps the existing dtag so relays replace the old announcement
+	if existing, err := LoadPlannedStream(cfg); err == nil && existing != nil {
+		metadata.Dtag = existing.Dtag
+	} else {
+		metadata.Dtag = newDtag()
+	}
+	metadata.Status = "planned"
+	metadata.Starts = fmt.Sprintf("%d", starts.Unix())
+	metadata.StreamURL = fmt.Sprintf("%s/live/output.m3u8", cfg.PublicBaseURL())
+
+	if client == nil || !client.IsEnabled() {
+		return nil, fmt.Errorf("nostr client is not configured")
+	}
+
+	eventJSON, successfulRelays := client.BroadcastPlannedEventWithResponse(metadata)
+	if eventJSON == "" {
+		return nil, fmt.Errorf("failed to publish planned event")
+	}
+	metadata.LastNostrEvent = eventJSON
+	metadata.SuccessfulRelays = successfulRelays
+
+	plannedPath := cfg.GetStreamDefaults().PlannedPath
+	if err := os.MkdirAll(filepath.Dir(plannedPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to save planned stream: %w", err)
+	}
+	if err := config.SaveStreamMetadata(plannedPath, metadata); err != nil {
+		return nil, fmt.Errorf("failed to save planned stream: %w", err)
+	}
+
+	logging.Infof("📅 Planned stream %s announced for %s to %d relays",
+		metadata.Dtag, starts.Format(time.RFC1123), len(successfulRelays))
+	return metadata, nil
+}
+
+// ParsePlannedStart parses a scheduled start time given as unix seconds, RFC 3339 or
+// "YYYY-MM-DD HH:MM" in local time
+func ParsePlannedStart(value string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid start time %q (use unix seconds, RFC 3339 or \"YYYY-MM-DD HH:MM\")", value)
+}
+
+// LoadPlannedStream returns the scheduled stream, or nil if none is planned
+func LoadPlannedStream(cfg *config.Config) (*config.StreamMetadata, error) {
+	path := cfg.GetStreamDefaults().PlannedPath
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return config.LoadStreamMetadata(path)
+}
+
+// ClearPlannedStream forgets the scheduled stream
+func ClearPlannedStream(cfg *config.Config) error {
+	err := os.Remove(cfg.GetStreamDefaults().PlannedPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// nextDtag returns the dtag for a stream going live: a planned stream's dtag (consuming the
+// schedule) so the live event updates the announcement, otherwise a fresh one
+func (m *Monitor) nextDtag() string {
+	planned, err := LoadPlannedStream(m.config)
+	if err != nil {
+		logging.Warnf("⚠️ Ignoring unreadable planned stream: %v", err)
+	}
+	if planned == nil || planned.Dtag == "" {
+		return newDtag()
+	}
+
+	if err := ClearPlannedStream(m.config); err != nil {
+		logging.Warnf("⚠️ Failed to clear planned stream: %v", err)
+	}
+	logging.Infof("📅 Going live as planned stream %s", planned.Dtag)
+	return planned.Dtag
+}
+
+// newDtag generates a random stream identifier
+func newDtag() string {
+	return fmt.Sprintf("%d", rand.Intn(900000)+100000)
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// InputHealth describes the media tracks found when a stream connects
+type InputHealth struct {
+	HasVideo    bool      `json:"has_video"`
+	HasAudio    bool      `json:"has_audio"`
+	VideoCodec  string    `json:"video_codec,omitempty"`
+	AudioCodec  string    `json:"audio_codec,omitempty"`
+	AudioTracks int       `json:"audio_tracks"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	Warnings    []string  `json:"warnings"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// hlsVideoCodecs and hlsAudioCodecs are codecs HLS players handle without re-encoding
+var hlsVideoCodecs = map[string]bool{"h264": true, "hevc": true}
+var hlsAudioCodecs = map[string]bool{"aac": true, "mp3": true, "ac3": true, "eac3": true}
+
+// probeStreams runs ffprobe against a media URL or playlist and summarizes its tracks
+func probeStreams(ffprobe, input string) (*InputHealth, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx,
+		ffprobe,
+		"-v", "quiet",
+		"-show_entries", "stream=codec_type,codec_name,width,height",
+		"-of", "json",
+		input,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	health := &InputHealth{CheckedAt: time.Now()}
+	for _, s := range result.Streams {
+		switch s.CodecType {
+		case "video":
+			if !health.HasVideo {
+				health.HasVideo = true
+				health.VideoCodec = s.CodecName
+				health.Width = s.Width
+				health.Height = s.Height
+			}
+		case "audio":
+			health.AudioTracks++
+			if !health.HasAudio {
+				health.HasAudio = true
+				health.AudioCodec = s.CodecName
+			}
+		}
+	}
+
+	health.Warnings = validateInput(health)
+	return health, nil
+}
+
+// validateInput returns human-readable warnings for a probed input
+func validateInput(h *InputHealth) []string {
+	warnings := []string{}
+
+	if !h.HasVideo {
+		warnings = append(warnings, "connected but no video track")
+	} else {
+		if !hlsVideoCodecs[h.VideoCodec] {
+			warnings = append(warnings, fmt.Sprintf("video codec %q is not HLS-compatible without re-encoding", h.VideoCodec))
+		}
+		if h.Width < 320 || h.Height < 180 || h.Width > 3840 || h.Height > 2160 {
+			warnings = append(warnings, fmt.Sprintf("unusual resolution %dx%d", h.Width, h.Height))
+		} else if h.Width%2 != 0 || h.Height%2 != 0 {
+			warnings = append(warnings, fmt.Sprintf("odd resolution %dx%d may fail to encode", h.Width, h.Height))
+		}
+	}
+
+	if !h.HasAudio {
+		warnings = append(warnings, "no audio track")
+	} else if !hlsAudioCodecs[h.AudioCodec] {
+		warnings = append(warnings, fmt.Sprintf("audio codec %q is not HLS-compatible without re-encoding", h.AudioCodec))
+	}
+
+	return warnings
+}
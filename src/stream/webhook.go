@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+const (
+	// webhookTimeout bounds each delivery attempt
+	webhookTimeout = 5 * time.Second
+	// webhookAttempts is how many times a webhook is tried before giving up
+	webhookAttempts = 3
+	// webhookRetryDelay is the wait before the first retry, doubled after each failure
+	webhookRetryDelay = 2 * time.Second
+
+	// WebhookSignatureHeader carries "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"
+	WebhookSignatureHeader = "X-Gnostream-Signature"
+	// WebhookTimestampHeader carries the unix time the request was signed at
+	WebhookTimestampHeader = "X-Gnostream-Timestamp"
+)
+
+// WebhookPayload is the JSON body POSTed to webhook URLs
+type WebhookPayload struct {
+	Event     string                  `json:"event"`     // "stream.start" or "stream.stop"
+	Timestamp int64                   `json:"timestamp"` // Unix seconds when the event fired
+	Stream    config.MetadataSnapshot `json:"stream"`
+}
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// fireWebhook delivers a stream event to url in the background. Delivery is best-effort:
+// failures are retried a couple of times and then logged. Shutdown waits for pending deliveries.
+func (m *Monitor) fireWebhook(url, event string, metadata config.MetadataSnapshot) {
+	if url == "" {
+		return
+	}
+
+	payload := WebhookPayload{Event: event, Timestamp: time.Now().Unix(), Stream: metadata}
+	m.broadcasts.Add(1)
+	go func() {
+		defer m.broadcasts.Done()
+		if err := deliverWebhook(url, m.config.Webhooks.Secret, payload); err != nil {
+			logging.Warnf("⚠️ Webhook %s to %s failed: %v", event, url, err)
+			return
+		}
+		logging.Infof("🪝 Webhook %s delivered to %s", event, url)
+	}()
+}
+
+// deliverWebhook POSTs the payload, retrying on network errors and non-2xx responses
+func deliverWebhook(url, secret string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		err = postWebhook(url, secret, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		logging.Debugf("🪝 Webhook attempt %d/%d failed, retrying in %v: %v", attempt, webhookAttempts, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postWebhook makes a single delivery attempt, signed when a secret is configured. Each attempt
+// is signed with a fresh timestamp so receivers can reject old (replayed) requests.
+func postWebhook(url, secret string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gnostream-webhook")
+	if secret != "" {
+		timestamp := fmt.Sprintf("%d", time.Now().Unix())
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, body))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the signature header value for a webhook body: "sha256=" followed by the
+// hex HMAC-SHA256, keyed with secret, of the timestamp, a ".", and the raw body
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
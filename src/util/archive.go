@@ -0,0 +1,65 @@
+package util
+
+import (
+	"regexp"
+	"time"
+)
+
+// ArchiveDateLayout is the date format used at the start of archive folder names
+const ArchiveDateLayout = "1-2-2006"
+
+// archiveNamePattern matches archive folder names: <M-D-YYYY>-<dtag>
+var archiveNamePattern = regexp.MustCompile(`^\d{1,2}-\d{1,2}-\d{4}-[0-9A-Za-z-]+$`)
+
+// unsafeDtagChars matches anything not allowed in the dtag part of a folder name
+var unsafeDtagChars = regexp.MustCompile(`[^0-9A-Za-z-]`)
+
+// ArchiveLocation is the timezone archive folder dates are written in. UTC keeps the name the
+// same wherever the server or CLI runs; folders from older versions used the server's local time.
+var ArchiveLocation = time.UTC
+
+// ArchiveFolderName builds the archive folder name for a stream: <M-D-YYYY>-<dtag>, dated in
+// ArchiveLocation. Characters outside [0-9A-Za-z-] are stripped from the dtag so the name can
+// never contain path elements.
+func ArchiveFolderName(date time.Time, dtag string) string {
+	return date.In(ArchiveLocation).Format(ArchiveDateLayout) + "-" + ArchiveDtag(dtag)
+}
+
+// ArchiveDtag returns dtag as it appears in an archive folder name
+func ArchiveDtag(dtag string) string {
+	safeDtag := unsafeDtagChars.ReplaceAllString(dtag, "")
+	if safeDtag == "" {
+		safeDtag = "stream"
+	}
+	return safeDtag
+}
+
+// IsArchiveName reports whether name is a well-formed archive folder name
+func IsArchiveName(name string) bool {
+	return archiveNamePattern.MatchString(name)
+}
+
+// ParseArchiveName splits an archive folder name into its date and dtag
+func ParseArchiveName(name string) (time.Time, string, bool) {
+	if !IsArchiveName(name) {
+		return time.Time{}, "", false
+	}
+
+	// The date has exactly three dash-separated parts; the rest is the dtag
+	dashes := 0
+	for i, c := range name {
+		if c != '-' {
+			continue
+		}
+		dashes++
+		if dashes == 3 {
+			date, err := time.Parse(ArchiveDateLayout, name[:i])
+			if err != nil {
+				return time.Time{}, "", false
+			}
+			return date, name[i+1:], true
+		}
+	}
+
+	return time.Time{}, "", false
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveDtag(t *testing.T) {
+	tests := []struct {
+		dtag string
+		want string
+	}{
+		{"my-stream-123", "my-stream-123"},
+		{"../../etc/passwd", "etcpasswd"},
+		{"..%2f..%2fconfig", "2f2fconfig"},
+		{`a\b/c`, "abc"},
+		{"stream name with spaces", "streamnamewithspaces"},
+		{"ünïcode", "ncode"},
+		{"../..", "stream"},
+		{"", "stream"},
+	}
+
+	for _, tt := range tests {
+		if got := ArchiveDtag(tt.dtag); got != tt.want {
+			t.Errorf("ArchiveDtag(%q) = %q, want %q", tt.dtag, got, tt.want)
+		}
+	}
+}
+
+func TestArchiveFolderNameIsAlwaysValid(t *testing.T) {
+	date := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+	for _, dtag := range []string{"stream", "../../escape", "a/b", "%2e%2e", ""} {
+		name := ArchiveFolderName(date, dtag)
+		if !IsArchiveName(name) {
+			t.Errorf("ArchiveFolderName(%q) = %q, not a valid archive name", dtag, name)
+		}
+
+		parsed, parsedDtag, ok := ParseArchiveName(name)
+		if !ok || !parsed.Equal(time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)) || parsedDtag != ArchiveDtag(dtag) {
+			t.Errorf("ParseArchiveName(%q) = %v, %q, %t", name, parsed, parsedDtag, ok)
+		}
+	}
+}
+
+func TestIsArchiveNameRejectsTraversal(t *testing.T) {
+	for _, name := range []string{"..", "1-2-2026-..", "1-2-2026-a/b", "1-2-2026-a\\b", "../1-2-2026-stream", "1-2-2026-"} {
+		if IsArchiveName(name) {
+			t.Errorf("IsArchiveName(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestArchiveFolderNameUsesUTC(t *testing.T) {
+	newYork := time.FixedZone("UTC-5", -5*60*60)
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+
+	tests := []struct {
+		name    string
+		started time.Time
+		want    string
+	}{
+		// 11:55 PM on March 7 in UTC-5 is already March 8 in UTC
+		{name: "late evening west of UTC", started: time.Date(2026, 3, 7, 23, 55, 0, 0, newYork), want: "3-8-2026-stream"},
+		// 8:30 AM on March 8 in UTC+9 is still March 7 in UTC
+		{name: "morning east of UTC", started: time.Date(2026, 3, 8, 8, 30, 0, 0, tokyo), want: "3-7-2026-stream"},
+		{name: "same day", started: time.Date(2026, 3, 7, 12, 0, 0, 0, newYork), want: "3-7-2026-stream"},
+		{name: "year boundary", started: time.Date(2025, 12, 31, 22, 0, 0, 0, newYork), want: "1-1-2026-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ArchiveFolderName(tt.started, "stream"); got != tt.want {
+				t.Errorf("ArchiveFolderName(%v) = %q, want %q", tt.started, got, tt.want)
+			}
+			// The same instant names the same folder whatever zone it is expressed in
+			if got := ArchiveFolderName(tt.started.UTC(), "stream"); got != tt.want {
+				t.Errorf("ArchiveFolderName(%v in UTC) = %q, want %q", tt.started, got, tt.want)
+			}
+		})
+	}
+}
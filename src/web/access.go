@@ -0,0 +1,116 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gnostream/src/analytics"
+)
+
+// accessHandler enforces access.allowlist/denylist on the watch routes (live HLS, archive files
+// and watch pages) and, with access.api, on /api/ and /ws/. Other pages and static assets stay
+// reachable so a blocked viewer still gets the site's error handling.
+func (s *Server) accessHandler(next http.Handler) http.Handler {
+	rules, err := s.config.Access.IPRules()
+	if err != nil {
+		log.Printf("⚠️ Ignoring invalid access entries: %v", err)
+	}
+	if rules.DeniesAll() {
+		log.Printf("🚫 access.allowlist has no valid entries - denying every client until it is fixed")
+	}
+	if !rules.Active() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.accessRestricted(r.URL.Path) {
+			if ip := s.getClientIP(r); !rules.Allows(ip) {
+				log.Printf("🚫 Access denied for %s: %s", ip, r.URL.Path)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// accessRestricted reports whether a path is covered by the access lists
+func (s *Server) accessRestricted(path string) bool {
+	prefixes := []string{"/live/", s.config.GetStreamDefaults().ArchiveRoute, legacyArchiveRoute}
+	if s.config.Access.API {
+		prefixes = append(prefixes, "/api/", "/ws/")
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessTokenCookie carries a viewer's access token, so the player's segment requests (which
+// don't repeat the playlist's query string) are authorized too
+const accessTokenCookie = "gnostream_token"
+
+// tokenHandler enforces access.require_token on HLS files under /live/ and the archive routes
+// and on recording downloads.
+// A valid ?token= on any page is stored in a cookie, so a shared link like /?token=... lets the
+// web player load the stream.
+func (s *Server) tokenHandler(next http.Handler) http.Handler {
+	if !s.config.Access.RequireToken {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token != "" {
+			if expires, err := s.config.Access.VerifyToken(token, time.Now()); err == nil {
+				s.setAccessTokenCookie(w, r, token, expires)
+			}
+		} else if cookie, err := r.Cookie(accessTokenCookie); err == nil {
+			token = cookie.Value
+		}
+
+		if s.tokenRestricted(r) {
+			if _, err := s.config.Access.VerifyToken(token, time.Now()); err != nil {
+				http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenRestricted reports whether a request fetches stream media that needs a token: HLS files
+// under /live/ and the archive routes, or a recording downloaded as MP4
+func (s *Server) tokenRestricted(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/archives/") && strings.HasSuffix(r.URL.Path, "/download") {
+		return true
+	}
+	if !analytics.IsHLSRequest(r) {
+		return false
+	}
+
+	for _, prefix := range []string{"/live/", s.config.GetStreamDefaults().ArchiveRoute, legacyArchiveRoute} {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAccessTokenCookie stores a verified token until it expires
+func (s *Server) setAccessTokenCookie(w http.ResponseWriter, r *http.Request, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     accessTokenCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,207 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"gnostream/src/analytics"
+	"gnostream/src/config"
+)
+
+func TestAccessHandlerIgnoresUntrustedForwardedFor(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Access.Denylist = []string{"203.0.113.0/24"}
+
+	tests := []struct {
+		name       string
+		trusted    []netip.Prefix
+		remoteAddr string
+		xff        string
+		want       int
+	}{
+		{name: "denied peer", remoteAddr: "203.0.113.7:5000", want: http.StatusForbidden},
+		{name: "denied peer spoofing another IP", remoteAddr: "203.0.113.7:5000", xff: "198.51.100.1", want: http.StatusForbidden},
+		{name: "allowed peer spoofing a denied IP", remoteAddr: "198.51.100.1:5000", xff: "203.0.113.7", want: http.StatusOK},
+		{name: "trusted proxy forwarding a denied IP", trusted: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, remoteAddr: "10.0.0.2:5000", xff: "203.0.113.7", want: http.StatusForbidden},
+		{name: "trusted proxy forwarding an allowed IP", trusted: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, remoteAddr: "10.0.0.2:5000", xff: "198.51.100.1", want: http.StatusOK},
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{config: cfg, trustedProxies: tt.trusted}
+			handler := server.accessHandler(ok)
+
+			req := httptest.NewRequest(http.MethodGet, "/live/output.m3u8", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("GET from %s (X-Forwarded-For %q) = %d, want %d", tt.remoteAddr, tt.xff, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessHandlerInvalidAllowlistDeniesAll(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Access.Allowlist = []string{"192.168.1.0/33"}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := (&Server{config: cfg}).accessHandler(ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/live/output.m3u8", nil)
+	req.RemoteAddr = "192.168.1.20:5000"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("GET with an unparseable allowlist = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestHLSBlocklistUsesTrustedClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tracker := analytics.NewViewerTracker(trusted)
+	defer tracker.Stop()
+	blocklist, err := analytics.NewIPBlocklist("")
+	if err != nil {
+		t.Fatalf("NewIPBlocklist: %v", err)
+	}
+
+	server := &Server{config: &config.Config{}, viewerTracker: tracker, blocklist: blocklist, trustedProxies: trusted}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := server.hlsTrackingHandler(ok)
+
+	get := func(remoteAddr, xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/live/output.m3u8", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("User-Agent", "test-player")
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A viewer behind the trusted proxy is tracked, and blocked, by their own IP
+	if code := get("10.0.0.2:5000", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first request = %d, want 200", code)
+	}
+	sessions := tracker.GetMetrics().Sessions
+	if len(sessions) != 1 || sessions[0].IPAddress != "198.51.100.1" {
+		t.Fatalf("sessions = %+v, want one for 198.51.100.1", sessions)
+	}
+	if _, err := blocklist.Block(sessions[0]); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       int
+	}{
+		{name: "blocked viewer through the proxy", remoteAddr: "10.0.0.2:5000", xff: "198.51.100.1", want: http.StatusForbidden},
+		{name: "blocked viewer direct", remoteAddr: "198.51.100.1:6000", want: http.StatusForbidden},
+		{name: "blocked viewer spoofing another IP", remoteAddr: "198.51.100.1:6000", xff: "192.0.2.50", want: http.StatusForbidden},
+		{name: "other viewer spoofing the blocked IP", remoteAddr: "192.0.2.50:6000", xff: "198.51.100.1", want: http.StatusOK},
+		{name: "other viewer through the proxy", remoteAddr: "10.0.0.2:5000", xff: "192.0.2.50", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := get(tt.remoteAddr, tt.xff); code != tt.want {
+			t.Errorf("%s: GET = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+}
+
+func TestHLSTrackingCountsSegmentBytes(t *testing.T) {
+	tracker := analytics.NewViewerTracker(nil)
+	defer tracker.Stop()
+	blocklist, err := analytics.NewIPBlocklist("")
+	if err != nil {
+		t.Fatalf("NewIPBlocklist: %v", err)
+	}
+
+	server := &Server{config: &config.Config{}, viewerTracker: tracker, blocklist: blocklist}
+	body := strings.Repeat("x", 1500)
+	handler := server.hlsTrackingHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+
+	for _, path := range []string{"/live/output.m3u8", "/live/segment001.ts", "/live/segment002.ts"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "198.51.100.1:5000"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	sessions := tracker.GetMetrics().Sessions
+	if len(sessions) != 1 {
+		t.Fatalf("sessions = %d, want 1", len(sessions))
+	}
+	if got := sessions[0].SegmentBytes; got != 3000 {
+		t.Errorf("segment bytes = %d, want 3000", got)
+	}
+}
+
+func TestTokenHandler(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Access.RequireToken = true
+	cfg.Access.TokenSecret = "test-secret"
+	valid := cfg.Access.MintToken(time.Now().Add(time.Hour))
+	expired := cfg.Access.MintToken(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		cookie string
+		want   int
+	}{
+		{name: "live playlist without token", path: "/live/output.m3u8", want: http.StatusForbidden},
+		{name: "live segment without token", path: "/live/output12.ts", want: http.StatusForbidden},
+		{name: "live playlist with token", path: "/live/output.m3u8", token: valid, want: http.StatusOK},
+		{name: "segment with token cookie", path: "/live/output12.ts", cookie: valid, want: http.StatusOK},
+		{name: "expired token", path: "/live/output.m3u8", token: expired, want: http.StatusForbidden},
+		{name: "archive playlist without token", path: "/media/archive/1-2-2026-stream/output.m3u8", want: http.StatusForbidden},
+		{name: "legacy archive segment without token", path: "/archive/1-2-2026-stream/output0.ts", want: http.StatusForbidden},
+		{name: "download without token", path: "/api/archives/1-2-2026-stream/download", want: http.StatusForbidden},
+		{name: "download with token", path: "/api/archives/1-2-2026-stream/download", token: valid, want: http.StatusOK},
+		{name: "download with token cookie", path: "/api/archives/1-2-2026-stream/download", cookie: valid, want: http.StatusOK},
+		{name: "archive metadata", path: "/api/archives/1-2-2026-stream", want: http.StatusOK},
+		{name: "watch page", path: "/archive/1-2-2026-stream", want: http.StatusOK},
+		{name: "home page", path: "/", want: http.StatusOK},
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := (&Server{config: cfg}).tokenHandler(ok)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.path
+			if tt.token != "" {
+				target += "?token=" + tt.token
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: accessTokenCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
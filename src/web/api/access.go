@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gnostream/src/config"
+)
+
+const (
+	// defaultTokenTTL is how long a minted access token lasts when no ttl is given
+	defaultTokenTTL = 24 * time.Hour
+	// maxTokenTTL caps token lifetimes, as a token can't be revoked short of changing the secret
+	maxTokenTTL = 365 * 24 * time.Hour
+)
+
+// AccessTokenRequest asks for an access token lasting TTL seconds (default one day)
+type AccessTokenRequest struct {
+	TTL int64 `json:"ttl"`
+}
+
+// AccessTokenResponse carries a minted access token and a shareable watch link
+type AccessTokenResponse struct {
+	Success   bool      `json:"success"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	URL       string    `json:"url"`
+}
+
+// AccessAPI mints access tokens for token-gated streams
+type AccessAPI struct {
+	config *config.Config
+}
+
+// NewAccessAPI creates a new access API handler
+func NewAccessAPI(cfg *config.Config) *AccessAPI {
+	return &AccessAPI{config: cfg}
+}
+
+// HandleToken mints a signed access token for the HLS paths (POST /api/access/token, owner only)
+func (api *AccessAPI) HandleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if !isOwnerRequest(api.config, r) {
+		writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "Only the server owner can create access tokens")
+		return
+	}
+
+	if !api.config.Access.RequireToken {
+		writeAPIError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "access.require_token is not enabled")
+		return
+	}
+
+	var req AccessTokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
+			return
+		}
+	}
+
+	ttl := defaultTokenTTL
+	if req.TTL < 0 {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "ttl must be positive")
+		return
+	}
+	if req.TTL > 0 {
+		ttl = time.Duration(min(req.TTL, int64(maxTokenTTL/time.Second))) * time.Second
+	}
+
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	token := api.config.Access.MintToken(expires)
+	log.Printf("🎟️ Access token minted, valid until %s", expires.UTC().Format(time.RFC3339))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AccessTokenResponse{
+		Success:   true,
+		Token:     token,
+		ExpiresAt: expires,
+		URL:       api.config.PublicBaseURL() + "/?token=" + url.QueryEscape(token),
+	})
+}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0ceanslim/grain/client/session"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+	"gnostream/src/nostr"
+	"gnostream/src/util"
+)
+
+// ArchiveAPI handles archive management (server owner only) and recording downloads
+type ArchiveAPI struct {
+	config      *config.Config
+	nostrClient nostr.Client
+
+	// MP4 exports being generated, keyed by archive name; closed when done
+	mp4Jobs  map[string]chan struct{}
+	mp4Mutex sync.Mutex
+}
+
+// archiveMP4Name is the cached single-file export inside an archive folder
+const archiveMP4Name = "recording.mp4"
+
+// mp4ExportTimeout bounds a single MP4 export
+const mp4ExportTimeout = 30 * time.Minute
+
+// NewArchiveAPI creates a new archive API handler
+func NewArchiveAPI(cfg *config.Config, nostrClient nostr.Client) *ArchiveAPI {
+	return &ArchiveAPI{config: cfg, nostrClient: nostrClient, mp4Jobs: make(map[string]chan struct{})}
+}
+
+// ArchiveInfo describes one archived recording
+type ArchiveInfo struct {
+	Name      string    `json:"name"`
+	Dtag      string    `json:"dtag"`
+	Date      string    `json:"date"`
+	Title     string    `json:"title,omitempty"`
+	Size      int64     `json:"size"`
+	SizeHuman string    `json:"size_human"`
+	Modified  time.Time `json:"modified"`
+}
+
+// ArchiveListResponse represents the response for listing archives
+type ArchiveListResponse struct {
+	Success   bool          `json:"success"`
+	Archives  []ArchiveInfo `json:"archives"`
+	TotalSize int64         `json:"total_size"`
+}
+
+// ArchiveUpdateRequest corrects an archive's metadata; omitted fields are left unchanged
+type ArchiveUpdateRequest struct {
+	Title       *string   `json:"title,omitempty"`
+	Summary     *string   `json:"summary,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Rebroadcast bool      `json:"rebroadcast"` // Re-publish the ended NIP-53 event with the corrected details
+}
+
+// ArchiveUpdateResponse represents the response for updating an archive
+type ArchiveUpdateResponse struct {
+	Success          bool                   `json:"success"`
+	Name             string                 `json:"name"`
+	Metadata         *config.StreamMetadata `json:"metadata"`
+	EventID          string                 `json:"event_id,omitempty"`
+	SuccessfulRelays []string               `json:"successful_relays,omitempty"`
+}
+
+// ArchiveDeleteResponse represents the response for deleting an archive
+type ArchiveDeleteResponse struct {
+	Success         bool   `json:"success"`
+	Name            string `json:"name"`
+	FreedBytes      int64  `json:"freed_bytes"`
+	FreedBytesHuman string `json:"freed_bytes_human"`
+}
+
+// HandleArchives lists archives (GET /api/archives)
+func (api *ArchiveAPI) HandleArchives(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if !api.requireOwner(w, r) {
+		return
+	}
+
+	archiveDir := api.config.GetStreamDefaults().ArchiveDir
+	entries, err := os.ReadDir(archiveDir)
+	if err != nil && !os.IsNotExist(err) {
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to read archive directory")
+		return
+	}
+
+	response := ArchiveListResponse{Success: true, Archives: []ArchiveInfo{}}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		date, dtag, ok := util.ParseArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(archiveDir, entry.Name())
+		size, _ := util.DirSize(path)
+
+		archive := ArchiveInfo{
+			Name:      entry.Name(),
+			Dtag:      dtag,
+			Date:      date.Format("2006-01-02"),
+			Size:      size,
+			SizeHuman: util.FormatBytes(size),
+		}
+		if info, err := entry.Info(); err == nil {
+			archive.Modified = info.ModTime()
+		}
+		if metadata, err := config.LoadStreamMetadata(filepath.Join(path, config.MetadataFileName)); err == nil {
+			archive.Title = metadata.Title
+		}
+
+		response.Archives = append(response.Archives, archive)
+		response.TotalSize += size
+	}
+
+	// Newest first
+	sort.Slice(response.Archives, func(i, j int) bool {
+		return response.Archives[i].Modified.After(response.Archives[j].Modified)
+	})
+
+	api.sendJSONResponse(w, response, http.StatusOK)
+}
+
+// HandleArchive deletes a single archive (DELETE /api/archives/{date-dtag}), corrects its
+// metadata (PATCH) or downloads it as one MP4 file (GET /api/archives/{date-dtag}/download)
+func (api *ArchiveAPI) HandleArchive(w http.ResponseWriter, r *http.Request) {
+	if name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/archives/"), "/download"); ok {
+		api.handleDownload(w, r, name)
+		return
+	}
+
+	if r.Method == http.MethodPatch {
+		api.handleUpdate(w, r, strings.TrimPrefix(r.URL.Path, "/api/archives/"))
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if !api.requireOwner(w, r) {
+		return
+	}
+
+	// Only well-formed archive names are accepted, so the path can't escape the archive directory
+	name := strings.TrimPrefix(r.URL.Path, "/api/archives/")
+	if !util.IsArchiveName(name) {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid archive name")
+		return
+	}
+
+	path := filepath.Join(api.config.GetStreamDefaults().ArchiveDir, name)
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Archive not found")
+		return
+	}
+
+	size, _ := util.DirSize(path)
+	if err := os.RemoveAll(path); err != nil {
+		logging.Errorf("❌ Failed to delete archive %s: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to delete archive")
+		return
+	}
+
+	logging.Infof("🗑️ Archive deleted: %s (%s freed)", name, util.FormatBytes(size))
+
+	api.sendJSONResponse(w, ArchiveDeleteResponse{
+		Success:         true,
+		Name:            name,
+		FreedBytes:      size,
+		FreedBytesHuman: util.FormatBytes(size),
+	}, http.StatusOK)
+}
+
+// handleUpdate rewrites the title, summary or tags in an archive's metadata.json and optionally
+// re-publishes the ended live event under the same dtag, so directories show the correction
+func (api *ArchiveAPI) handleUpdate(w http.ResponseWriter, r *http.Request, name string) {
+	if !api.requireOwner(w, r) {
+		return
+	}
+
+	folderDate, folderDtag, ok := util.ParseArchiveName(name)
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid archive name")
+		return
+	}
+
+	var req ArchiveUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
+		return
+	}
+	if req.Title == nil && req.Summary == nil && req.Tags == nil && !req.Rebroadcast {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Nothing to update")
+		return
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Title cannot be empty")
+		return
+	}
+
+	metadataPath := filepath.Join(api.config.GetStreamDefaults().ArchiveDir, name, config.MetadataFileName)
+	metadata, err := config.LoadStreamMetadata(metadataPath)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Archive not found or has no metadata")
+		return
+	}
+
+	// The folder must belong to the stream the metadata describes, or the re-published event
+	// would overwrite a different stream's event
+	if metadata.Dtag == "" || util.ArchiveDtag(metadata.Dtag) != folderDtag {
+		writeAPIError(w, http.StatusConflict, ErrCodeInvalidRequest, "Archive metadata does not match the archive name")
+		return
+	}
+
+	if req.Title != nil {
+		metadata.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Summary != nil {
+		metadata.Summary = *req.Summary
+	}
+	if req.Tags != nil {
+		tags := []string{}
+		for _, tag := range *req.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		metadata.Tags = tags
+	}
+
+	response := ArchiveUpdateResponse{Success: true, Name: name, Metadata: metadata}
+
+	if req.Rebroadcast {
+		if api.nostrClient == nil || !api.nostrClient.IsEnabled() {
+			writeAPIError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "Nostr keys are not configured")
+			return
+		}
+
+		// Older archives saved their metadata before the recording URL was known
+		if metadata.RecordingURL == "" {
+			metadata.RecordingURL = api.config.PublicBaseURL() + api.config.GetStreamDefaults().ArchiveRoute + name + "/" + config.MasterPlaylistName
+		}
+		if metadata.Ends == "" {
+			metadata.Ends = fmt.Sprintf("%d", folderDate.Unix())
+		}
+		metadata.Status = "ended"
+
+		eventJSON, successfulRelays := api.nostrClient.BroadcastEndEventWithResponse(metadata)
+		if eventJSON == "" {
+			writeAPIError(w, http.StatusBadGateway, ErrCodeUnavailable, "Failed to publish the updated event")
+			return
+		}
+		metadata.LastNostrEvent = eventJSON
+		metadata.SuccessfulRelays = successfulRelays
+		response.EventID, _ = nostr.ExtractEventID(eventJSON)
+		response.SuccessfulRelays = successfulRelays
+	}
+
+	if err := config.SaveStreamMetadata(metadataPath, metadata); err != nil {
+		logging.Errorf("❌ Failed to save archive metadata for %s: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to save archive metadata")
+		return
+	}
+
+	logging.Infof("✏️ Archive %s updated (title: %q, rebroadcast: %t)", name, metadata.Title, req.Rebroadcast)
+	api.sendJSONResponse(w, response, http.StatusOK)
+}
+
+// handleDownload serves an archive as a single MP4, remuxing the HLS recording on first request
+func (api *ArchiveAPI) handleDownload(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if !util.IsArchiveName(name) {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid archive name")
+		return
+	}
+
+	archiveDir := filepath.Join(api.config.GetStreamDefaults().ArchiveDir, name)
+	if _, err := os.Stat(filepath.Join(archiveDir, config.MasterPlaylistName)); err != nil {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Archive not found")
+		return
+	}
+
+	mp4Path, err := api.ensureMP4(name, archiveDir)
+	if err != nil {
+		logging.Errorf("❌ Failed to export archive %s to MP4: %v", name, err)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to generate MP4")
+		return
+	}
+
+	file, err := os.Open(mp4Path)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to open MP4")
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to open MP4")
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.mp4"`, name))
+	http.ServeContent(w, r, name+".mp4", info.ModTime(), file)
+}
+
+// ensureMP4 returns the cached MP4 export for an archive, generating it if needed. Concurrent
+// requests for the same archive wait for a single FFmpeg run instead of starting their own.
+func (api *ArchiveAPI) ensureMP4(name, archiveDir string) (string, error) {
+	mp4Path := filepath.Join(archiveDir, archiveMP4Name)
+
+	for {
+		if _, err := os.Stat(mp4Path); err == nil {
+			return mp4Path, nil
+		}
+
+		api.mp4Mutex.Lock()
+		if job, running := api.mp4Jobs[name]; running {
+			api.mp4Mutex.Unlock()
+			<-job
+			// The other request either produced the file or failed; a later request can retry
+			if _, err := os.Stat(mp4Path); err == nil {
+				return mp4Path, nil
+			}
+			return "", fmt.Errorf("export by a concurrent request failed")
+		}
+		job := make(chan struct{})
+		api.mp4Jobs[name] = job
+		api.mp4Mutex.Unlock()
+
+		err := api.exportMP4(archiveDir, mp4Path)
+
+		api.mp4Mutex.Lock()
+		delete(api.mp4Jobs, name)
+		api.mp4Mutex.Unlock()
+		close(job)
+
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// exportMP4 remuxes the archived HLS playlist into an MP4 without re-encoding
+func (api *ArchiveAPI) exportMP4(archiveDir, mp4Path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mp4ExportTimeout)
+	defer cancel()
+
+	logging.Infof("🎞️ Exporting %s to MP4...", filepath.Base(archiveDir))
+
+	// Write to a temporary name so a partial file is never served
+	tmpPath := mp4Path + ".part"
+	cmd := exec.CommandContext(ctx, api.config.FFmpegBinary(),
+		"-y",
+		"-i", filepath.Join(archiveDir, config.MasterPlaylistName),
+		"-map", "0:v:0?",
+		"-map", "0:a?", // Keeps every rendition of a multi-track audio recording
+		"-c", "copy",
+		"-bsf:a", "aac_adtstoasc",
+		"-movflags", "+faststart",
+		"-f", "mp4",
+		tmpPath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("ffmpeg: %w: %s", err, lastLines(string(output), 3))
+	}
+
+	if err := os.Rename(tmpPath, mp4Path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	logging.Infof("✅ MP4 export ready: %s", mp4Path)
+	return nil
+}
+
+// lastLines returns the last n non-empty lines of s (FFmpeg puts the actual error at the end)
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, " | ")
+}
+
+// requireOwner writes an error and returns false unless the request comes from the server owner
+func (api *ArchiveAPI) requireOwner(w http.ResponseWriter, r *http.Request) bool {
+	if !session.IsSessionManagerInitialized() {
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Session manager not initialized")
+		return false
+	}
+
+	userSession := session.SessionMgr.GetCurrentUser(r)
+	if userSession == nil || !isServerOwner(api.config, userSession.PublicKey) {
+		writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "Only the server owner can manage archives")
+		return false
+	}
+
+	return true
+}
+
+func (api *ArchiveAPI) sendJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/0ceanslim/grain/client/core/tools"
@@ -15,11 +17,29 @@ import (
 	"strings"
 
 	"gnostream/src/config"
+	gnostr "gnostream/src/nostr"
 )
 
+// hostProfileTTL is how long the streamer's profile is cached
+const hostProfileTTL = 10 * time.Minute
+
 // AuthAPI handles authentication and session management
 type AuthAPI struct {
 	config *config.Config
+
+	// Cached profile of the stream host (server owner)
+	hostProfile        *UserProfile
+	hostProfileFetched time.Time
+	hostProfileMux     sync.Mutex
+}
+
+// HostProfileResponse represents the stream host's profile
+type HostProfileResponse struct {
+	Success   bool         `json:"success"`
+	PublicKey string       `json:"public_key,omitempty"`
+	NPub      string       `json:"npub,omitempty"`
+	Profile   *UserProfile `json:"profile,omitempty"`
+	Error     string       `json:"error,omitempty"`
 }
 
 // NewAuthAPI creates a new authentication API handler
@@ -78,19 +98,19 @@ type UserProfile struct {
 // HandleLogin handles user login/authentication
 func (api *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
 		return
 	}
 
-	// Validate the request
+	// Validate the request (also normalizes an npub/nprofile public key to hex)
 	if err := api.validateLoginRequest(&req); err != nil {
-		api.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
 		return
 	}
 
@@ -104,7 +124,7 @@ func (api *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	switch req.SigningMethod {
 	case "browser_extension":
 		if req.PublicKey == "" {
-			api.sendErrorResponse(w, "Public key required for browser extension signing", http.StatusBadRequest)
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Public key required for browser extension signing")
 			return
 		}
 		sessionReq.PublicKey = req.PublicKey
@@ -118,26 +138,26 @@ func (api *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
 			// Handle both nsec and hex format
 			if strings.HasPrefix(req.PrivateKey, "nsec") {
 				// Decode nsec to get hex private key
-				privateKeyHex, err = tools.DecodeNsec(req.PrivateKey)
+				privateKeyHex, err = gnostr.DecodeNsec(req.PrivateKey)
 				if err != nil {
-					api.sendErrorResponse(w, fmt.Sprintf("Invalid nsec format: %v", err), http.StatusBadRequest)
+					writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, fmt.Sprintf("Invalid nsec format: %v", err))
 					return
 				}
 			} else if len(req.PrivateKey) == 64 {
 				// Assume it's already hex format
 				if matched, _ := regexp.MatchString("^[0-9a-fA-F]{64}$", req.PrivateKey); !matched {
-					api.sendErrorResponse(w, "Invalid hex private key format", http.StatusBadRequest)
+					writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid hex private key format")
 					return
 				}
 				privateKeyHex = req.PrivateKey
 			} else {
-				api.sendErrorResponse(w, "Private key must be nsec format or 64-character hex", http.StatusBadRequest)
+				writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Private key must be nsec format or 64-character hex")
 				return
 			}
 
 			pubkey, err := tools.DerivePublicKey(privateKeyHex)
 			if err != nil {
-				api.sendErrorResponse(w, fmt.Sprintf("Failed to derive public key: %v", err), http.StatusBadRequest)
+				writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, fmt.Sprintf("Failed to derive public key: %v", err))
 				return
 			}
 
@@ -146,7 +166,7 @@ func (api *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		} else if req.PublicKey != "" {
 			sessionReq.PublicKey = req.PublicKey
 		} else {
-			api.sendErrorResponse(w, "Either public key or private key must be provided", http.StatusBadRequest)
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Either public key or private key must be provided")
 			return
 		}
 	}
@@ -154,7 +174,7 @@ func (api *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	// Create user session
 	userSession, err := session.CreateUserSession(w, sessionReq)
 	if err != nil {
-		api.sendErrorResponse(w, fmt.Sprintf("Failed to create session: %v", err), http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, fmt.Sprintf("Failed to create session: %v", err))
 		return
 	}
 
@@ -188,7 +208,7 @@ func (api *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
 // HandleLogout handles user logout
 func (api *AuthAPI) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
@@ -216,7 +236,7 @@ func (api *AuthAPI) HandleLogout(w http.ResponseWriter, r *http.Request) {
 // HandleSession handles session status checks
 func (api *AuthAPI) HandleSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
@@ -259,17 +279,75 @@ func (api *AuthAPI) HandleSession(w http.ResponseWriter, r *http.Request) {
 	api.sendJSONResponse(w, response, http.StatusOK)
 }
 
+// Identity describes one Nostr identity: the server's signing key or the browser's login
+type Identity struct {
+	PublicKey     string                `json:"public_key,omitempty"`
+	NPub          string                `json:"npub,omitempty"`
+	SigningMethod session.SigningMethod `json:"signing_method,omitempty"`
+}
+
+// IdentityResponse reports whose key signs stream events and whose key this browser is logged
+// in with. Owner features need the two to match; chat messages use the login.
+
+type IdentityResponse struct {
+	Success bool      `json:"success"`
+	Server  *Identity `json:"server,omitempty"`  // Signs live events (kind 30311), from config.yml
+	Session *Identity `json:"session,omitempty"` // Signs this browser's chat messages
+	Matches bool      `json:"matches"`           // Logged in as the server identity, i.e. the owner
+	Warning string    `json:"warning,omitempty"`
+}
+
+// HandleIdentity reports the server signing identity next to the logged-in identity, so the UI
+// can warn a streamer who logged in with a different key than the server publishes under
+func (api *AuthAPI) HandleIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	response := IdentityResponse{Success: true}
+
+	if publicKey, err := serverPublicKey(api.config); err == nil && publicKey != "" {
+		npub, _ := tools.EncodePubkey(publicKey)
+		response.Server = &Identity{PublicKey: publicKey, NPub: npub}
+	}
+
+	if session.IsSessionManagerInitialized() {
+		if userSession := session.SessionMgr.GetCurrentUser(r); userSession != nil {
+			npub, _ := tools.EncodePubkey(userSession.PublicKey)
+			response.Session = &Identity{
+				PublicKey:     userSession.PublicKey,
+				NPub:          npub,
+				SigningMethod: userSession.SigningMethod,
+			}
+		}
+	}
+
+	switch {
+	case response.Server == nil:
+		response.Warning = "No server key is configured, so stream events are not published and nobody is the owner"
+	case response.Session == nil:
+	case response.Session.PublicKey == response.Server.PublicKey:
+		response.Matches = true
+	default:
+		response.Warning = "You are logged in with a different key than the server publishes stream events with. " +
+			"Your chat messages are signed with your login, but stream events stay under the server key and owner controls are unavailable"
+	}
+
+	api.sendJSONResponse(w, response, http.StatusOK)
+}
+
 // HandleGenerateKeys handles key pair generation
 func (api *AuthAPI) HandleGenerateKeys(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	// Generate new key pair
 	keyPair, err := tools.GenerateKeyPair()
 	if err != nil {
-		api.sendErrorResponse(w, fmt.Sprintf("Failed to generate keys: %v", err), http.StatusInternalServerError)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("Failed to generate keys: %v", err))
 		return
 	}
 
@@ -286,7 +364,7 @@ func (api *AuthAPI) HandleGenerateKeys(w http.ResponseWriter, r *http.Request) {
 // HandleConnectRelay handles connecting to a new relay
 func (api *AuthAPI) HandleConnectRelay(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
@@ -295,12 +373,12 @@ func (api *AuthAPI) HandleConnectRelay(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
 		return
 	}
 
 	if req.RelayURL == "" {
-		api.sendErrorResponse(w, "Relay URL is required", http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Relay URL is required")
 		return
 	}
 
@@ -504,6 +582,44 @@ func (api *AuthAPI) renderAmberError(w http.ResponseWriter, errorMsg string) {
 	w.Write([]byte(html))
 }
 
+// HandleHostProfile returns the stream host's (server owner's) Nostr profile
+func (api *AuthAPI) HandleHostProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	publicKey, err := serverPublicKey(api.config)
+	if err != nil || publicKey == "" {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Stream host not configured")
+		return
+	}
+
+	api.hostProfileMux.Lock()
+	if api.hostProfile == nil || time.Since(api.hostProfileFetched) > hostProfileTTL {
+		if profile := api.fetchUserProfile(publicKey); profile != nil {
+			api.hostProfile = profile
+			api.hostProfileFetched = time.Now()
+		}
+	}
+	profile := api.hostProfile
+	api.hostProfileMux.Unlock()
+
+	if profile == nil {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Host profile not found")
+		return
+	}
+
+	npub, _ := tools.EncodePubkey(publicKey)
+
+	api.sendJSONResponse(w, HostProfileResponse{
+		Success:   true,
+		PublicKey: publicKey,
+		NPub:      npub,
+		Profile:   profile,
+	}, http.StatusOK)
+}
+
 // Helper methods
 
 // fetchUserProfile fetches user profile metadata from Nostr
@@ -524,25 +640,35 @@ func (api *AuthAPI) fetchUserProfile(publicKey string) *UserProfile {
 		},
 	}
 
+	// Optionally query the user's own write relays (NIP-65) as well
+	var relayHints []string
+	if api.config.Nostr.UseRelayHints {
+		relayHints = gnostr.WriteRelayHints(coreClient, publicKey)
+	}
+
 	// Subscribe and get the profile event
-	subscription, err := coreClient.Subscribe(filters, nil)
+	subscription, err := coreClient.Subscribe(filters, relayHints)
 	if err != nil {
 		log.Printf("Failed to subscribe for profile: %v", err)
 		return nil
 	}
-	defer subscription.Close()
 
-	// Wait for events (with timeout)
-	select {
-	case event := <-subscription.Events:
-		if event != nil {
-			return api.parseProfileFromEvent(event)
+	ctx, cancel := context.WithTimeout(context.Background(), profileFetchTimeout)
+	defer cancel()
+
+	// Wait for every relay to answer (EOSE) and keep the newest revision of the profile
+	var latest *nostr.Event
+	for _, event := range gnostr.CollectEvents(ctx, subscription, 0) {
+		if latest == nil || event.CreatedAt > latest.CreatedAt {
+			latest = event
 		}
-	case <-time.After(5 * time.Second): // 5 second timeout
-		log.Printf("Profile fetch timeout for pubkey: %s", publicKey[:8])
+	}
+	if latest == nil {
+		log.Printf("No profile found for pubkey: %s", publicKey[:8])
+		return nil
 	}
 
-	return nil
+	return api.parseProfileFromEvent(latest)
 }
 
 // parseProfileFromEvent parses a kind 0 event into UserProfile
@@ -598,6 +724,14 @@ func (api *AuthAPI) validateLoginRequest(req *LoginRequest) error {
 		req.Mode = "read_only" // Default to read-only
 	}
 
+	if req.PublicKey != "" {
+		pubkey, err := gnostr.NormalizePubkey(req.PublicKey)
+		if err != nil {
+			return err
+		}
+		req.PublicKey = pubkey
+	}
+
 	return nil
 }
 
@@ -607,46 +741,28 @@ func (api *AuthAPI) sendJSONResponse(w http.ResponseWriter, data interface{}, st
 	json.NewEncoder(w).Encode(data)
 }
 
-func (api *AuthAPI) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	response := map[string]interface{}{
-		"success": false,
-		"error":   message,
-	}
-	api.sendJSONResponse(w, response, statusCode)
-}
-
 // isServerOwner checks if the given public key matches the server owner's public key
 func (api *AuthAPI) isServerOwner(publicKey string) bool {
-	// Get the server owner's private key from config
-	serverPrivateKey := api.config.Nostr.PrivateKey
-	if serverPrivateKey == "" {
-		return false
-	}
-
-	var privateKeyHex string
-	var err error
+	return isServerOwner(api.config, publicKey)
+}
 
-	// Handle nsec format
-	if strings.HasPrefix(serverPrivateKey, "nsec") {
-		privateKeyHex, err = tools.DecodeNsec(serverPrivateKey)
-		if err != nil {
-			log.Printf("Failed to decode server nsec: %v", err)
-			return false
-		}
-	} else {
-		// Assume it's already hex format
-		privateKeyHex = serverPrivateKey
+// isServerOwner checks if the given public key matches the public key derived from the configured private key
+func isServerOwner(cfg *config.Config, publicKey string) bool {
+	// Relay events aren't verified, so the pubkey may be anything
+	if !isHexID(publicKey) {
+		return false
 	}
 
-	// Derive the public key from the server's private key
-	serverPublicKey, err := tools.DerivePublicKey(privateKeyHex)
+	serverPublicKey, err := serverPublicKey(cfg)
 	if err != nil {
 		log.Printf("Failed to derive server public key: %v", err)
 		return false
 	}
+	return serverPublicKey != "" && publicKey == serverPublicKey
+}
 
-	log.Printf("🔍 Owner check: user=%s server=%s match=%v", publicKey[:16]+"...", serverPublicKey[:16]+"...", publicKey == serverPublicKey)
-
-	// Compare the public keys
-	return publicKey == serverPublicKey
+// serverPublicKey returns the server owner's public key: derived from the configured private key,
+// or nostr.public_key when browser signing runs without one ("" if none is set)
+func serverPublicKey(cfg *config.Config) (string, error) {
+	return cfg.Nostr.OwnerPublicKey()
 }
\ No newline at end of file
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/0ceanslim/grain/client/core/tools"
+
+	"gnostream/src/config"
+)
+
+// testPrivateHex is the NIP-19 test vector private key
+const testPrivateHex = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+
+// testOwnerHex is the public key of testPrivateHex
+var testOwnerHex = mustDerivePublicKey(testPrivateHex)
+
+func mustDerivePublicKey(privateKeyHex string) string {
+	publicKey, err := tools.DerivePublicKey(privateKeyHex)
+	if err != nil {
+		panic(err)
+	}
+	return publicKey
+}
+
+func TestIsServerOwner(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+	cfg := &config.Config{Nostr: config.NostrRelayConfig{PrivateKey: testPrivateHex}}
+	wsm := &WebSocketManager{config: cfg}
+
+	tests := []struct {
+		name   string
+		pubkey string
+		want   bool
+	}{
+		{name: "owner", pubkey: testOwnerHex, want: true},
+		{name: "someone else", pubkey: strings.Repeat("ab", 32), want: false},
+		{name: "empty", pubkey: "", want: false},
+		{name: "short", pubkey: "3bf0c6", want: false},
+		{name: "owner prefix", pubkey: testOwnerHex[:16], want: false},
+		{name: "too long", pubkey: testOwnerHex + "00", want: false},
+		{name: "not hex", pubkey: strings.Repeat("zz", 32), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isServerOwner(cfg, tt.pubkey); got != tt.want {
+				t.Errorf("isServerOwner(%q) = %t, want %t", tt.pubkey, got, tt.want)
+			}
+			if got := wsm.isOwner(tt.pubkey); got != tt.want {
+				t.Errorf("isOwner(%q) = %t, want %t", tt.pubkey, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsServerOwnerWithoutKey(t *testing.T) {
+	t.Setenv(config.PrivateKeyEnv, "")
+	cfg := &config.Config{}
+	wsm := &WebSocketManager{config: cfg}
+
+	for _, pubkey := range []string{"", "short", testOwnerHex} {
+		if isServerOwner(cfg, pubkey) || wsm.isOwner(pubkey) {
+			t.Errorf("%q counted as the owner with no key configured", pubkey)
+		}
+	}
+}
@@ -1,14 +1,19 @@
 package api
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/0ceanslim/grain/client/connection"
 	"github.com/0ceanslim/grain/client/core"
@@ -29,7 +34,7 @@ type ChatAPI struct {
 
 // StreamMonitor interface for getting current stream metadata
 type StreamMonitor interface {
-	GetCurrentMetadata() *config.StreamMetadata
+	GetCurrentMetadata() config.MetadataSnapshot
 }
 
 // NewChatAPI creates a new chat API handler
@@ -51,16 +56,34 @@ type ChatMessage struct {
 	Tags      [][]string         `json:"tags"`
 	Sig       string             `json:"sig"`
 	Profile   *UserProfile       `json:"profile,omitempty"`
-	ReplyTo   string             `json:"reply_to,omitempty"`
+	RootID    string             `json:"root_id,omitempty"`   // Thread root (NIP-10 "root" marker)
+	ReplyTo   string             `json:"reply_to,omitempty"`  // Direct parent (NIP-10 "reply" marker)
+	QuotedID  string             `json:"quoted_id,omitempty"` // Quoted event ("q" tag)
+	Reactions map[string]int     `json:"reactions,omitempty"` // Reaction content -> count (kind 7)
 }
 
 // ChatMessagesResponse represents the response for chat messages
 type ChatMessagesResponse struct {
 	Success  bool          `json:"success"`
 	Messages []ChatMessage `json:"messages"`
+	HasMore  bool          `json:"has_more,omitempty"` // More history may exist before the oldest returned message
+	Latest   string        `json:"latest,omitempty"`   // Newest returned message ID, to pass back as ?since=
 	Error    string        `json:"error,omitempty"`
 }
 
+const (
+	// defaultHistoryLimit is the page size for "load older" chat requests
+	defaultHistoryLimit = 50
+	// maxHistoryLimit caps the page size clients may request
+	maxHistoryLimit = 100
+	// chatFetchTimeout bounds a history fetch when relays are slow to send EOSE
+	chatFetchTimeout = 5 * time.Second
+	// profileFetchTimeout bounds a profile lookup when relays are slow to send EOSE
+	profileFetchTimeout = 3 * time.Second
+	// maxLongPollWait caps how long a ?wait= request blocks, staying under the server's write timeout
+	maxLongPollWait = 25 * time.Second
+)
+
 // SendMessageRequest represents a request to send a chat message
 type SendMessageRequest struct {
 	Content string `json:"content"`
@@ -69,22 +92,23 @@ type SendMessageRequest struct {
 
 // SendMessageResponse represents the response for sending a message
 type SendMessageResponse struct {
-	Success bool   `json:"success"`
-	EventID string `json:"event_id,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success       bool              `json:"success"`
+	EventID       string            `json:"event_id,omitempty"`
+	UnsignedEvent *nostrTypes.Event `json:"unsigned_event,omitempty"` // Set when the user's signer must sign client-side
+	Error         string            `json:"error,omitempty"`
 }
 
 // HandleGetMessages retrieves live chat messages for the current stream
 func (api *ChatAPI) HandleGetMessages(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	// Get current stream metadata to find the 'a' tag
 	streamMetadata, err := api.getCurrentStreamMetadata()
 	if err != nil {
-		api.sendErrorResponse(w, "Failed to get stream metadata", http.StatusInternalServerError)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to get stream metadata")
 		return
 	}
 
@@ -99,6 +123,18 @@ func (api *ChatAPI) HandleGetMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// "Load older" requests are served from relays, the live view from the cache
+	if before := r.URL.Query().Get("before"); before != "" {
+		api.handleGetOlderMessages(w, r, streamMetadata, before)
+		return
+	}
+
+	// Non-WebSocket clients fetch only what they haven't seen, optionally waiting for it
+	if since := r.URL.Query().Get("since"); since != "" && api.wsManager != nil {
+		api.handleGetNewMessages(w, r, since)
+		return
+	}
+
 	log.Printf("📝 Returning cached chat messages for stream: %s (status: %s)", streamMetadata.Dtag, streamMetadata.Status)
 
 	// Get cached messages from WebSocket manager (no subscriptions here!)
@@ -119,48 +155,202 @@ func (api *ChatAPI) HandleGetMessages(w http.ResponseWriter, r *http.Request) {
 	api.sendJSONResponse(w, response, http.StatusOK)
 }
 
+// handleGetNewMessages returns cached messages newer than since (a message ID or unix timestamp).
+// With ?wait=<seconds> it blocks until a new message arrives or the wait runs out.
+func (api *ChatAPI) handleGetNewMessages(w http.ResponseWriter, r *http.Request, since string) {
+	var sinceID string
+	var sinceTime int64
+	if isHexID(since) {
+		sinceID = strings.ToLower(since)
+	} else {
+		var err error
+		sinceTime, err = strconv.ParseInt(since, 10, 64)
+		if err != nil || sinceTime < 0 {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid 'since' value (expected an event ID or unix timestamp)")
+			return
+		}
+	}
+
+	var wait time.Duration
+	if waitParam := r.URL.Query().Get("wait"); waitParam != "" {
+		seconds, err := strconv.Atoi(waitParam)
+		if err != nil || seconds < 0 {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid 'wait' value")
+			return
+		}
+		wait = min(time.Duration(seconds)*time.Second, maxLongPollWait)
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		messages, updated := api.wsManager.CachedMessagesSince(sinceID, sinceTime)
+		if len(messages) > 0 || wait == 0 {
+			api.sendNewMessages(w, messages, since)
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-timeout.C:
+			api.sendNewMessages(w, messages, since)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// sendNewMessages answers a ?since= request; latest stays at since when nothing new arrived
+func (api *ChatAPI) sendNewMessages(w http.ResponseWriter, messages []ChatMessage, since string) {
+	latest := since
+	if len(messages) > 0 {
+		latest = messages[len(messages)-1].ID
+	}
+	api.sendJSONResponse(w, ChatMessagesResponse{Success: true, Messages: messages, Latest: latest}, http.StatusOK)
+}
+
+// handleGetOlderMessages backfills chat history up to and including the given unix timestamp,
+// skipping the event IDs listed in ?exclude (the messages of that second the client already has)
+func (api *ChatAPI) handleGetOlderMessages(w http.ResponseWriter, r *http.Request, streamMetadata *config.StreamMetadata, before string) {
+	beforeUnix, err := strconv.ParseInt(before, 10, 64)
+	if err != nil || beforeUnix <= 0 {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid 'before' timestamp")
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid 'limit' value")
+			return
+		}
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	// Relays treat 'until' as inclusive: messages posted in the same second as the oldest one the
+	// client has are returned too, and the ones it already has (?exclude=<id>,...) are skipped
+	until := time.Unix(beforeUnix, 0)
+	exclude := parseExcludedIDs(r.URL.Query().Get("exclude"))
+
+	log.Printf("📝 Fetching chat history before %d (limit %d) for stream: %s", beforeUnix, limit, streamMetadata.Dtag)
+
+	ctx, cancel := context.WithTimeout(r.Context(), chatFetchTimeout)
+	defer cancel()
+
+	fetched, err := api.getChatMessages(ctx, streamMetadata.Dtag, streamMetadata.Pubkey, &until, limit+len(exclude))
+	if err != nil {
+		log.Printf("❌ Failed to fetch chat history: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to fetch chat history")
+		return
+	}
+
+	response := ChatMessagesResponse{
+		Success:  true,
+		Messages: excludeMessages(fetched, exclude),
+		HasMore:  len(fetched) == limit+len(exclude),
+	}
+
+	api.sendJSONResponse(w, response, http.StatusOK)
+}
+
+// parseExcludedIDs reads the comma-separated event IDs a client already has, ignoring anything
+// that isn't an event ID and capping the list at a page
+func parseExcludedIDs(value string) map[string]bool {
+	exclude := make(map[string]bool)
+	for _, id := range strings.Split(value, ",") {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if isHexID(id) && len(exclude) < maxHistoryLimit {
+			exclude[id] = true
+		}
+	}
+	return exclude
+}
+
+// excludeMessages drops messages whose IDs are in exclude, keeping the order
+func excludeMessages(messages []ChatMessage, exclude map[string]bool) []ChatMessage {
+	if len(exclude) == 0 {
+		return messages
+	}
+	kept := make([]ChatMessage, 0, len(messages))
+	for _, message := range messages {
+		if !exclude[message.ID] {
+			kept = append(kept, message)
+		}
+	}
+	return kept
+}
+
 // HandleSendMessage sends a new live chat message
 func (api *ChatAPI) HandleSendMessage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	// Check if user is authenticated
 	if !session.IsSessionManagerInitialized() {
-		api.sendErrorResponse(w, "Session manager not initialized", http.StatusInternalServerError)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Session manager not initialized")
 		return
 	}
 
 	userSession := session.SessionMgr.GetCurrentUser(r)
 	if userSession == nil || userSession.Mode != session.WriteMode {
-		api.sendErrorResponse(w, "Authentication required for sending messages", http.StatusUnauthorized)
+		writeAPIError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required for sending messages")
+		return
+	}
+
+	if api.wsManager != nil && api.wsManager.IsMuted(userSession.PublicKey) {
+		writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "You are banned from this chat")
 		return
 	}
 
 	var req SendMessageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Content) == "" {
-		api.sendErrorResponse(w, "Message content cannot be empty", http.StatusBadRequest)
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Message content cannot be empty")
+		return
+	}
+	if maxLength := api.config.Chat.MessageLengthLimit(); utf8.RuneCountInString(req.Content) > maxLength {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeMessageTooLong,
+			fmt.Sprintf("Message is too long (maximum %d characters)", maxLength))
 		return
 	}
 
 	// Get current stream metadata
 	streamMetadata, err := api.getCurrentStreamMetadata()
 	if err != nil {
-		api.sendErrorResponse(w, "Failed to get stream metadata", http.StatusInternalServerError)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to get stream metadata")
+		return
+	}
+	if streamMetadata.Dtag == "" {
+		writeAPIError(w, http.StatusConflict, ErrCodeNotLive, "There is no stream to chat in")
 		return
 	}
 
 	// Create the live chat event (kind 1311)
-	eventID, err := api.createChatEvent(userSession, streamMetadata, req.Content, req.ReplyTo)
+	eventID, unsignedEvent, err := api.createChatEvent(userSession, streamMetadata, req.Content, req.ReplyTo)
+	if errors.Is(err, nostr.ErrExternalSigning) {
+		// Hand the event back so the browser extension / Amber can sign it
+		response := SendMessageResponse{
+			Success:       false,
+			Error:         "Event requires external signing",
+			UnsignedEvent: unsignedEvent,
+		}
+		api.sendJSONResponse(w, response, http.StatusAccepted)
+		return
+	}
 	if err != nil {
 		log.Printf("❌ Failed to create chat event: %v", err)
-		api.sendErrorResponse(w, "Failed to send message", http.StatusInternalServerError)
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Failed to send message")
 		return
 	}
 
@@ -177,22 +367,17 @@ func (api *ChatAPI) getCurrentStreamMetadata() (*config.StreamMetadata, error) {
 	// Use the monitor to get current metadata, but only if it has valid data
 	if api.monitor != nil {
 		metadata := api.monitor.GetCurrentMetadata()
-		if metadata != nil && metadata.Dtag != "" && metadata.Pubkey != "" {
+		if metadata.Dtag != "" && metadata.Pubkey != "" {
 			log.Printf("🔍 Monitor provided valid metadata: dtag=%s, status=%s", metadata.Dtag, metadata.Status)
-			return metadata, nil
-		} else {
-			if metadata != nil {
-				log.Printf("⚠️ Monitor metadata incomplete: dtag='%s', pubkey='%s', falling back to file", metadata.Dtag, metadata.Pubkey)
-			} else {
-				log.Printf("⚠️ Monitor returned nil metadata, falling back to file")
-			}
+			return metadata.Metadata(), nil
 		}
+		log.Printf("⚠️ Monitor metadata incomplete: dtag='%s', pubkey='%s', falling back to file", metadata.Dtag, metadata.Pubkey)
 	} else {
 		log.Printf("⚠️ No monitor available, reading file directly")
 	}
 
 	// Fallback to reading metadata file directly
-	metadataFile := "www/live/metadata.json"
+	metadataFile := api.config.GetStreamDefaults().MetadataPath
 
 	// Check if metadata file exists
 	if _, err := os.Stat(metadataFile); os.IsNotExist(err) {
@@ -269,8 +454,8 @@ func (api *ChatAPI) getCurrentStreamMetadata() (*config.StreamMetadata, error) {
 	}, nil
 }
 
-// getChatMessages retrieves live chat messages for a stream
-func (api *ChatAPI) getChatMessages(dtag, hostPubkey string) ([]ChatMessage, error) {
+// getChatMessages retrieves up to limit live chat messages for a stream, optionally only those created until the given time
+func (api *ChatAPI) getChatMessages(ctx context.Context, dtag, hostPubkey string, until *time.Time, limit int) ([]ChatMessage, error) {
 	if api.nostrClient == nil || !api.nostrClient.IsEnabled() {
 		return nil, fmt.Errorf("nostr client not available or disabled")
 	}
@@ -279,13 +464,13 @@ func (api *ChatAPI) getChatMessages(dtag, hostPubkey string) ([]ChatMessage, err
 	aTag := fmt.Sprintf("30311:%s:%s", hostPubkey, dtag)
 
 	// Create filter for kind 1311 (live chat) events with specific 'a' tag
-	limit := 100
 	filters := []nostrTypes.Filter{
 		{
 			Kinds: []int{1311}, // Kind 1311 = live chat message
 			Tags: map[string][]string{
 				"a": {aTag}, // Filter by the specific stream 'a' tag
 			},
+			Until: until,
 			Limit: &limit,
 		},
 	}
@@ -299,57 +484,47 @@ func (api *ChatAPI) getChatMessages(dtag, hostPubkey string) ([]ChatMessage, err
 	if err != nil {
 		return nil, fmt.Errorf("failed to subscribe for chat messages: %w", err)
 	}
-	defer subscription.Close()
-
+	// Collect until every relay sent its stored messages (deduplicated across relays) or ctx ends
 	var chatMessages []ChatMessage
-	seenEventIDs := make(map[string]bool) // Deduplication map
-	timeout := time.After(5 * time.Second) // 5 second timeout
+	for _, event := range nostr.CollectEvents(ctx, subscription, 0) {
+		// Check if this message is for our stream by looking for the 'a' tag
+		isForOurStream := false
+		for _, tag := range event.Tags {
+			if len(tag) >= 2 && tag[0] == "a" && tag[1] == aTag {
+				isForOurStream = true
+				break
+			}
+		}
 
-	// Collect all available messages and filter by 'a' tag
-	for {
-		select {
-		case event := <-subscription.Events:
-			if event != nil {
-				// Skip if we've already seen this event ID (deduplication)
-				if seenEventIDs[event.ID] {
-					continue
-				}
-				seenEventIDs[event.ID] = true
-
-				// Check if this message is for our stream by looking for the 'a' tag
-				isForOurStream := false
-				var foundATags []string
-
-				for _, tag := range event.Tags {
-					if len(tag) >= 2 && tag[0] == "a" {
-						foundATags = append(foundATags, tag[1])
-						if tag[1] == aTag {
-							isForOurStream = true
-						}
-					}
-				}
+		// Relays may ignore 'until', so enforce it client-side too
+		if until != nil && event.CreatedAt > until.Unix() {
+			continue
+		}
+		if utf8.RuneCountInString(event.Content) > api.config.Chat.MessageLengthLimit() {
+			continue
+		}
+		if api.wsManager != nil && api.wsManager.IsMuted(event.PubKey) {
+			continue
+		}
 
-				if isForOurStream {
-					chatMsg := api.eventToChatMessage(event)
-					if chatMsg != nil {
-						chatMessages = append(chatMessages, *chatMsg)
-						log.Printf("💬 Found message for our stream: %s", event.ID[:8])
-					}
-				}
-				// Remove verbose logging of skipped messages
+		if isForOurStream {
+			if chatMsg := api.eventToChatMessage(event); chatMsg != nil {
+				chatMessages = append(chatMessages, *chatMsg)
 			}
-		case <-timeout:
-			log.Printf("📝 Chat message fetch timeout, collected %d unique messages for our stream (dtag: %s)", len(chatMessages), dtag)
-			goto fetchProfiles
 		}
 	}
+	log.Printf("📝 Collected %d unique messages for our stream (dtag: %s)", len(chatMessages), dtag)
 
-fetchProfiles:
 	// Sort messages by created_at
 	sort.Slice(chatMessages, func(i, j int) bool {
 		return chatMessages[i].CreatedAt < chatMessages[j].CreatedAt
 	})
 
+	// Keep only the newest messages if relays returned more than requested
+	if limit > 0 && len(chatMessages) > limit {
+		chatMessages = chatMessages[len(chatMessages)-limit:]
+	}
+
 	// Fetch user profiles for all unique pubkeys
 	api.enrichWithProfiles(chatMessages)
 
@@ -372,15 +547,57 @@ func (api *ChatAPI) eventToChatMessage(event *nostrTypes.Event) *ChatMessage {
 		Sig:       event.Sig,
 	}
 
-	// Check for reply (e tag)
-	for _, tag := range event.Tags {
-		if len(tag) >= 2 && tag[0] == "e" {
-			chatMsg.ReplyTo = tag[1]
-			break
+	// Populate reply threading fields from e/q tags
+	chatMsg.RootID, chatMsg.ReplyTo, chatMsg.QuotedID = parseThreadTags(event.Tags)
+
+	return chatMsg
+}
+
+// parseThreadTags extracts NIP-10 root/reply event IDs and the quoted event ID from event tags.
+// Marked e tags take precedence; unmarked e tags fall back to the deprecated positional scheme.
+func parseThreadTags(tags [][]string) (rootID, replyTo, quotedID string) {
+	var unmarked []string
+
+	for _, tag := range tags {
+		if len(tag) < 2 {
+			continue
+		}
+
+		switch tag[0] {
+		case "e":
+			marker := ""
+			if len(tag) >= 4 {
+				marker = tag[3]
+			}
+			switch marker {
+			case "root":
+				rootID = tag[1]
+			case "reply":
+				replyTo = tag[1]
+			case "mention":
+				// Mentions are not part of the thread
+			default:
+				unmarked = append(unmarked, tag[1])
+			}
+		case "q":
+			if quotedID == "" {
+				quotedID = tag[1]
+			}
 		}
 	}
 
-	return chatMsg
+	// Positional e tags: first is the root, last is the direct parent
+	if rootID == "" && replyTo == "" && len(unmarked) > 0 {
+		rootID = unmarked[0]
+		replyTo = unmarked[len(unmarked)-1]
+	}
+
+	// A reply to the root only carries the "root" marker
+	if replyTo == "" {
+		replyTo = rootID
+	}
+
+	return rootID, replyTo, quotedID
 }
 
 // enrichWithProfiles fetches and adds user profiles to chat messages
@@ -442,26 +659,25 @@ func (api *ChatAPI) fetchMultipleProfiles(pubkeys map[string]bool) map[string]*U
 		log.Printf("Failed to subscribe for profiles: %v", err)
 		return make(map[string]*UserProfile)
 	}
-	defer subscription.Close()
 
-	profiles := make(map[string]*UserProfile)
-	timeout := time.After(3 * time.Second) // 3 second timeout for profiles
+	ctx, cancel := context.WithTimeout(context.Background(), profileFetchTimeout)
+	defer cancel()
 
-	// Collect profile events
-	for {
-		select {
-		case event := <-subscription.Events:
-			if event != nil && event.Kind == 0 {
-				profile := api.parseProfileFromEvent(event)
-				if profile != nil {
-					profiles[event.PubKey] = profile
-				}
-			}
-		case <-timeout:
-			log.Printf("👤 Profile fetch timeout, collected %d profiles", len(profiles))
-			return profiles
+	// Keep each user's newest profile; relays may hold older revisions
+	profiles := make(map[string]*UserProfile)
+	newest := make(map[string]int64)
+	for _, event := range nostr.CollectEvents(ctx, subscription, 0) {
+		if event.Kind != 0 || event.CreatedAt < newest[event.PubKey] {
+			continue
+		}
+		if profile := api.parseProfileFromEvent(event); profile != nil {
+			profiles[event.PubKey] = profile
+			newest[event.PubKey] = event.CreatedAt
 		}
 	}
+
+	log.Printf("👤 Collected %d profiles", len(profiles))
+	return profiles
 }
 
 // parseProfileFromEvent parses a kind 0 event into UserProfile (reused from auth.go)
@@ -508,21 +724,24 @@ func (api *ChatAPI) parseProfileFromEvent(event *nostrTypes.Event) *UserProfile
 	return profile
 }
 
-// createChatEvent creates and broadcasts a live chat event
-func (api *ChatAPI) createChatEvent(userSession *session.UserSession, streamMetadata *config.StreamMetadata, content, replyTo string) (string, error) {
+// createChatEvent creates, signs and broadcasts a live chat event.
+// When the session uses an external signer the unsigned event is returned
+// together with nostr.ErrExternalSigning so the client can sign it.
+func (api *ChatAPI) createChatEvent(userSession *session.UserSession, streamMetadata *config.StreamMetadata, content, replyTo string) (string, *nostrTypes.Event, error) {
 	if !api.nostrClient.IsEnabled() {
-		return "", fmt.Errorf("nostr client not enabled")
+		return "", nil, fmt.Errorf("nostr client not enabled")
 	}
 
 	// Get the Grain client for event building
 	grainClient, ok := api.nostrClient.(*nostr.GrainClient)
 	if !ok {
-		return "", fmt.Errorf("failed to get grain client")
+		return "", nil, fmt.Errorf("failed to get grain client")
 	}
 
-	client := grainClient.GetClient()
-	if client == nil {
-		return "", fmt.Errorf("grain core client not available")
+	// Resolve the signer for the logged-in user's signing method
+	signer, err := nostr.SignerForSession(userSession)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get signer: %w", err)
 	}
 
 	// Create the 'a' tag for the live stream event
@@ -540,42 +759,24 @@ func (api *ChatAPI) createChatEvent(userSession *session.UserSession, streamMeta
 
 	event := eventBuilder.Build()
 
-	// Sign the event using the session's signing method
-	var signedEvent *nostrTypes.Event
-	var err error
-
-	switch userSession.SigningMethod {
-	case "browser_extension":
-		// For browser extension, we'll need to return unsigned event
-		// and let the frontend handle signing via extension
-		return "", fmt.Errorf("browser extension signing not implemented for chat yet")
-
-	case "private_key":
-		// Get the user's signer (this would need to be implemented)
-		return "", fmt.Errorf("private key signing not implemented for chat yet")
-
-	default:
-		return "", fmt.Errorf("unsupported signing method: %s", userSession.SigningMethod)
+	if err := signer.Sign(event); err != nil {
+		if errors.Is(err, nostr.ErrExternalSigning) {
+			return "", event, err
+		}
+		return "", nil, fmt.Errorf("failed to sign chat event: %w", err)
 	}
 
-	// For now, let's create a mock signed event for testing
-	// In production, proper signing would be implemented
-	signedEvent = event
-	signedEvent.PubKey = userSession.PublicKey
-	signedEvent.ID = fmt.Sprintf("mock_id_%d", time.Now().UnixNano())
-	signedEvent.Sig = "mock_signature"
-
-	// Broadcast the event
-	results, err := client.PublishEvent(signedEvent, nil)
+	// Broadcast the event (logged only in dry-run mode)
+	results, err := grainClient.PublishEvent(event, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to publish chat event: %w", err)
+		return "", nil, fmt.Errorf("failed to publish chat event: %w", err)
 	}
 
 	summary := core.SummarizeBroadcast(results)
 	log.Printf("💬 Chat message published to %d/%d relays (%.1f%% success)",
 		summary.Successful, summary.TotalRelays, summary.SuccessRate)
 
-	return signedEvent.ID, nil
+	return event.ID, nil, nil
 }
 
 // Helper methods
@@ -586,10 +787,11 @@ func (api *ChatAPI) sendJSONResponse(w http.ResponseWriter, data interface{}, st
 	json.NewEncoder(w).Encode(data)
 }
 
-func (api *ChatAPI) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	response := map[string]interface{}{
-		"success": false,
-		"error":   message,
+// isHexID reports whether s looks like a Nostr event ID (64 hex characters)
+func isHexID(s string) bool {
+	if len(s) != 64 {
+		return false
 	}
-	api.sendJSONResponse(w, response, statusCode)
-}
\ No newline at end of file
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
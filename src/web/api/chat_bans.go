@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/0ceanslim/grain/client/session"
+
+	"gnostream/src/logging"
+	gnostr "gnostream/src/nostr"
+)
+
+// ChatBanRequest represents a request to ban or unban a chat user
+type ChatBanRequest struct {
+	PublicKey string `json:"pubkey"` // Hex, npub or nprofile
+}
+
+// ChatBanResponse represents the response for chat ban requests
+type ChatBanResponse struct {
+	Success          bool     `json:"success"`
+	Banned           []string `json:"banned"`
+	Changed          bool     `json:"changed"`
+	EventID          string   `json:"event_id,omitempty"`
+	SuccessfulRelays []string `json:"successful_relays,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// HandleChatBans lists (GET), bans (POST) or unbans (DELETE) chat users. Bans are kept in the
+// owner's NIP-51 mute list, so they carry over to other Nostr clients and survive restarts.
+func (api *ChatAPI) HandleChatBans(w http.ResponseWriter, r *http.Request) {
+	if api.wsManager == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "Chat not available")
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if !session.IsSessionManagerInitialized() {
+		writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Session manager not initialized")
+		return
+	}
+
+	userSession := session.SessionMgr.GetCurrentUser(r)
+	if userSession == nil || !isServerOwner(api.config, userSession.PublicKey) {
+		writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "Only the stream owner can ban chat users")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		api.sendJSONResponse(w, ChatBanResponse{Success: true, Banned: api.wsManager.MutedPubkeys()}, http.StatusOK)
+		return
+	}
+
+	if api.nostrClient == nil || !api.nostrClient.IsEnabled() {
+		writeAPIError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "Nostr client not available")
+		return
+	}
+
+	var req ChatBanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	pubkey, err := gnostr.NormalizePubkey(req.PublicKey)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid public key: "+err.Error())
+		return
+	}
+
+	ban := r.Method == http.MethodPost
+	if ban && pubkey == userSession.PublicKey {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "You cannot ban yourself")
+		return
+	}
+
+	result, err := api.nostrClient.UpdateMuteList(pubkey, ban)
+	if err != nil {
+		log.Printf("❌ Failed to update mute list: %v", err)
+		writeAPIError(w, http.StatusBadGateway, ErrCodeInternal, "Failed to update mute list: "+err.Error())
+		return
+	}
+
+	api.wsManager.SetMutedPubkeys(result.Muted)
+	if ban {
+		api.wsManager.removeCachedMessagesFrom(pubkey)
+	}
+
+	api.sendJSONResponse(w, ChatBanResponse{
+		Success:          true,
+		Banned:           result.Muted,
+		Changed:          result.Changed,
+		EventID:          result.EventID,
+		SuccessfulRelays: result.SuccessfulRelays,
+	}, http.StatusOK)
+}
+
+// loadMuteList fills the banned set from the owner's published mute list
+func (wsm *WebSocketManager) loadMuteList() {
+	if wsm.nostrClient == nil || !wsm.nostrClient.IsEnabled() {
+		return
+	}
+
+	muted, err := wsm.nostrClient.GetMuteList()
+	if err != nil {
+		logging.Warnf("⚠️ Failed to load mute list, chat bans start empty: %v", err)
+		return
+	}
+
+	wsm.SetMutedPubkeys(muted)
+	logging.Infof("🔇 Loaded %d banned chat users from the mute list", len(muted))
+}
+
+// SetMutedPubkeys replaces the set of banned chat users
+func (wsm *WebSocketManager) SetMutedPubkeys(pubkeys []string) {
+	muted := make(map[string]bool, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		muted[pubkey] = true
+	}
+
+	wsm.mutedMux.Lock()
+	wsm.muted = muted
+	wsm.mutedMux.Unlock()
+}
+
+// MutedPubkeys returns the banned chat users, sorted
+func (wsm *WebSocketManager) MutedPubkeys() []string {
+	wsm.mutedMux.RLock()
+	defer wsm.mutedMux.RUnlock()
+
+	pubkeys := make([]string, 0, len(wsm.muted))
+	for pubkey := range wsm.muted {
+		pubkeys = append(pubkeys, pubkey)
+	}
+	sort.Strings(pubkeys)
+	return pubkeys
+}
+
+// IsMuted reports whether a pubkey is banned from chat
+func (wsm *WebSocketManager) IsMuted(pubkey string) bool {
+	wsm.mutedMux.RLock()
+	defer wsm.mutedMux.RUnlock()
+	return wsm.muted[pubkey]
+}
+
+// removeCachedMessagesFrom drops a banned user's messages from the chat history cache
+func (wsm *WebSocketManager) removeCachedMessagesFrom(pubkey string) {
+	wsm.cacheMux.Lock()
+	defer wsm.cacheMux.Unlock()
+
+	wsm.messageCache.removeIf(func(message ChatMessage) bool {
+		return message.PubKey == pubkey
+	})
+}
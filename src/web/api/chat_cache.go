@@ -0,0 +1,89 @@
+package api
+
+// messageRing is a fixed-size ring buffer of recent chat messages with a set of the IDs it
+// holds, so inserts and duplicate checks are O(1) and memory stays bounded. It is not
+// thread-safe; WebSocketManager guards it with cacheMux.
+type messageRing struct {
+	items []ChatMessage
+	start int // Index of the oldest message
+	count int
+	seen  map[string]struct{}
+}
+
+// newMessageRing creates a ring holding at most size messages
+func newMessageRing(size int) *messageRing {
+	if size < 1 {
+		size = 1
+	}
+	return &messageRing{
+		items: make([]ChatMessage, size),
+		seen:  make(map[string]struct{}, size),
+	}
+}
+
+// add appends a message, evicting the oldest when full. It returns false for a duplicate.
+func (r *messageRing) add(message ChatMessage) bool {
+	if _, ok := r.seen[message.ID]; ok {
+		return false
+	}
+
+	if r.count == len(r.items) {
+		delete(r.seen, r.items[r.start].ID)
+		r.items[r.start] = message
+		r.start = (r.start + 1) % len(r.items)
+	} else {
+		r.items[(r.start+r.count)%len(r.items)] = message
+		r.count++
+	}
+	r.seen[message.ID] = struct{}{}
+	return true
+}
+
+// contains reports whether a message ID is in the ring
+func (r *messageRing) contains(id string) bool {
+	_, ok := r.seen[id]
+	return ok
+}
+
+// messages returns a copy of the ring's messages, oldest first
+func (r *messageRing) messages() []ChatMessage {
+	messages := make([]ChatMessage, r.count)
+	for i := range messages {
+		messages[i] = r.items[(r.start+i)%len(r.items)]
+	}
+	return messages
+}
+
+// after returns the messages added after the one with the given ID, oldest first. It returns
+// false if the ID is not (or no longer) in the ring.
+func (r *messageRing) after(id string) ([]ChatMessage, bool) {
+	if !r.contains(id) {
+		return nil, false
+	}
+
+	messages := r.messages()
+	for i, message := range messages {
+		if message.ID == id {
+			return messages[i+1:], true
+		}
+	}
+	return nil, false
+}
+
+// removeIf drops every message matching drop, keeping the rest in order
+func (r *messageRing) removeIf(drop func(ChatMessage) bool) {
+	kept := r.messages()
+	r.clear()
+	for _, message := range kept {
+		if !drop(message) {
+			r.add(message)
+		}
+	}
+}
+
+// clear empties the ring
+func (r *messageRing) clear() {
+	clear(r.items)
+	r.start, r.count = 0, 0
+	r.seen = make(map[string]struct{}, len(r.items))
+}
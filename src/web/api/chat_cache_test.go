@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// ringIDs returns the IDs in the ring, oldest first
+func ringIDs(r *messageRing) []string {
+	var ids []string
+	for _, message := range r.messages() {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestMessageRing(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		add       []string
+		removeIf  string // Pubkey to remove after adding, if any
+		want      []string
+		wantAdded int
+	}{
+		{name: "under capacity", size: 3, add: []string{"a", "b"}, want: []string{"a", "b"}, wantAdded: 2},
+		{name: "evicts oldest", size: 3, add: []string{"a", "b", "c", "d", "e"}, want: []string{"c", "d", "e"}, wantAdded: 5},
+		{name: "ignores duplicates", size: 3, add: []string{"a", "b", "a", "b"}, want: []string{"a", "b"}, wantAdded: 2},
+		{name: "evicted ID can return", size: 2, add: []string{"a", "b", "c", "a"}, want: []string{"c", "a"}, wantAdded: 4},
+		{name: "size below one holds one", size: 0, add: []string{"a", "b"}, want: []string{"b"}, wantAdded: 2},
+		{name: "remove keeps order", size: 4, add: []string{"a", "x1", "b", "x2", "c"}, removeIf: "x", want: []string{"b", "c"}, wantAdded: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := newMessageRing(tt.size)
+			added := 0
+			for _, id := range tt.add {
+				// Messages whose ID starts with "x" come from pubkey "x"
+				if ring.add(ChatMessage{ID: id, PubKey: id[:1]}) {
+					added++
+				}
+			}
+			if tt.removeIf != "" {
+				ring.removeIf(func(message ChatMessage) bool { return message.PubKey == tt.removeIf })
+			}
+
+			if got := ringIDs(ring); !slices.Equal(got, tt.want) {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("add accepted %d messages, want %d", added, tt.wantAdded)
+			}
+			// The seen-set must track exactly what the ring holds
+			if len(ring.seen) != len(tt.want) {
+				t.Errorf("seen-set has %d IDs, want %d", len(ring.seen), len(tt.want))
+			}
+			for _, id := range tt.add {
+				if want := slices.Contains(tt.want, id); ring.contains(id) != want {
+					t.Errorf("contains(%q) = %t, want %t", id, !want, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageRingClear(t *testing.T) {
+	ring := newMessageRing(2)
+	ring.add(ChatMessage{ID: "a"})
+	ring.add(ChatMessage{ID: "b"})
+	ring.add(ChatMessage{ID: "c"})
+
+	ring.clear()
+	if got := ring.messages(); len(got) != 0 {
+		t.Errorf("messages after clear = %v, want none", got)
+	}
+	if ring.contains("c") {
+		t.Error("cleared ring still contains c")
+	}
+	if !ring.add(ChatMessage{ID: "c"}) {
+		t.Error("cleared ring rejected a previously cached ID")
+	}
+}
+
+// sliceCache is the previous cache: a slice with a linear duplicate scan, trimmed after each insert
+type sliceCache struct {
+	messages []ChatMessage
+	limit    int
+}
+
+func (c *sliceCache) add(message ChatMessage) {
+	for _, existing := range c.messages {
+		if existing.ID == message.ID {
+			return
+		}
+	}
+	c.messages = append(c.messages, message)
+	if len(c.messages) > c.limit {
+		c.messages = c.messages[len(c.messages)-c.limit:]
+	}
+}
+
+// benchmarkMessages returns n messages with distinct IDs
+func benchmarkMessages(n int) []ChatMessage {
+	messages := make([]ChatMessage, n)
+	for i := range messages {
+		messages[i] = ChatMessage{ID: fmt.Sprintf("%064x", i)}
+	}
+	return messages
+}
+
+func BenchmarkChatCacheAdd(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		messages := benchmarkMessages(4 * size)
+
+		b.Run(fmt.Sprintf("slice/%d", size), func(b *testing.B) {
+			cache := &sliceCache{limit: size}
+			for i := 0; i < b.N; i++ {
+				cache.add(messages[i%len(messages)])
+			}
+		})
+
+		b.Run(fmt.Sprintf("ring/%d", size), func(b *testing.B) {
+			ring := newMessageRing(size)
+			for i := 0; i < b.N; i++ {
+				ring.add(messages[i%len(messages)])
+			}
+		})
+	}
+}
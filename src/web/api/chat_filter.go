@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+// linkPattern matches URLs and bare www. links
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// chatFilter drops or masks chat messages matching a word list, and optionally any link
+type chatFilter struct {
+	config   config.ChatFilterConfig
+	patterns []*regexp.Regexp
+	modTime  time.Time
+	mux      sync.RWMutex
+	filtered atomic.Int64
+}
+
+// newChatFilter creates a chat filter and loads its word list
+func newChatFilter(cfg config.ChatFilterConfig) *chatFilter {
+	filter := &chatFilter{config: cfg}
+	if cfg.Enabled {
+		filter.reloadIfChanged()
+	}
+	return filter
+}
+
+// Apply returns the message content to show and whether the message should be kept
+func (f *chatFilter) Apply(content string) (string, bool) {
+	if !f.config.Enabled {
+		return content, true
+	}
+
+	if f.config.BlockLinks && linkPattern.MatchString(content) {
+		f.filtered.Add(1)
+		return "", false
+	}
+
+	f.reloadIfChanged()
+
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+
+	matched := false
+	for _, pattern := range f.patterns {
+		if !pattern.MatchString(content) {
+			continue
+		}
+		matched = true
+		if f.config.Action != "mask" {
+			break
+		}
+		content = pattern.ReplaceAllStringFunc(content, func(match string) string {
+			return strings.Repeat("*", len([]rune(match)))
+		})
+	}
+
+	if !matched {
+		return content, true
+	}
+
+	f.filtered.Add(1)
+	if f.config.Action == "mask" {
+		return content, true
+	}
+	return "", false
+}
+
+// FilteredCount returns how many messages were dropped or masked
+func (f *chatFilter) FilteredCount() int64 {
+	return f.filtered.Load()
+}
+
+// reloadIfChanged re-reads the word list when the file has been modified
+func (f *chatFilter) reloadIfChanged() {
+	if f.config.WordList == "" {
+		return
+	}
+
+	info, err := os.Stat(f.config.WordList)
+	if err != nil {
+		return
+	}
+
+	f.mux.RLock()
+	unchanged := info.ModTime().Equal(f.modTime)
+	f.mux.RUnlock()
+	if unchanged {
+		return
+	}
+
+	patterns, err := loadWordList(f.config.WordList)
+	if err != nil {
+		logging.Warnf("⚠️ Failed to load chat word list: %v", err)
+		return
+	}
+
+	f.mux.Lock()
+	f.patterns = patterns
+	f.modTime = info.ModTime()
+	f.mux.Unlock()
+
+	logging.Infof("🧹 Loaded %d chat filter patterns from %s", len(patterns), f.config.WordList)
+}
+
+// loadWordList parses a word list file: one word per line, "re:" lines are regular expressions
+func loadWordList(path string) ([]*regexp.Regexp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []*regexp.Regexp
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		expr := `\b` + regexp.QuoteMeta(line) + `\b`
+		if raw, ok := strings.CutPrefix(line, "re:"); ok {
+			expr = raw
+		}
+
+		pattern, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			logging.Warnf("⚠️ Skipping invalid chat filter pattern %q: %v", line, err)
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, scanner.Err()
+}
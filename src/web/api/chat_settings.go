@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/0ceanslim/grain/client/session"
+
+	"gnostream/src/config"
+)
+
+var errNoLiveStream = errors.New("no live stream to apply chat settings to")
+
+// ChatSettings holds per-stream chat moderation toggles
+type ChatSettings struct {
+	Dtag             string `json:"dtag"`              // Stream the settings apply to
+	SlowModeSeconds  int    `json:"slow_mode_seconds"` // Minimum seconds between messages from one pubkey (0 = off)
+	ParticipantsOnly bool   `json:"participants_only"` // Only the owner and listed participants may chat
+}
+
+// ChatSettingsRequest represents a request to update chat moderation toggles
+type ChatSettingsRequest struct {
+	SlowModeSeconds  *int  `json:"slow_mode_seconds,omitempty"`
+	ParticipantsOnly *bool `json:"participants_only,omitempty"`
+}
+
+// ChatSettingsResponse represents the response for chat settings requests
+type ChatSettingsResponse struct {
+	Success  bool          `json:"success"`
+	Settings *ChatSettings `json:"settings,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// HandleChatSettings returns (GET) or updates (POST, owner only) the chat moderation toggles
+func (api *ChatAPI) HandleChatSettings(w http.ResponseWriter, r *http.Request) {
+	if api.wsManager == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "Chat not available")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		settings := api.wsManager.GetChatSettings()
+		api.sendJSONResponse(w, ChatSettingsResponse{Success: true, Settings: &settings}, http.StatusOK)
+
+	case http.MethodPost:
+		if !session.IsSessionManagerInitialized() {
+			writeAPIError(w, http.StatusInternalServerError, ErrCodeInternal, "Session manager not initialized")
+			return
+		}
+
+		userSession := session.SessionMgr.GetCurrentUser(r)
+		if userSession == nil || !isServerOwner(api.config, userSession.PublicKey) {
+			writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "Only the stream owner can change chat settings")
+			return
+		}
+
+		var req ChatSettingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
+			return
+		}
+
+		if req.SlowModeSeconds != nil && *req.SlowModeSeconds < 0 {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "slow_mode_seconds cannot be negative")
+			return
+		}
+
+		settings, err := api.wsManager.UpdateChatSettings(req)
+		if err != nil {
+			writeAPIError(w, http.StatusConflict, ErrCodeInvalidRequest, err.Error())
+			return
+		}
+
+		api.sendJSONResponse(w, ChatSettingsResponse{Success: true, Settings: &settings}, http.StatusOK)
+
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// GetChatSettings returns the chat settings for the current live stream. Settings saved for a
+// previous or ended stream don't apply, so defaults are returned until the owner changes them.
+func (wsm *WebSocketManager) GetChatSettings() ChatSettings {
+	dtag := wsm.liveDtag()
+
+	wsm.settingsMux.Lock()
+	defer wsm.settingsMux.Unlock()
+
+	if wsm.chatSettings.Dtag != dtag {
+		return ChatSettings{Dtag: dtag}
+	}
+	return wsm.chatSettings
+}
+
+// UpdateChatSettings applies and persists new chat toggles for the live stream
+func (wsm *WebSocketManager) UpdateChatSettings(req ChatSettingsRequest) (ChatSettings, error) {
+	dtag := wsm.liveDtag()
+	if dtag == "" {
+		return ChatSettings{}, errNoLiveStream
+	}
+
+	wsm.settingsMux.Lock()
+	defer wsm.settingsMux.Unlock()
+
+	// Settings left over from another stream start again from the defaults
+	if wsm.chatSettings.Dtag != dtag {
+		if wsm.chatSettings.Dtag != "" {
+			log.Printf("💬 Stream changed - resetting chat settings")
+		}
+		wsm.chatSettings = ChatSettings{Dtag: dtag}
+		wsm.lastMessageAt = make(map[string]int64)
+	}
+
+	if req.SlowModeSeconds != nil {
+		wsm.chatSettings.SlowModeSeconds = *req.SlowModeSeconds
+	}
+	if req.ParticipantsOnly != nil {
+		wsm.chatSettings.ParticipantsOnly = *req.ParticipantsOnly
+	}
+	wsm.saveChatSettings()
+
+	log.Printf("💬 Chat settings updated: slow mode %ds, participants only: %t",
+		wsm.chatSettings.SlowModeSeconds, wsm.chatSettings.ParticipantsOnly)
+
+	return wsm.chatSettings, nil
+}
+
+// allowChatMessage applies slow mode and participants-only rules to an incoming chat event
+func (wsm *WebSocketManager) allowChatMessage(pubkey string, createdAt int64) bool {
+	settings := wsm.GetChatSettings()
+	if settings.SlowModeSeconds == 0 && !settings.ParticipantsOnly {
+		return true
+	}
+
+	// The owner is never throttled
+	if wsm.isOwner(pubkey) {
+		return true
+	}
+
+	if settings.ParticipantsOnly && !wsm.isParticipant(pubkey) {
+		return false
+	}
+
+	if settings.SlowModeSeconds > 0 {
+		wsm.settingsMux.Lock()
+		defer wsm.settingsMux.Unlock()
+
+		if last, exists := wsm.lastMessageAt[pubkey]; exists && createdAt-last < int64(settings.SlowModeSeconds) {
+			return false
+		}
+		wsm.lastMessageAt[pubkey] = createdAt
+	}
+
+	return true
+}
+
+// isParticipant checks whether a pubkey is listed as a participant ("p" tag) on the live event
+func (wsm *WebSocketManager) isParticipant(pubkey string) bool {
+	if wsm.monitor == nil {
+		return false
+	}
+
+	metadata := wsm.monitor.GetCurrentMetadata()
+	if metadata.LastNostrEvent == "" {
+		return false
+	}
+
+	var event struct {
+		Tags [][]string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(metadata.LastNostrEvent), &event); err != nil {
+		return false
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "p" && tag[1] == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// liveDtag returns the dtag of the stream currently live, or "" if none
+func (wsm *WebSocketManager) liveDtag() string {
+	if wsm.monitor == nil {
+		return ""
+	}
+
+	metadata := wsm.monitor.GetCurrentMetadata()
+	if metadata.Status != "live" {
+		return ""
+	}
+	return metadata.Dtag
+}
+
+// chatSettingsPath returns where chat settings are persisted: in the data directory, which isn't
+// served and isn't moved into the archive when a stream ends
+func (wsm *WebSocketManager) chatSettingsPath() string {
+	return wsm.config.GetStreamDefaults().ChatSettingsPath
+}
+
+// saveChatSettings persists the current chat settings (caller holds settingsMux)
+func (wsm *WebSocketManager) saveChatSettings() {
+	if err := os.MkdirAll(filepath.Dir(wsm.chatSettingsPath()), 0755); err != nil {
+		log.Printf("⚠️ Failed to save chat settings: %v", err)
+		return
+	}
+	if err := config.SaveJSON(wsm.chatSettingsPath(), wsm.chatSettings); err != nil {
+		log.Printf("⚠️ Failed to save chat settings: %v", err)
+	}
+}
+
+// loadChatSettings restores persisted chat settings, e.g. after a restart mid-stream
+func (wsm *WebSocketManager) loadChatSettings() {
+	data, err := os.ReadFile(wsm.chatSettingsPath())
+	if err != nil {
+		return
+	}
+
+	var settings ChatSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		log.Printf("⚠️ Failed to parse chat settings: %v", err)
+		return
+	}
+
+	wsm.settingsMux.Lock()
+	wsm.chatSettings = settings
+	wsm.settingsMux.Unlock()
+}
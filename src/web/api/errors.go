@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Machine-readable error codes returned in the "code" field of API error bodies
+const (
+	ErrCodeInvalidBody      = "invalid_body"       // Request body missing or not valid JSON
+	ErrCodeInvalidRequest   = "invalid_request"    // Well-formed request with bad or missing values
+	ErrCodeUnauthorized     = "unauthorized"       // Login required
+	ErrCodeForbidden        = "forbidden"          // Logged in, but not allowed (e.g. not the server owner)
+	ErrCodeNotFound         = "not_found"          // Resource doesn't exist
+	ErrCodeNotLive          = "not_live"           // Needs a live stream and there isn't one
+	ErrCodeMessageTooLong   = "message_too_long"   // Chat message exceeds chat.max_message_length
+	ErrCodeMethodNotAllowed = "method_not_allowed" // Wrong HTTP method for the endpoint
+	ErrCodeUnavailable      = "unavailable"        // Feature not configured or dependency down
+	ErrCodeInternal         = "internal_error"     // Unexpected server-side failure
+)
+
+// APIError is the JSON error body returned by API endpoints. "success" and "error" match the
+// older ad-hoc responses, so existing clients keep working; "code" is for branching on.
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"error"`
+}
+
+// NewAPIError creates an API error
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Write sends the error as a JSON response with its HTTP status
+func (e *APIError) Write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(struct {
+		Success bool `json:"success"`
+		*APIError
+	}{false, e})
+}
+
+// WriteAPIError writes a structured JSON error response
+func WriteAPIError(w http.ResponseWriter, status int, code, message string) {
+	NewAPIError(status, code, message).Write(w)
+}
+
+// writeAPIError is the package-internal shorthand for WriteAPIError
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	WriteAPIError(w, status, code, message)
+}
+
+// writeMethodNotAllowed rejects a request made with the wrong HTTP method
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeAPIError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed")
+}
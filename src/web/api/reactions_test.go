@@ -0,0 +1,115 @@
+package api
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0ceanslim/grain/client/core"
+	nostrTypes "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/config"
+	"gnostream/src/nostr"
+)
+
+// subscribeRecorder is a nostr client that records the filters it's asked to subscribe with
+type subscribeRecorder struct {
+	nostr.Client
+	filters [][]nostrTypes.Filter
+}
+
+func (c *subscribeRecorder) Subscribe(filters []nostrTypes.Filter, relayHints []string) (*core.Subscription, error) {
+	c.filters = append(c.filters, filters)
+	return core.NewSubscription("test", filters, relayHints, nil), nil
+}
+
+func newReactionTestManager(client nostr.Client) *WebSocketManager {
+	return &WebSocketManager{
+		config:       &config.Config{},
+		nostrClient:  client,
+		broadcast:    make(chan interface{}, 16),
+		messageCache: newMessageRing(10),
+		cacheUpdated: make(chan struct{}),
+		reactions:    make(map[string]map[string]chatReaction),
+	}
+}
+
+func TestRefreshReactionSubscriptionFollowsCachedMessages(t *testing.T) {
+	client := &subscribeRecorder{}
+	wsm := newReactionTestManager(client)
+	wsm.reactionsActive = true
+	defer wsm.stopReactionSubscription()
+
+	first := strings.Repeat("a", 64)
+	second := strings.Repeat("b", 64)
+
+	// Nothing cached yet - nothing to subscribe to
+	wsm.refreshReactionSubscription()
+	if len(client.filters) != 0 {
+		t.Fatalf("subscribed with an empty cache: %+v", client.filters)
+	}
+
+	wsm.addToCache(ChatMessage{ID: first})
+	wsm.refreshReactionSubscription()
+	if len(client.filters) != 1 {
+		t.Fatalf("subscriptions = %d, want 1", len(client.filters))
+	}
+	filter := client.filters[0][0]
+	if !slices.Equal(filter.Kinds, []int{7}) || !slices.Equal(filter.Tags["e"], []string{first}) {
+		t.Errorf("filter = kinds %v, #e %v, want kinds [7], #e [%s]", filter.Kinds, filter.Tags["e"], first)
+	}
+
+	// An unchanged cache keeps the current subscription
+	wsm.refreshReactionSubscription()
+	if len(client.filters) != 1 {
+		t.Errorf("re-subscribed without new messages: %d subscriptions", len(client.filters))
+	}
+
+	wsm.addToCache(ChatMessage{ID: second})
+	wsm.refreshReactionSubscription()
+	if len(client.filters) != 2 {
+		t.Fatalf("subscriptions = %d, want 2 after a new message", len(client.filters))
+	}
+	if got := client.filters[1][0].Tags["e"]; !slices.Equal(got, []string{first, second}) {
+		t.Errorf("#e = %v, want both cached messages", got)
+	}
+
+	// Once the chat subscription stops, new messages don't re-subscribe
+	wsm.stopReactionSubscription()
+	wsm.addToCache(ChatMessage{ID: strings.Repeat("c", 64)})
+	wsm.refreshReactionSubscription()
+	if len(client.filters) != 2 {
+		t.Errorf("re-subscribed after stopping: %d subscriptions", len(client.filters))
+	}
+}
+
+func TestHandleReactionEventSkipsRepeats(t *testing.T) {
+	wsm := newReactionTestManager(&subscribeRecorder{})
+	target := strings.Repeat("a", 64)
+	wsm.addToCache(ChatMessage{ID: target})
+
+	reaction := &nostrTypes.Event{
+		ID:        strings.Repeat("d", 64),
+		PubKey:    strings.Repeat("e", 64),
+		Kind:      7,
+		CreatedAt: 100,
+		Content:   "🔥",
+		Tags:      [][]string{{"e", target}},
+	}
+
+	// The same reaction from the stream and the #e subscription is only broadcast once
+	wsm.handleReactionEvent(reaction)
+	wsm.handleReactionEvent(reaction)
+	if got := len(wsm.broadcast); got != 1 {
+		t.Errorf("broadcasts = %d, want 1", got)
+	}
+
+	// A newer reaction from the same pubkey replaces the old one
+	replaced := *reaction
+	replaced.CreatedAt = 200
+	replaced.Content = "+"
+	wsm.handleReactionEvent(&replaced)
+	if got := wsm.reactionCounts(target); len(got) != 1 || got["+"] != 1 {
+		t.Errorf("reaction counts = %v, want map[+:1]", got)
+	}
+}
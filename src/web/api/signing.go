@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	nostrTypes "github.com/0ceanslim/grain/server/types"
+
+	"gnostream/src/config"
+	"gnostream/src/nostr"
+)
+
+// PendingEventsResponse lists stream events waiting for the owner's browser to sign them
+type PendingEventsResponse struct {
+	Success bool                `json:"success"`
+	Events  []*nostrTypes.Event `json:"events"`
+}
+
+// SignedEventRequest returns a pending event signed by the owner's browser
+type SignedEventRequest struct {
+	Event *nostrTypes.Event `json:"event"`
+}
+
+// SigningAPI hands stream events to the owner's browser when nostr.event_signer is "browser"
+type SigningAPI struct {
+	config      *config.Config
+	nostrClient nostr.Client
+}
+
+// NewSigningAPI creates a new signing API handler
+func NewSigningAPI(cfg *config.Config, nostrClient nostr.Client) *SigningAPI {
+	return &SigningAPI{
+		config:      cfg,
+		nostrClient: nostrClient,
+	}
+}
+
+// HandlePendingEvents lists unsigned stream events (GET) and accepts their signed copies (POST).
+// Owner only; polling GET is what marks the owner's browser as available to sign.
+func (api *SigningAPI) HandlePendingEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if !isOwnerRequest(api.config, r) {
+		writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "Only the server owner can sign stream events")
+		return
+	}
+
+	if !api.config.Nostr.BrowserSigning() || api.nostrClient == nil || !api.nostrClient.IsEnabled() {
+		writeAPIError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "nostr.event_signer is not set to browser")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PendingEventsResponse{
+			Success: true,
+			Events:  api.nostrClient.PendingSignatures(),
+		})
+		return
+	}
+
+	var req SignedEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Event == nil {
+		writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	if err := api.nostrClient.SubmitSignature(req.Event); err != nil {
+		log.Printf("🚫 Rejected signed stream event: %v", err)
+		switch {
+		case errors.Is(err, nostr.ErrNoPendingEvent):
+			writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Event is not pending (already signed or timed out)")
+		default:
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		}
+		return
+	}
+
+	log.Printf("✍️ Stream event %s signed by the owner's browser", req.Event.ID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      req.Event.ID,
+	})
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"gnostream/src/config"
+	"gnostream/src/logging"
+)
+
+// StatusMessage is pushed to /ws/status clients when stream status, metadata or viewer count changes
+type StatusMessage struct {
+	Type          string                   `json:"type"` // "status" or "viewers"
+	Status        string                   `json:"status,omitempty"`
+	Metadata      *config.MetadataSnapshot `json:"metadata,omitempty"`
+	ActiveViewers int                      `json:"active_viewers"`
+}
+
+// StatusHub pushes stream status changes to connected browsers
+type StatusHub struct {
+	monitor     StreamMonitor
+	viewerCount func() int
+	clients     map[*websocket.Conn]chan StatusMessage
+	clientsMux  sync.RWMutex
+	lastViewers int
+}
+
+// NewStatusHub creates a new status hub
+func NewStatusHub(monitor StreamMonitor, viewerCount func() int) *StatusHub {
+	return &StatusHub{
+		monitor:     monitor,
+		viewerCount: viewerCount,
+		clients:     make(map[*websocket.Conn]chan StatusMessage),
+	}
+}
+
+// Run watches the viewer count and pushes changes to clients
+func (h *StatusHub) Run() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count := h.viewerCount()
+		if count == h.lastViewers {
+			continue
+		}
+		h.lastViewers = count
+		h.broadcast(StatusMessage{Type: "viewers", ActiveViewers: count})
+	}
+}
+
+// BroadcastStatus pushes a status/metadata update to all clients
+func (h *StatusHub) BroadcastStatus(metadata config.MetadataSnapshot) {
+	h.broadcast(h.statusMessage(metadata))
+}
+
+// statusMessage builds a full status message from metadata
+func (h *StatusHub) statusMessage(metadata config.MetadataSnapshot) StatusMessage {
+	msg := StatusMessage{
+		Type:          "status",
+		Status:        "offline",
+		Metadata:      &metadata,
+		ActiveViewers: h.viewerCount(),
+	}
+	if metadata.Status != "" {
+		msg.Status = metadata.Status
+	}
+	return msg
+}
+
+// broadcast sends a message to every connected client, dropping clients that can't keep up
+func (h *StatusHub) broadcast(msg StatusMessage) {
+	h.clientsMux.Lock()
+	defer h.clientsMux.Unlock()
+
+	for conn, send := range h.clients {
+		select {
+		case send <- msg:
+		default:
+			close(send)
+			delete(h.clients, conn)
+		}
+	}
+}
+
+// CloseAll disconnects every status client (used on shutdown)
+func (h *StatusHub) CloseAll() {
+	h.clientsMux.Lock()
+	defer h.clientsMux.Unlock()
+
+	for conn, send := range h.clients {
+		close(send)
+		delete(h.clients, conn)
+	}
+}
+
+// HandleWebSocket handles /ws/status connection requests
+func (h *StatusHub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logging.Errorf("❌ Status WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	send := make(chan StatusMessage, 16)
+
+	// Send the current state immediately so the badge is correct on connect
+	send <- h.statusMessage(h.monitor.GetCurrentMetadata())
+
+	h.clientsMux.Lock()
+	h.clients[conn] = send
+	h.clientsMux.Unlock()
+
+	go h.writePump(conn, send)
+	go h.readPump(conn)
+}
+
+// writePump writes queued status messages and keepalive pings to a client
+func (h *StatusHub) writePump(conn *websocket.Conn, send chan StatusMessage) {
+	ticker := time.NewTicker(54 * time.Second)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteJSON(message); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readPump discards client messages and unregisters the client when the connection closes
+func (h *StatusHub) readPump(conn *websocket.Conn) {
+	defer func() {
+		h.clientsMux.Lock()
+		if send, ok := h.clients[conn]; ok {
+			close(send)
+			delete(h.clients, conn)
+		}
+		h.clientsMux.Unlock()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		return nil
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/0ceanslim/grain/client/session"
+
+	"gnostream/src/config"
+	"gnostream/src/nostr"
+	"gnostream/src/stream"
+)
+
+// FFmpegRestarter restarts the FFmpeg process behind the RTMP server
+type FFmpegRestarter interface {
+	RestartFFmpeg() error
+}
+
+// StreamControlAPI handles owner-only stream control actions
+type StreamControlAPI struct {
+	config      *config.Config
+	nostrClient nostr.Client
+	restarter   FFmpegRestarter
+}
+
+// PlannedStreamRequest represents a request to announce an upcoming stream
+type PlannedStreamRequest struct {
+	Starts  string `json:"starts"` // Unix seconds, RFC 3339 or "YYYY-MM-DD HH:MM"
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// NewStreamControlAPI creates a new stream control API handler (restarter is nil when the RTMP server is disabled)
+func NewStreamControlAPI(cfg *config.Config, nostrClient nostr.Client, restarter FFmpegRestarter) *StreamControlAPI {
+	return &StreamControlAPI{config: cfg, nostrClient: nostrClient, restarter: restarter}
+}
+
+// HandleRestartFFmpeg kills and relaunches the RTMP server's FFmpeg (POST /api/stream/restart-ffmpeg)
+func (api *StreamControlAPI) HandleRestartFFmpeg(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !isOwnerRequest(api.config, r) {
+		api.sendErrorResponse(w, "Only the server owner can control the stream", http.StatusForbidden)
+		return
+	}
+
+	if api.restarter == nil {
+		api.sendErrorResponse(w, "RTMP server is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	log.Printf("🔄 FFmpeg restart requested from %s", r.RemoteAddr)
+	if err := api.restarter.RestartFFmpeg(); err != nil {
+		log.Printf("❌ FFmpeg restart failed: %v", err)
+		api.sendErrorResponse(w, "Failed to restart FFmpeg: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success": true,
+		"message": "FFmpeg restarted",
+	}, http.StatusOK)
+}
+
+// HandleNostrTest publishes a throwaway event to check keys and relays (POST /api/nostr/test)
+func (api *StreamControlAPI) HandleNostrTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !isOwnerRequest(api.config, r) {
+		api.sendErrorResponse(w, "Only the server owner can test the Nostr setup", http.StatusForbidden)
+		return
+	}
+
+	if !api.nostrClient.IsEnabled() {
+		api.sendErrorResponse(w, "Nostr keys are not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	eventID, results, err := api.nostrClient.PublishTestEvent()
+	if err != nil {
+		log.Printf("❌ Nostr connectivity test failed: %v", err)
+		api.sendErrorResponse(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	accepted := 0
+	for _, result := range results {
+		if result.Accepted {
+			accepted++
+		}
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success":  accepted > 0 || api.config.Nostr.DryRun,
+		"event_id": eventID,
+		"accepted": accepted,
+		"total":    len(results),
+		"dry_run":  api.config.Nostr.DryRun,
+		"relays":   results,
+	}, http.StatusOK)
+}
+
+// HandleLastEvent returns the last published live event exactly as sent, with the relays that
+// accepted it, for troubleshooting (GET /api/nostr/last-event)
+func (api *StreamControlAPI) HandleLastEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !isOwnerRequest(api.config, r) {
+		api.sendErrorResponse(w, "Only the server owner can inspect published events", http.StatusForbidden)
+		return
+	}
+
+	metadataPath := api.config.GetStreamDefaults().MetadataPath
+	metadata, err := config.LoadStreamMetadata(metadataPath)
+	if err != nil || metadata.LastNostrEvent == "" {
+		api.sendErrorResponse(w, "No Nostr event has been published yet - start a stream first", http.StatusNotFound)
+		return
+	}
+
+	// Hand the stored JSON through untouched so it matches what relays received
+	var event interface{} = metadata.LastNostrEvent
+	if json.Valid([]byte(metadata.LastNostrEvent)) {
+		event = json.RawMessage(metadata.LastNostrEvent)
+	}
+
+	successfulRelays := metadata.SuccessfulRelays
+	if successfulRelays == nil {
+		successfulRelays = []string{}
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success":           true,
+		"dtag":              metadata.Dtag,
+		"status":            metadata.Status,
+		"event":             event,
+		"successful_relays": successfulRelays,
+	}, http.StatusOK)
+}
+
+// HandlePlannedStream shows (GET), announces (POST) or clears (DELETE) the planned stream (/api/stream/planned)
+func (api *StreamControlAPI) HandlePlannedStream(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		planned, err := stream.LoadPlannedStream(api.config)
+		if err != nil {
+			api.sendErrorResponse(w, "Failed to read planned stream", http.StatusInternalServerError)
+			return
+		}
+		api.sendJSONResponse(w, map[string]interface{}{
+			"success": true,
+			"planned": planned,
+		}, http.StatusOK)
+
+	case http.MethodPost:
+		if !isOwnerRequest(api.config, r) {
+			api.sendErrorResponse(w, "Only the server owner can schedule streams", http.StatusForbidden)
+			return
+		}
+
+		var req PlannedStreamRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		starts, err := stream.ParsePlannedStart(req.Starts)
+		if err != nil {
+			api.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		planned, err := stream.SchedulePlannedStream(api.config, api.nostrClient, req.Title, req.Summary, starts)
+		if err != nil {
+			api.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.sendJSONResponse(w, map[string]interface{}{
+			"success": true,
+			"planned": planned,
+		}, http.StatusOK)
+
+	case http.MethodDelete:
+		if !isOwnerRequest(api.config, r) {
+			api.sendErrorResponse(w, "Only the server owner can schedule streams", http.StatusForbidden)
+			return
+		}
+
+		if err := stream.ClearPlannedStream(api.config); err != nil {
+			api.sendErrorResponse(w, "Failed to clear planned stream", http.StatusInternalServerError)
+			return
+		}
+		api.sendJSONResponse(w, map[string]interface{}{"success": true}, http.StatusOK)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// isOwnerRequest accepts either a logged-in owner session or a NIP-98 Authorization header
+// signed by the server key (used by the CLI, which has no browser session)
+func isOwnerRequest(cfg *config.Config, r *http.Request) bool {
+	if header := r.Header.Get("Authorization"); header != "" {
+		pubkey, err := nostr.VerifyHTTPAuthHeader(header, r.URL.Path, r.Method)
+		if err != nil {
+			log.Printf("🚫 Rejected stream control auth: %v", err)
+			return false
+		}
+		return isServerOwner(cfg, pubkey)
+	}
+
+	if !session.IsSessionManagerInitialized() {
+		return false
+	}
+
+	userSession := session.SessionMgr.GetCurrentUser(r)
+	return userSession != nil && isServerOwner(cfg, userSession.PublicKey)
+}
+
+func (api *StreamControlAPI) sendJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (api *StreamControlAPI) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	response := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+	api.sendJSONResponse(w, response, statusCode)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gnostream/src/config"
+	"gnostream/src/rtmp"
+)
+
+// RTMPStatusProvider reports the RTMP server's listener and per-stream-key status
+type RTMPStatusProvider interface {
+	Status() rtmp.ServerStatus
+}
+
+// StreamsAPI reports RTMP-level stream status
+type StreamsAPI struct {
+	config   *config.Config
+	provider RTMPStatusProvider
+}
+
+// NewStreamsAPI creates a new streams API handler (provider is nil when the RTMP server is disabled)
+func NewStreamsAPI(cfg *config.Config, provider RTMPStatusProvider) *StreamsAPI {
+	return &StreamsAPI{config: cfg, provider: provider}
+}
+
+// HandleStreams lists the stream keys currently publishing with their uptime (GET /api/streams)
+func (api *StreamsAPI) HandleStreams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	live := []rtmp.StreamStatus{}
+	if api.provider != nil {
+		for _, status := range api.provider.Status().Streams {
+			// FFmpeg listening without a publisher isn't a live stream
+			if status.PublishingSince != nil {
+				status.FFmpegPID = 0 // Process details are owner-only (see /api/rtmp/status)
+				live = append(live, status)
+			}
+		}
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success": true,
+		"streams": live,
+	}, http.StatusOK)
+}
+
+// HandleRTMPStatus reports the RTMP listener and every stream key including FFmpeg PIDs (GET /api/rtmp/status, owner only)
+func (api *StreamsAPI) HandleRTMPStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !isOwnerRequest(api.config, r) {
+		api.sendErrorResponse(w, "Only the server owner can view RTMP status", http.StatusForbidden)
+		return
+	}
+
+	if api.provider == nil {
+		api.sendJSONResponse(w, map[string]interface{}{
+			"success": true,
+			"enabled": false,
+		}, http.StatusOK)
+		return
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success": true,
+		"enabled": true,
+		"rtmp":    api.provider.Status(),
+	}, http.StatusOK)
+}
+
+func (api *StreamsAPI) sendJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (api *StreamsAPI) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	response := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+	api.sendJSONResponse(w, response, statusCode)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"gnostream/src/analytics"
+	"gnostream/src/config"
+)
+
+// ViewersAPI serves per-viewer session details and the viewer IP denylist (owner only)
+type ViewersAPI struct {
+	config    *config.Config
+	tracker   *analytics.ViewerTracker
+	blocklist *analytics.IPBlocklist
+}
+
+// ViewerDetailResponse is a single viewer session plus whether its IP is blocked
+type ViewerDetailResponse struct {
+	Success bool                    `json:"success"`
+	Session analytics.ViewerSession `json:"session"`
+	Blocked bool                    `json:"blocked"`
+}
+
+// ViewerUnblockRequest lifts a block by IP, as blocked viewers' sessions expire
+type ViewerUnblockRequest struct {
+	IP string `json:"ip"`
+}
+
+// NewViewersAPI creates a new viewers API handler
+func NewViewersAPI(cfg *config.Config, tracker *analytics.ViewerTracker, blocklist *analytics.IPBlocklist) *ViewersAPI {
+	return &ViewersAPI{config: cfg, tracker: tracker, blocklist: blocklist}
+}
+
+// HandleViewer serves GET /api/viewers/{sessionID}, blocks the session's IP from HLS content with
+// POST /api/viewers/{sessionID}/block, and lists (GET) or lifts (DELETE {"ip"}) blocks at
+// /api/viewers/blocked
+func (api *ViewersAPI) HandleViewer(w http.ResponseWriter, r *http.Request) {
+	if !isOwnerRequest(api.config, r) {
+		writeAPIError(w, http.StatusForbidden, ErrCodeForbidden, "Only the server owner can manage viewers")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/viewers/")
+	if path == "blocked" {
+		api.handleBlocked(w, r)
+		return
+	}
+
+	if sessionID, ok := strings.CutSuffix(path, "/block"); ok {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w)
+			return
+		}
+		api.handleBlock(w, sessionID)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	viewer, exists := api.tracker.GetSession(path)
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Viewer session not found")
+		return
+	}
+
+	api.sendJSONResponse(w, ViewerDetailResponse{
+		Success: true,
+		Session: viewer,
+		Blocked: api.blocklist.IsBlocked(viewer.IPAddress),
+	}, http.StatusOK)
+}
+
+// handleBlock adds a session's IP to the denylist
+func (api *ViewersAPI) handleBlock(w http.ResponseWriter, sessionID string) {
+	viewer, exists := api.tracker.GetSession(sessionID)
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, ErrCodeNotFound, "Viewer session not found")
+		return
+	}
+
+	changed, err := api.blocklist.Block(viewer)
+	if err != nil {
+		log.Printf("⚠️ Viewer %s blocked, but the blocklist was not saved: %v", viewer.IPAddress, err)
+	} else if changed {
+		log.Printf("🚫 Blocked viewer %s (session %s)", viewer.IPAddress, viewer.ID)
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success": true,
+		"changed": changed,
+		"blocked": api.blocklist.List(),
+	}, http.StatusOK)
+}
+
+// handleBlocked lists blocked IPs or lifts a block
+func (api *ViewersAPI) handleBlocked(w http.ResponseWriter, r *http.Request) {
+	changed := false
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		var req ViewerUnblockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.IP) == "" {
+			writeAPIError(w, http.StatusBadRequest, ErrCodeInvalidBody, "Request body must include an ip")
+			return
+		}
+
+		ip := strings.TrimSpace(req.IP)
+		var err error
+		changed, err = api.blocklist.Unblock(ip)
+		if err != nil {
+			log.Printf("⚠️ Viewer %s unblocked, but the blocklist was not saved: %v", ip, err)
+		} else if changed {
+			log.Printf("✅ Unblocked viewer %s", ip)
+		}
+	default:
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	api.sendJSONResponse(w, map[string]interface{}{
+		"success": true,
+		"changed": changed,
+		"blocked": api.blocklist.List(),
+	}, http.StatusOK)
+}
+
+func (api *ViewersAPI) sendJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
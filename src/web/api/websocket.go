@@ -3,17 +3,19 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"slices"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/0ceanslim/grain/client/core"
 	nostrTypes "github.com/0ceanslim/grain/server/types"
 	"github.com/gorilla/websocket"
 
 	"gnostream/src/config"
+	"gnostream/src/logging"
 	"gnostream/src/nostr"
 )
 
@@ -23,24 +25,63 @@ type WebSocketManager struct {
 	monitor      StreamMonitor
 	clients      map[*websocket.Conn]*ChatClient
 	clientsMux   sync.RWMutex
-	broadcast    chan ChatMessage
+	broadcast    chan interface{} // ChatMessage or ReactionMessage
 	register     chan *ChatClient
 	unregister   chan *ChatClient
 	nostrClient  nostr.Client
 	nostrSub     *core.Subscription
 	currentATag  string
 	// Message cache for HTTP API
-	messageCache []ChatMessage
+	messageCache *messageRing
 	cacheMux     sync.RWMutex
+	cacheUpdated chan struct{} // Closed and replaced whenever the cache changes, waking long-polls
+	// Reactions per target event ID, keyed by reacting pubkey
+	reactions    map[string]map[string]chatReaction
+	reactionsMux sync.RWMutex
+	// Kind 7 subscription keyed on the cached chat message IDs, rebuilt as messages arrive
+	reactionSub     *core.Subscription
+	reactionIDs     []string
+	reactionTimer   *time.Timer
+	reactionsActive bool
+	reactionSubMux  sync.Mutex
+	// Chat moderation toggles for the live stream
+	chatSettings  ChatSettings
+	lastMessageAt map[string]int64 // Last chat created_at per pubkey, for slow mode
+	settingsMux   sync.Mutex
+	chatFilter    *chatFilter // Word list / link filter applied before broadcasting
+	// Banned chat users, mirrored from the owner's NIP-51 mute list
+	muted    map[string]bool
+	mutedMux sync.RWMutex
+	// Server owner's pubkey, derived once for the per-event owner checks
+	ownerKey     string
+	ownerKeyOnce sync.Once
 }
 
 // ChatClient represents a connected WebSocket client
 type ChatClient struct {
 	conn     *websocket.Conn
-	send     chan ChatMessage
+	send     chan interface{}
 	manager  *WebSocketManager
 }
 
+// ReactionMessage is pushed to WebSocket clients when a chat message receives a reaction
+type ReactionMessage struct {
+	Type      string         `json:"type"` // Always "reaction"
+	TargetID  string         `json:"target_id"`
+	PubKey    string         `json:"pubkey"`
+	Content   string         `json:"content"`
+	Reactions map[string]int `json:"reactions"` // Updated counts for the target message
+}
+
+// chatReaction is the latest reaction a pubkey left on a message
+type chatReaction struct {
+	content   string
+	createdAt int64
+}
+
+// reactionRefreshDelay batches new chat messages into one reaction re-subscription
+const reactionRefreshDelay = 5 * time.Second
+
 // WebSocket upgrader
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
@@ -52,16 +93,41 @@ var upgrader = websocket.Upgrader{
 
 // NewWebSocketManager creates a new WebSocket manager
 func NewWebSocketManager(cfg *config.Config, monitor StreamMonitor, nostrClient nostr.Client) *WebSocketManager {
-	return &WebSocketManager{
-		config:       cfg,
-		monitor:      monitor,
-		clients:      make(map[*websocket.Conn]*ChatClient),
-		broadcast:    make(chan ChatMessage, 256),
-		register:     make(chan *ChatClient),
-		unregister:   make(chan *ChatClient),
-		nostrClient:  nostrClient,
-		messageCache: make([]ChatMessage, 0),
-	}
+	wsm := &WebSocketManager{
+		config:        cfg,
+		monitor:       monitor,
+		clients:       make(map[*websocket.Conn]*ChatClient),
+		broadcast:     make(chan interface{}, 256),
+		register:      make(chan *ChatClient),
+		unregister:    make(chan *ChatClient),
+		nostrClient:   nostrClient,
+		messageCache:  newMessageRing(cfg.Chat.CacheLimit()),
+		cacheUpdated:  make(chan struct{}),
+		reactions:     make(map[string]map[string]chatReaction),
+		lastMessageAt: make(map[string]int64),
+		muted:         make(map[string]bool),
+	}
+
+	wsm.chatFilter = newChatFilter(cfg.Chat.Filter)
+	if nostrClient != nil {
+		nostrClient.OnRelaysReconnected(wsm.handleRelaysReconnected)
+	}
+	wsm.loadChatSettings()
+
+	return wsm
+}
+
+// isOwner reports whether a relay event's pubkey is the server owner's. The owner key is derived
+// once instead of on every chat event; a malformed pubkey simply doesn't match.
+func (wsm *WebSocketManager) isOwner(pubkey string) bool {
+	wsm.ownerKeyOnce.Do(func() {
+		key, err := serverPublicKey(wsm.config)
+		if err != nil {
+			logging.Warnf("⚠️ Failed to derive server public key: %v", err)
+		}
+		wsm.ownerKey = key
+	})
+	return wsm.ownerKey != "" && pubkey == wsm.ownerKey
 }
 
 // Run starts the WebSocket manager
@@ -76,7 +142,7 @@ func (wsm *WebSocketManager) Run() {
 			wsm.clientsMux.Lock()
 			wsm.clients[client.conn] = client
 			wsm.clientsMux.Unlock()
-			log.Printf("💬 WebSocket client connected (%d total)", len(wsm.clients))
+			logging.Infof("💬 WebSocket client connected (%d total)", len(wsm.clients))
 
 			// Subscription is now handled by StartInitialSubscription(), not here
 
@@ -87,7 +153,7 @@ func (wsm *WebSocketManager) Run() {
 				close(client.send)
 			}
 			wsm.clientsMux.Unlock()
-			log.Printf("💬 WebSocket client disconnected (%d total)", len(wsm.clients))
+			logging.Infof("💬 WebSocket client disconnected (%d total)", len(wsm.clients))
 
 			// Subscription stays active regardless of client count
 
@@ -109,17 +175,34 @@ func (wsm *WebSocketManager) Run() {
 	}
 }
 
+// FilteredMessageCount returns how many chat messages the content filter dropped or masked
+func (wsm *WebSocketManager) FilteredMessageCount() int64 {
+	return wsm.chatFilter.FilteredCount()
+}
+
+// CloseAll disconnects every chat client (used on shutdown)
+func (wsm *WebSocketManager) CloseAll() {
+	wsm.clientsMux.Lock()
+	defer wsm.clientsMux.Unlock()
+
+	for conn, client := range wsm.clients {
+		close(client.send) // writePump sends a close frame and closes the connection
+		delete(wsm.clients, conn)
+	}
+	logging.Infof("💬 Closed all chat WebSocket clients")
+}
+
 // HandleWebSocket handles WebSocket connection requests
 func (wsm *WebSocketManager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("❌ WebSocket upgrade failed: %v", err)
+		logging.Errorf("❌ WebSocket upgrade failed: %v", err)
 		return
 	}
 
 	client := &ChatClient{
 		conn:    conn,
-		send:    make(chan ChatMessage, 256),
+		send:    make(chan interface{}, 256),
 		manager: wsm,
 	}
 
@@ -133,19 +216,19 @@ func (wsm *WebSocketManager) HandleWebSocket(w http.ResponseWriter, r *http.Requ
 // startNostrSubscription starts subscribing to nostr relays for chat messages
 func (wsm *WebSocketManager) startNostrSubscription() {
 	if wsm.nostrClient == nil || !wsm.nostrClient.IsEnabled() {
-		log.Printf("📝 Nostr client not available for WebSocket subscription")
+		logging.Debugf("📝 Nostr client not available for WebSocket subscription")
 		return
 	}
 
 	// Get current stream metadata
 	metadata, err := wsm.getCurrentStreamMetadata()
 	if err != nil {
-		log.Printf("❌ Failed to get stream metadata for WebSocket: %v", err)
+		logging.Errorf("❌ Failed to get stream metadata for WebSocket: %v", err)
 		return
 	}
 
 	if metadata.Dtag == "offline" {
-		log.Printf("📝 Stream offline, not starting nostr subscription")
+		logging.Debugf("📝 Stream offline, not starting nostr subscription")
 		return
 	}
 
@@ -154,47 +237,162 @@ func (wsm *WebSocketManager) startNostrSubscription() {
 
 	// Check if already subscribed to this stream
 	if wsm.currentATag == aTag && wsm.nostrSub != nil {
-		log.Printf("📡 Already subscribed to stream: %s", aTag)
+		logging.Infof("📡 Already subscribed to stream: %s", aTag)
 		return
 	}
 
 	wsm.currentATag = aTag
-	log.Printf("📡 Starting real-time nostr subscription for: %s", aTag)
+	logging.Infof("📡 Starting real-time nostr subscription for: %s", aTag)
 
 	// Create subscription filter - grain client has issues with tag filters, so just filter by kind
 	// We'll do client-side filtering since relay filtering isn't working
 	filters := []nostrTypes.Filter{
 		{
-			Kinds: []int{1311}, // Kind 1311 = live chat message
+			Kinds: []int{1311, 7}, // Kind 1311 = live chat message, kind 7 = reaction
 			// Note: No tag filter due to grain client issues - using client-side filtering instead
 		},
 	}
 
 	subscription, err := wsm.nostrClient.Subscribe(filters, nil)
 	if err != nil {
-		log.Printf("❌ Failed to create nostr subscription: %v", err)
+		logging.Errorf("❌ Failed to create nostr subscription: %v", err)
 		return
 	}
 
-	log.Printf("✅ Nostr subscription created successfully")
+	logging.Infof("✅ Nostr subscription created successfully")
 	wsm.nostrSub = subscription
 
 	// Grain automatically starts the subscription, no need to call Start()
 
 	// Listen for events using grain's event channel
 	go wsm.listenForEvents()
+
+	// Reactions to messages already cached (e.g. after a relay reconnect) are picked up too
+	wsm.reactionSubMux.Lock()
+	wsm.reactionsActive = true
+	wsm.reactionSubMux.Unlock()
+	wsm.scheduleReactionRefresh()
 }
 
 // stopNostrSubscription stops the nostr subscription
 func (wsm *WebSocketManager) stopNostrSubscription() {
+	wsm.stopReactionSubscription()
+
 	if wsm.nostrSub != nil {
-		log.Printf("📡 Stopping nostr subscription")
+		logging.Infof("📡 Stopping nostr subscription")
 		wsm.nostrSub.Close()
 		wsm.nostrSub = nil
 		wsm.currentATag = ""
 	}
 }
 
+// scheduleReactionRefresh rebuilds the reaction subscription shortly, so a burst of chat
+// messages causes one re-subscription instead of one per message
+func (wsm *WebSocketManager) scheduleReactionRefresh() {
+	wsm.reactionSubMux.Lock()
+	defer wsm.reactionSubMux.Unlock()
+
+	if !wsm.reactionsActive || wsm.reactionTimer != nil {
+		return
+	}
+	wsm.reactionTimer = time.AfterFunc(reactionRefreshDelay, func() {
+		wsm.reactionSubMux.Lock()
+		wsm.reactionTimer = nil
+		wsm.reactionSubMux.Unlock()
+		wsm.refreshReactionSubscription()
+	})
+}
+
+// refreshReactionSubscription re-subscribes to kind 7 events whose e tag is a cached chat
+// message. Reactions don't always carry the stream's a tag, so this filter catches the rest.
+// Grain subscriptions can't change their filters, so the old one is replaced.
+func (wsm *WebSocketManager) refreshReactionSubscription() {
+	wsm.cacheMux.RLock()
+	cached := wsm.messageCache.messages()
+	wsm.cacheMux.RUnlock()
+
+	ids := make([]string, 0, len(cached))
+	for _, message := range cached {
+		ids = append(ids, message.ID)
+	}
+
+	wsm.reactionSubMux.Lock()
+	defer wsm.reactionSubMux.Unlock()
+
+	if !wsm.reactionsActive || slices.Equal(ids, wsm.reactionIDs) {
+		return
+	}
+	if wsm.reactionSub != nil {
+		wsm.reactionSub.Close()
+		wsm.reactionSub = nil
+	}
+	wsm.reactionIDs = ids
+	if len(ids) == 0 {
+		return
+	}
+
+	filters := []nostrTypes.Filter{
+		{
+			Kinds: []int{7}, // Kind 7 = reaction
+			Tags: map[string][]string{
+				"e": ids, // Reactions to the cached chat messages
+			},
+		},
+	}
+
+	subscription, err := wsm.nostrClient.Subscribe(filters, nil)
+	if err != nil {
+		logging.Errorf("❌ Failed to create reaction subscription: %v", err)
+		return
+	}
+
+	logging.Debugf("📡 Reaction subscription refreshed for %d chat messages", len(ids))
+	wsm.reactionSub = subscription
+	go wsm.listenForReactions(subscription)
+}
+
+// stopReactionSubscription closes the reaction subscription and cancels a pending refresh
+func (wsm *WebSocketManager) stopReactionSubscription() {
+	wsm.reactionSubMux.Lock()
+	defer wsm.reactionSubMux.Unlock()
+
+	wsm.reactionsActive = false
+	if wsm.reactionTimer != nil {
+		wsm.reactionTimer.Stop()
+		wsm.reactionTimer = nil
+	}
+	if wsm.reactionSub != nil {
+		wsm.reactionSub.Close()
+		wsm.reactionSub = nil
+	}
+	wsm.reactionIDs = nil
+}
+
+// listenForReactions handles the events of one reaction subscription until it is closed
+func (wsm *WebSocketManager) listenForReactions(subscription *core.Subscription) {
+	for {
+		select {
+		case event, open := <-subscription.Events:
+			if !open {
+				return
+			}
+			if event != nil && event.Kind == 7 {
+				wsm.handleReactionEvent(event)
+			}
+
+		case err := <-subscription.Errors:
+			if err != nil {
+				logging.Errorf("⚠️ Reaction subscription error: %v", err)
+			}
+
+		case _, open := <-subscription.Done:
+			if !open {
+				return
+			}
+		}
+	}
+}
+
 // listenForEvents listens for incoming nostr events using grain's channels
 func (wsm *WebSocketManager) listenForEvents() {
 	if wsm.nostrSub == nil {
@@ -213,6 +411,12 @@ func (wsm *WebSocketManager) listenForEvents() {
 				}
 				seenEventIDs[event.ID] = true
 
+				// Reactions are matched by stream tag or by the chat message they target
+				if event.Kind == 7 {
+					wsm.handleReactionEvent(event)
+					continue
+				}
+
 				// Verify this event is actually for our stream
 				isForOurStream := false
 				for _, tag := range event.Tags {
@@ -226,10 +430,36 @@ func (wsm *WebSocketManager) listenForEvents() {
 					continue
 				}
 
+				// Other clients can post any length; oversized messages are dropped rather than
+				// truncated, since cutting the content would break the event signature
+				if utf8.RuneCountInString(event.Content) > wsm.config.Chat.MessageLengthLimit() {
+					logging.Debugf("✂️ Dropped oversized chat message %s (%d characters)", event.ID, utf8.RuneCountInString(event.Content))
+					continue
+				}
+
+				if wsm.IsMuted(event.PubKey) {
+					continue
+				}
+
+				// Enforce slow mode and participants-only chat
+				if !wsm.allowChatMessage(event.PubKey, event.CreatedAt) {
+					continue
+				}
+
 				// Convert to chat message
 				chatMsg := wsm.eventToChatMessage(event)
 				if chatMsg != nil {
 
+					// Drop or mask filtered content (the owner is exempt)
+					if wsm.config.Chat.Filter.Enabled && !wsm.isOwner(event.PubKey) {
+						content, keep := wsm.chatFilter.Apply(chatMsg.Content)
+						if !keep {
+							logging.Debugf("🧹 Filtered chat message %s", event.ID)
+							continue
+						}
+						chatMsg.Content = content
+					}
+
 					// Fetch user profile for the message using grain client
 					if chatMsg.Profile == nil {
 						chatMsg.Profile = wsm.fetchUserProfile(event.PubKey)
@@ -249,11 +479,17 @@ func (wsm *WebSocketManager) listenForEvents() {
 
 		case err := <-wsm.nostrSub.Errors:
 			if err != nil {
-				log.Printf("⚠️ Nostr subscription error: %v", err)
+				logging.Errorf("⚠️ Nostr subscription error: %v", err)
 			}
 
-		case <-wsm.nostrSub.Done:
-			log.Printf("📡 Nostr subscription closed")
+		case _, open := <-wsm.nostrSub.Done:
+			// Grain signals each relay's EOSE on Done; the live subscription keeps running
+			// until the channel is closed
+			if open {
+				logging.Debugf("📡 Relay finished sending stored chat events (EOSE)")
+				continue
+			}
+			logging.Infof("📡 Nostr subscription closed")
 			return
 		}
 	}
@@ -264,27 +500,22 @@ func (wsm *WebSocketManager) getCurrentStreamMetadata() (*config.StreamMetadata,
 	// Try monitor first
 	if wsm.monitor != nil {
 		metadata := wsm.monitor.GetCurrentMetadata()
-		if metadata != nil && metadata.Dtag != "" && metadata.Pubkey != "" {
-			log.Printf("🔍 Monitor provided valid metadata: dtag=%s, status=%s", metadata.Dtag, metadata.Status)
-			return metadata, nil
-		} else {
-			if metadata != nil {
-				log.Printf("⚠️ Monitor metadata incomplete: dtag='%s', pubkey='%s', falling back to file", metadata.Dtag, metadata.Pubkey)
-			} else {
-				log.Printf("⚠️ Monitor returned nil metadata, falling back to file")
-			}
+		if metadata.Dtag != "" && metadata.Pubkey != "" {
+			logging.Debugf("🔍 Monitor provided valid metadata: dtag=%s, status=%s", metadata.Dtag, metadata.Status)
+			return metadata.Metadata(), nil
 		}
+		logging.Warnf("⚠️ Monitor metadata incomplete: dtag='%s', pubkey='%s', falling back to file", metadata.Dtag, metadata.Pubkey)
 	} else {
-		log.Printf("⚠️ No monitor available, reading file directly")
+		logging.Warnf("⚠️ No monitor available, reading file directly")
 	}
 
 	// Fallback to reading metadata file directly
-	metadataFile := "www/live/metadata.json"
+	metadataFile := wsm.config.GetStreamDefaults().MetadataPath
 
 	// Check if metadata file exists
 	if _, err := os.Stat(metadataFile); os.IsNotExist(err) {
 		// Return default if no metadata file - but this means no chat available
-		log.Printf("📝 No stream metadata file found - stream is offline")
+		logging.Debugf("📝 No stream metadata file found - stream is offline")
 		return &config.StreamMetadata{
 			Dtag:   "offline",
 			Pubkey: wsm.config.Nostr.PublicKey,
@@ -311,7 +542,7 @@ func (wsm *WebSocketManager) getCurrentStreamMetadata() (*config.StreamMetadata,
 		return nil, fmt.Errorf("failed to parse metadata JSON: %w", err)
 	}
 
-	log.Printf("🔍 Raw metadata: dtag=%s, status=%s, last_nostr_event_length=%d",
+	logging.Debugf("🔍 Raw metadata: dtag=%s, status=%s, last_nostr_event_length=%d",
 		metadata.Dtag, metadata.Status, len(metadata.LastNostrEvent))
 
 	// Extract dtag, pubkey, and event ID from the last nostr event
@@ -326,7 +557,7 @@ func (wsm *WebSocketManager) getCurrentStreamMetadata() (*config.StreamMetadata,
 			Tags   [][]string `json:"tags"`
 		}
 		if err := json.Unmarshal([]byte(metadata.LastNostrEvent), &event); err != nil {
-			log.Printf("❌ Failed to parse last_nostr_event: %v", err)
+			logging.Errorf("❌ Failed to parse last_nostr_event: %v", err)
 		} else {
 		
 			// Get event ID (this is what we need for the a tag)
@@ -346,7 +577,7 @@ func (wsm *WebSocketManager) getCurrentStreamMetadata() (*config.StreamMetadata,
 			}
 		}
 	} else {
-		log.Printf("⚠️ No last_nostr_event found in metadata")
+		logging.Warnf("⚠️ No last_nostr_event found in metadata")
 	}
 
 
@@ -380,13 +611,8 @@ func (wsm *WebSocketManager) eventToChatMessage(event *nostrTypes.Event) *ChatMe
 		Sig:       event.Sig,
 	}
 
-	// Check for reply
-	for _, tag := range event.Tags {
-		if len(tag) >= 2 && tag[0] == "e" {
-			chatMsg.ReplyTo = tag[1]
-			break
-		}
-	}
+	// Populate reply threading fields
+	chatMsg.RootID, chatMsg.ReplyTo, chatMsg.QuotedID = parseThreadTags(event.Tags)
 
 	return chatMsg
 }
@@ -402,7 +628,7 @@ func (wsm *WebSocketManager) fetchUserProfile(pubkey string) *UserProfile {
 	// Use the nostr client to fetch user profile
 	profileEvent, err := wsm.nostrClient.GetUserProfile(pubkey, nil)
 	if err != nil {
-		log.Printf("⚠️ Failed to fetch profile for %s: %v", pubkey[:8], err)
+		logging.Warnf("⚠️ Failed to fetch profile for %s: %v", pubkey[:8], err)
 		return &UserProfile{
 			Name: pubkey[:8] + "...",
 		}
@@ -442,55 +668,201 @@ func (wsm *WebSocketManager) fetchUserProfile(pubkey string) *UserProfile {
 // addToCache adds a message to the cache (thread-safe)
 func (wsm *WebSocketManager) addToCache(message ChatMessage) {
 	wsm.cacheMux.Lock()
-	defer wsm.cacheMux.Unlock()
-
-	// Check for duplicates
-	for _, existing := range wsm.messageCache {
-		if existing.ID == message.ID {
-			return // Already cached
-		}
+	// Duplicates are ignored; the oldest message is evicted once chat.max_cached_messages is reached
+	added := wsm.messageCache.add(message)
+	if added {
+		wsm.notifyCacheUpdatedLocked()
 	}
+	wsm.cacheMux.Unlock()
 
-	// Add to cache
-	wsm.messageCache = append(wsm.messageCache, message)
-
-	// Keep cache size reasonable (last 100 messages)
-	if len(wsm.messageCache) > 100 {
-		wsm.messageCache = wsm.messageCache[len(wsm.messageCache)-100:]
+	// Follow reactions to the new message
+	if added {
+		wsm.scheduleReactionRefresh()
 	}
+}
 
+// notifyCacheUpdatedLocked wakes long-polling chat requests. cacheMux must be held for writing.
+func (wsm *WebSocketManager) notifyCacheUpdatedLocked() {
+	close(wsm.cacheUpdated)
+	wsm.cacheUpdated = make(chan struct{})
 }
 
-// GetCachedMessages returns cached messages (thread-safe)
+// GetCachedMessages returns cached messages with their reaction summaries (thread-safe)
 func (wsm *WebSocketManager) GetCachedMessages() []ChatMessage {
 	wsm.cacheMux.RLock()
-	defer wsm.cacheMux.RUnlock()
-
 	// Return a copy to avoid race conditions
-	messages := make([]ChatMessage, len(wsm.messageCache))
-	copy(messages, wsm.messageCache)
+	messages := wsm.messageCache.messages()
+	wsm.cacheMux.RUnlock()
+
+	wsm.attachReactionCounts(messages)
 	return messages
 }
 
-// ClearCache clears the message cache (when stream changes)
+// CachedMessagesSince returns cached messages newer than since, a message ID or a unix timestamp,
+// plus a channel that is closed when the cache next changes. An ID no longer in the cache returns
+// the whole cache, since the client can't tell what it missed.
+func (wsm *WebSocketManager) CachedMessagesSince(sinceID string, sinceTime int64) ([]ChatMessage, <-chan struct{}) {
+	wsm.cacheMux.RLock()
+	var messages []ChatMessage
+	if sinceID != "" {
+		newer, found := wsm.messageCache.after(sinceID)
+		if !found {
+			newer = wsm.messageCache.messages()
+		}
+		messages = newer
+	} else {
+		for _, message := range wsm.messageCache.messages() {
+			if message.CreatedAt > sinceTime {
+				messages = append(messages, message)
+			}
+		}
+	}
+	updated := wsm.cacheUpdated
+	wsm.cacheMux.RUnlock()
+
+	if messages == nil {
+		messages = []ChatMessage{}
+	}
+	wsm.attachReactionCounts(messages)
+	return messages, updated
+}
+
+// attachReactionCounts fills in reaction summaries on copied messages
+func (wsm *WebSocketManager) attachReactionCounts(messages []ChatMessage) {
+	for i := range messages {
+		if counts := wsm.reactionCounts(messages[i].ID); len(counts) > 0 {
+			messages[i].Reactions = counts
+		}
+	}
+}
+
+// ClearCache clears the message and reaction caches (when stream changes)
 func (wsm *WebSocketManager) ClearCache() {
 	wsm.cacheMux.Lock()
-	defer wsm.cacheMux.Unlock()
-	wsm.messageCache = make([]ChatMessage, 0)
+	wsm.messageCache.clear()
+	wsm.notifyCacheUpdatedLocked()
+	wsm.cacheMux.Unlock()
+
+	wsm.reactionsMux.Lock()
+	wsm.reactions = make(map[string]map[string]chatReaction)
+	wsm.reactionsMux.Unlock()
+}
+
+// isCachedMessage reports whether a chat message ID is in the cache
+func (wsm *WebSocketManager) isCachedMessage(eventID string) bool {
+	wsm.cacheMux.RLock()
+	defer wsm.cacheMux.RUnlock()
+
+	return wsm.messageCache.contains(eventID)
+}
+
+// handleReactionEvent records a kind 7 reaction and pushes the updated counts to clients
+func (wsm *WebSocketManager) handleReactionEvent(event *nostrTypes.Event) {
+	targetID := reactionTarget(event.Tags)
+	if targetID == "" {
+		return
+	}
+
+	// Accept reactions tagged with our stream, or targeting a message we know about
+	isForOurStream := false
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "a" && tag[1] == wsm.currentATag {
+			isForOurStream = true
+			break
+		}
+	}
+	if !isForOurStream && !wsm.isCachedMessage(targetID) {
+		return
+	}
+
+	content := event.Content
+	if content == "" {
+		content = "+" // NIP-25: empty content is a like
+	}
+
+	wsm.reactionsMux.Lock()
+	byPubkey, exists := wsm.reactions[targetID]
+	if !exists {
+		byPubkey = make(map[string]chatReaction)
+		wsm.reactions[targetID] = byPubkey
+	}
+	// One reaction per pubkey per message - newer reactions replace older ones. The same
+	// reaction arriving again (from both subscriptions or a re-subscription) isn't re-broadcast.
+	if previous, exists := byPubkey[event.PubKey]; exists && (previous.createdAt > event.CreatedAt ||
+		previous.createdAt == event.CreatedAt && previous.content == content) {
+		wsm.reactionsMux.Unlock()
+		return
+	}
+	byPubkey[event.PubKey] = chatReaction{content: content, createdAt: event.CreatedAt}
+	wsm.reactionsMux.Unlock()
+
+	message := ReactionMessage{
+		Type:      "reaction",
+		TargetID:  targetID,
+		PubKey:    event.PubKey,
+		Content:   content,
+		Reactions: wsm.reactionCounts(targetID),
+	}
+
+	select {
+	case wsm.broadcast <- message:
+	default:
+		// Channel full, drop reaction silently
+	}
+}
+
+// reactionCounts returns reaction content counts for a target event (thread-safe)
+func (wsm *WebSocketManager) reactionCounts(targetID string) map[string]int {
+	wsm.reactionsMux.RLock()
+	defer wsm.reactionsMux.RUnlock()
+
+	byPubkey := wsm.reactions[targetID]
+	if len(byPubkey) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, reaction := range byPubkey {
+		counts[reaction.content]++
+	}
+	return counts
+}
+
+// reactionTarget returns the reacted-to event ID, which NIP-25 places in the last e tag
+func reactionTarget(tags [][]string) string {
+	targetID := ""
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			targetID = tag[1]
+		}
+	}
+	return targetID
+}
+
+// handleRelaysReconnected re-creates the chat subscription after relays drop and come back,
+// since the old subscription doesn't survive the lost connection
+func (wsm *WebSocketManager) handleRelaysReconnected(relays []string) {
+	if wsm.nostrSub == nil {
+		return
+	}
+
+	logging.Infof("📡 Relays reconnected - resubscribing to chat")
+	wsm.stopNostrSubscription()
+	wsm.startNostrSubscription()
 }
 
 // checkStreamChange checks if the stream has changed and restarts subscription if needed
 func (wsm *WebSocketManager) checkStreamChange() {
 	metadata, err := wsm.getCurrentStreamMetadata()
 	if err != nil {
-		log.Printf("⚠️ Failed to check stream metadata: %v", err)
+		logging.Warnf("⚠️ Failed to check stream metadata: %v", err)
 		return
 	}
 
 	if metadata.Dtag == "offline" {
 		// Stream went offline - stop subscription
 		if wsm.nostrSub != nil {
-			log.Printf("📴 Stream went offline - stopping subscription")
+			logging.Infof("📴 Stream went offline - stopping subscription")
 			wsm.stopNostrSubscription()
 		}
 		return
@@ -500,7 +872,7 @@ func (wsm *WebSocketManager) checkStreamChange() {
 
 	// If stream changed, restart subscription
 	if wsm.currentATag != newATag {
-		log.Printf("🔄 Stream changed: %s → %s", wsm.currentATag, newATag)
+		logging.Infof("🔄 Stream changed: %s → %s", wsm.currentATag, newATag)
 
 		// Stop old subscription
 		if wsm.nostrSub != nil {
@@ -517,20 +889,22 @@ func (wsm *WebSocketManager) checkStreamChange() {
 
 // StartInitialSubscription starts nostr subscription immediately on server startup
 func (wsm *WebSocketManager) StartInitialSubscription() {
-	log.Printf("🚀 Starting initial nostr subscription on server startup")
+	logging.Infof("🚀 Starting initial nostr subscription on server startup")
 
 	// Start with a small delay to let server finish initializing
 	time.Sleep(2 * time.Second)
 
 	// Ensure no existing subscription
 	if wsm.nostrSub != nil {
-		log.Printf("🔄 Found existing subscription, stopping it first")
+		logging.Infof("🔄 Found existing subscription, stopping it first")
 		wsm.stopNostrSubscription()
 	}
 
 	// Clear any existing cache from wrong messages
 	wsm.ClearCache()
 
+	wsm.loadMuteList()
+
 	// Start the subscription immediately
 	wsm.startNostrSubscription()
 
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gnostream/src/hls"
+	"gnostream/src/stream"
+)
+
+// offlineRoute is the URL path the offline placeholder's segments are served under
+const offlineRoute = "/offline/"
+
+// offlinePlaceholderHandler answers live playlist requests with the looping offline placeholder
+// while no stream is running (stream.offline.source). Once a stream is live and FFmpeg has written
+// its playlist, requests fall through to the real output.
+func (s *Server) offlinePlaceholderHandler(next http.Handler) http.Handler {
+	if !s.config.Stream.Offline.Enabled() {
+		return next
+	}
+
+	streamDefaults := s.config.GetStreamDefaults()
+	livePlaylist := filepath.Join(streamDefaults.OutputDir, "output.m3u8")
+	placeholderPlaylist := filepath.Join(streamDefaults.OfflineDir, stream.OfflinePlaylist)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "output.m3u8" || s.isLiveOutput(livePlaylist) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(placeholderPlaylist)
+		if err != nil {
+			// Not encoded (yet); fall back to the regular 404
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		playlist := hls.LoopPlaylist(hls.ParseSegments(data), offlineRoute, time.Now())
+		if playlist == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", hlsContentTypes[".m3u8"])
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(playlist)
+	})
+}
+
+// isLiveOutput reports whether a stream is running and FFmpeg has written its playlist
+func (s *Server) isLiveOutput(livePlaylist string) bool {
+	if !s.monitor.IsActive() {
+		return false
+	}
+	_, err := os.Stat(livePlaylist)
+	return err == nil
+}
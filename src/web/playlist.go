@@ -0,0 +1,168 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gnostream/src/config"
+	"gnostream/src/hls"
+)
+
+// blockingReloadPoll is how often the playlist is re-read while a blocking reload waits
+const blockingReloadPoll = 100 * time.Millisecond
+
+// lowLatencyPlaylistHandler serves the live playlist with LL-HLS blocking reload when low-latency mode is on.
+// FFmpeg's HLS muxer can't emit partial segments (#EXT-X-PART), so low latency comes from short fMP4
+// segments plus blocking reload: a player asking for ?_HLS_msn=N is held until segment N exists instead
+// of polling. _HLS_part is accepted but blocks at segment granularity.
+func (s *Server) lowLatencyPlaylistHandler(outputDir string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hlsConfig := s.config.GetHLSConfig()
+		if !hlsConfig.LowLatency || !strings.HasSuffix(r.URL.Path, ".m3u8") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		playlistPath := filepath.Join(outputDir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		targetDuration := time.Duration(hlsConfig.SegmentTime) * time.Second
+
+		if msnParam := r.URL.Query().Get("_HLS_msn"); msnParam != "" {
+			msn, err := strconv.Atoi(msnParam)
+			if err != nil || msn < 0 {
+				http.Error(w, "invalid _HLS_msn", http.StatusBadRequest)
+				return
+			}
+
+			// Per the LL-HLS spec, requests too far in the future are rejected rather than held
+			if latest, ok := hls.ReadLatestSegmentSequence(playlistPath); ok && msn > latest+2 {
+				http.Error(w, "_HLS_msn too far ahead of live edge", http.StatusBadRequest)
+				return
+			}
+
+			deadline := time.Now().Add(3 * targetDuration)
+			for time.Now().Before(deadline) {
+				if latest, ok := hls.ReadLatestSegmentSequence(playlistPath); ok && latest >= msn {
+					break
+				}
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(blockingReloadPoll):
+				}
+			}
+		}
+
+		data, err := os.ReadFile(playlistPath)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		data = s.checkPlaylist(playlistPath, data, hlsConfig.PlaylistCheck)
+
+		w.Header().Set("Content-Type", hlsContentTypes[".m3u8"])
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(addServerControl(data, targetDuration))
+	})
+}
+
+// addServerControl advertises blocking reload support in a media playlist. Master playlists
+// (ABR variants) are left alone: the tag is only valid in media playlists.
+func addServerControl(playlist []byte, targetDuration time.Duration) []byte {
+	const header = "#EXTM3U\n"
+	content := string(playlist)
+	if !strings.HasPrefix(content, header) || strings.Contains(content, "#EXT-X-SERVER-CONTROL") ||
+		strings.Contains(content, "#EXT-X-STREAM-INF") || strings.Contains(content, "#EXT-X-MEDIA:") {
+		return playlist
+	}
+
+	// HOLD-BACK must be at least three target durations
+	serverControl := fmt.Sprintf("#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,HOLD-BACK=%.1f\n", (3 * targetDuration).Seconds())
+	return []byte(header + serverControl + strings.TrimPrefix(content, header))
+}
+
+// playlistIntegrityHandler serves live playlists (those directly in the output directory) through
+// checkPlaylist, so a segment FFmpeg listed but failed to flush doesn't break playback. Low-latency
+// playlists are checked by lowLatencyPlaylistHandler instead.
+func (s *Server) playlistIntegrityHandler(outputDir string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hlsConfig := s.config.GetHLSConfig()
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if hlsConfig.PlaylistCheck == config.PlaylistCheckOff || hlsConfig.LowLatency ||
+			!strings.HasSuffix(name, ".m3u8") || strings.Contains(name, "/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		playlistPath := filepath.Join(outputDir, name)
+		data, err := os.ReadFile(playlistPath)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", hlsContentTypes[".m3u8"])
+		w.Write(s.checkPlaylist(playlistPath, data, hlsConfig.PlaylistCheck))
+	})
+}
+
+// checkPlaylist verifies that the segments a playlist lists exist and aren't empty. Broken ones
+// are logged (once per segment file) and, in repair mode, left out of the returned playlist.
+func (s *Server) checkPlaylist(playlistPath string, data []byte, mode string) []byte {
+	if mode == config.PlaylistCheckOff {
+		return data
+	}
+
+	dir := filepath.Dir(playlistPath)
+	repaired, broken := hls.CheckSegments(data, func(uri string) bool {
+		segmentPath, ok := s.segmentPath(dir, uri)
+		if !ok {
+			return true
+		}
+		info, err := os.Stat(segmentPath)
+		return err == nil && info.Size() > 0
+	})
+	if len(broken) == 0 {
+		return data
+	}
+
+	for _, uri := range broken {
+		if _, seen := s.brokenSegments.LoadOrStore(filepath.Join(dir, path.Base(uri)), true); !seen {
+			log.Printf("⚠️ Playlist %s lists missing or empty segment %s", filepath.Base(playlistPath), uri)
+		}
+	}
+
+	if mode == config.PlaylistCheckLog {
+		return data
+	}
+	return repaired
+}
+
+// segmentPath maps a playlist's segment URI to its file, accepting the absolute /live/ URLs written
+// with hls.absolute_segment_urls. It reports false for URIs that point elsewhere.
+func (s *Server) segmentPath(dir, uri string) (string, bool) {
+	if parsed, err := url.Parse(uri); err == nil {
+		uri = parsed.Path
+	}
+
+	if strings.HasPrefix(uri, "/") {
+		_, name, ok := strings.Cut(uri, "/live/")
+		if !ok {
+			return "", false
+		}
+		uri = name
+	}
+
+	name := path.Clean(uri)
+	if name == "." || strings.HasPrefix(name, "..") {
+		return "", false
+	}
+	return filepath.Join(dir, filepath.FromSlash(name)), true
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddServerControl(t *testing.T) {
+	const serverControl = "#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,HOLD-BACK=6.0\n"
+
+	tests := []struct {
+		name     string
+		playlist string
+		want     string
+	}{
+		{
+			name:     "media playlist",
+			playlist: "#EXTM3U\n#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:4\n#EXTINF:2.0,\nsegment004.ts\n",
+			want:     "#EXTM3U\n" + serverControl + "#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:4\n#EXTINF:2.0,\nsegment004.ts\n",
+		},
+		{
+			name: "master playlist",
+			playlist: "#EXTM3U\n#EXT-X-VERSION:3\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720\n720p/output.m3u8\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=1096000,RESOLUTION=854x480\n480p/output.m3u8\n",
+		},
+		{
+			name: "master playlist with alternate audio",
+			playlist: "#EXTM3U\n" +
+				"#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"audio\",NAME=\"main\",URI=\"audio/output.m3u8\"\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=2928000,AUDIO=\"audio\"\n720p/output.m3u8\n",
+		},
+		{
+			name:     "already advertised",
+			playlist: "#EXTM3U\n" + serverControl + "#EXTINF:2.0,\nsegment000.ts\n",
+		},
+		{
+			name:     "not a playlist",
+			playlist: "<html></html>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == "" {
+				want = tt.playlist // Left unchanged
+			}
+			got := string(addServerControl([]byte(tt.playlist), 2*time.Second))
+			if got != want {
+				t.Errorf("addServerControl() =\n%s\nwant\n%s", got, want)
+			}
+			if strings.Contains(tt.playlist, "#EXT-X-STREAM-INF") && strings.Contains(got, "#EXT-X-SERVER-CONTROL") {
+				t.Error("EXT-X-SERVER-CONTROL added to a master playlist")
+			}
+		})
+	}
+}
@@ -2,16 +2,23 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"gnostream/src/analytics"
 	"gnostream/src/config"
 	"gnostream/src/nostr"
+	"gnostream/src/rtmp"
 	"gnostream/src/stream"
+	"gnostream/src/util"
 	"gnostream/src/web/api"
 )
 
@@ -20,15 +27,32 @@ type Server struct {
 	config        *config.Config
 	monitor       *stream.Monitor
 	templates     *template.Template
+	templatesMux  sync.RWMutex
 	viewerTracker *analytics.ViewerTracker
 	authAPI       *api.AuthAPI
 	chatAPI       *api.ChatAPI
+	archiveAPI    *api.ArchiveAPI
+	controlAPI    *api.StreamControlAPI
+	streamsAPI    *api.StreamsAPI
+	viewersAPI    *api.ViewersAPI
+	accessAPI     *api.AccessAPI
+	signingAPI    *api.SigningAPI
+	blocklist     *analytics.IPBlocklist
 	wsManager     *api.WebSocketManager
+	statusHub     *api.StatusHub
 	nostrClient   nostr.Client
+	rtmpStatus    api.RTMPStatusProvider // nil when RTMP ingest is disabled
+
+	// Reverse proxies whose forwarded client IP headers are believed (access.trusted_proxies)
+	trustedProxies []netip.Prefix
+
+	// Segment files already reported as missing or empty by the playlist check
+	brokenSegments sync.Map
 }
 
-// NewServer creates a new web server instance
-func NewServer(cfg *config.Config, monitor *stream.Monitor) *Server {
+// NewServer creates a new web server instance. rtmpServer may be nil when RTMP ingest is
+// disabled; the RTMP status and control endpoints then report it as unavailable.
+func NewServer(cfg *config.Config, monitor *stream.Monitor, rtmpServer *rtmp.Server) *Server {
 	// Note: Grain client initialization is now handled by our NostrClient
 	// to avoid conflicts with subscription management
 
@@ -42,16 +66,59 @@ func NewServer(cfg *config.Config, monitor *stream.Monitor) *Server {
 	// Initialize WebSocket manager
 	wsManager := api.NewWebSocketManager(cfg, monitor, nostrClient)
 
+	trustedProxies, err := cfg.Access.TrustedProxyRanges()
+	if err != nil {
+		log.Printf("⚠️ Ignoring invalid access.trusted_proxies entries: %v", err)
+	}
+
+	// Without access.token_secret, tokens are signed with a key kept in the data directory
+	if cfg.Access.RequireToken {
+		if err := cfg.LoadTokenSecret(); err != nil {
+			log.Printf("⚠️ %v - access tokens will stop working on restart", err)
+		}
+	}
+
+	viewerTracker := analytics.NewViewerTracker(trustedProxies)
+
+	// Blocked viewer IPs are kept across restarts only when a file is configured
+	blocklist, err := analytics.NewIPBlocklist(cfg.Server.ViewerBlocklist)
+	if err != nil {
+		log.Printf("⚠️ %v - starting with an empty viewer blocklist", err)
+	}
+
+	// Keep the interfaces nil (not a typed nil pointer) without an RTMP server
+	var restarter api.FFmpegRestarter
+	var rtmpStatus api.RTMPStatusProvider
+	if rtmpServer != nil {
+		restarter = rtmpServer
+		rtmpStatus = rtmpServer
+	}
+
 	server := &Server{
 		config:        cfg,
 		monitor:       monitor,
-		viewerTracker: analytics.NewViewerTracker(),
+		viewerTracker: viewerTracker,
 		authAPI:       api.NewAuthAPI(cfg),
+		archiveAPI:    api.NewArchiveAPI(cfg, nostrClient),
+		controlAPI:    api.NewStreamControlAPI(cfg, nostrClient, restarter),
+		streamsAPI:    api.NewStreamsAPI(cfg, rtmpStatus),
+		viewersAPI:    api.NewViewersAPI(cfg, viewerTracker, blocklist),
+		accessAPI:     api.NewAccessAPI(cfg),
+		signingAPI:    api.NewSigningAPI(cfg, nostrClient),
+		blocklist:     blocklist,
 		chatAPI:       api.NewChatAPI(cfg, nostrClient, monitor, wsManager),
 		wsManager:     wsManager,
+		statusHub:     api.NewStatusHub(monitor, viewerTracker.GetActiveViewerCount),
 		nostrClient:   nostrClient,
+		rtmpStatus:    rtmpStatus,
+
+		trustedProxies: trustedProxies,
 	}
 
+	// Push stream status changes to /ws/status clients
+	monitor.OnStatusChange(server.statusHub.BroadcastStatus)
+	go server.statusHub.Run()
+
 	// Start WebSocket manager
 	go wsManager.Run()
 
@@ -64,6 +131,17 @@ func NewServer(cfg *config.Config, monitor *stream.Monitor) *Server {
 	return server
 }
 
+// Shutdown closes WebSocket clients and stops viewer tracking, logging the final viewer stats
+func (s *Server) Shutdown() {
+	s.wsManager.CloseAll()
+	s.statusHub.CloseAll()
+
+	metrics := s.viewerTracker.GetMetrics()
+	s.viewerTracker.Stop()
+	log.Printf("📊 Final viewer stats: %d active, %d peak, %d sessions",
+		metrics.ActiveViewers, metrics.PeakViewers, len(metrics.Sessions))
+}
+
 // Router sets up HTTP routes
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
@@ -77,18 +155,41 @@ func (s *Server) Router() http.Handler {
 	streamDefaults := s.config.GetStreamDefaults()
 
 	// HLS streaming files (with CORS and viewer tracking)
-	mux.Handle("/live/", http.StripPrefix("/live/", s.hlsTrackingHandler(http.FileServer(http.Dir(streamDefaults.OutputDir)))))
-	mux.Handle("/archive/", http.StripPrefix("/archive/", s.hlsTrackingHandler(http.FileServer(http.Dir(streamDefaults.ArchiveDir)))))
+	mux.Handle("/live/", s.safePathHandler(http.StripPrefix("/live/", s.offlinePlaceholderHandler(s.hlsTrackingHandler(s.cacheControlHandler(false, s.playlistIntegrityHandler(streamDefaults.OutputDir, s.lowLatencyPlaylistHandler(streamDefaults.OutputDir, http.FileServer(http.Dir(streamDefaults.OutputDir))))))))))
+	if s.config.Stream.Offline.Enabled() {
+		mux.Handle(offlineRoute, s.safePathHandler(http.StripPrefix(offlineRoute, s.corsHandler(s.cacheControlHandler(false, http.FileServer(http.Dir(streamDefaults.OfflineDir)))))))
+	}
+	archiveFiles := s.hlsTrackingHandler(s.cacheControlHandler(true, http.FileServer(http.Dir(streamDefaults.ArchiveDir))))
+	mux.Handle(streamDefaults.ArchiveRoute, s.safePathHandler(http.StripPrefix(streamDefaults.ArchiveRoute, archiveFiles)))
+	// /archive/{date-dtag} is the watch page; deeper paths still serve files for recording URLs
+	// published before archives moved under /media/archive/
+	mux.Handle(legacyArchiveRoute, s.safePathHandler(s.watchPageHandler(http.StripPrefix(legacyArchiveRoute, archiveFiles))))
 
 	// API endpoints (with CORS)
 	mux.HandleFunc("/api/stream-data", s.corsWrapper(s.handleStreamData))
 	mux.HandleFunc("/api/health", s.corsWrapper(s.handleHealth))
+	mux.HandleFunc("/api/ready", s.corsWrapper(s.handleReady))
+	mux.HandleFunc("/api/stream-health", s.corsWrapper(s.handleStreamHealth))
 	mux.HandleFunc("/api/viewers", s.corsWrapper(s.handleViewerMetrics))
+	mux.HandleFunc("/api/viewers/", s.corsWrapper(s.viewersAPI.HandleViewer))
+	mux.HandleFunc("/api/access/token", s.corsWrapper(s.accessAPI.HandleToken))
+	mux.HandleFunc("/api/nostr/pending-events", s.corsWrapper(s.signingAPI.HandlePendingEvents))
+	mux.HandleFunc("/api/stream/share", s.corsWrapper(s.handleStreamShare))
+	mux.HandleFunc("/api/stream/host-profile", s.corsWrapper(s.authAPI.HandleHostProfile))
+	mux.HandleFunc("/api/archives", s.corsWrapper(s.archiveAPI.HandleArchives))
+	mux.HandleFunc("/api/archives/", s.corsWrapper(s.archiveAPI.HandleArchive))
+	mux.HandleFunc("/api/stream/restart-ffmpeg", s.corsWrapper(s.controlAPI.HandleRestartFFmpeg))
+	mux.HandleFunc("/api/stream/planned", s.corsWrapper(s.controlAPI.HandlePlannedStream))
+	mux.HandleFunc("/api/nostr/test", s.corsWrapper(s.controlAPI.HandleNostrTest))
+	mux.HandleFunc("/api/nostr/last-event", s.corsWrapper(s.controlAPI.HandleLastEvent))
+	mux.HandleFunc("/api/streams", s.corsWrapper(s.streamsAPI.HandleStreams))
+	mux.HandleFunc("/api/rtmp/status", s.corsWrapper(s.streamsAPI.HandleRTMPStatus))
 	
 	// Authentication API endpoints
 	mux.HandleFunc("/api/auth/login", s.corsWrapper(s.authAPI.HandleLogin))
 	mux.HandleFunc("/api/auth/logout", s.corsWrapper(s.authAPI.HandleLogout))
 	mux.HandleFunc("/api/auth/session", s.corsWrapper(s.authAPI.HandleSession))
+	mux.HandleFunc("/api/auth/identity", s.corsWrapper(s.authAPI.HandleIdentity))
 	mux.HandleFunc("/api/auth/generate-keys", s.corsWrapper(s.authAPI.HandleGenerateKeys))
 	mux.HandleFunc("/api/auth/connect-relay", s.corsWrapper(s.authAPI.HandleConnectRelay))
 	mux.HandleFunc("/api/auth/amber-callback", s.corsWrapper(s.authAPI.HandleAmberCallback))
@@ -96,7 +197,10 @@ func (s *Server) Router() http.Handler {
 	// Chat API endpoints
 	mux.HandleFunc("/api/chat/messages", s.corsWrapper(s.chatAPI.HandleGetMessages))
 	mux.HandleFunc("/api/chat/send", s.corsWrapper(s.chatAPI.HandleSendMessage))
+	mux.HandleFunc("/api/chat/settings", s.corsWrapper(s.chatAPI.HandleChatSettings))
+	mux.HandleFunc("/api/chat/bans", s.corsWrapper(s.chatAPI.HandleChatBans))
 	mux.HandleFunc("/api/chat/ws", s.wsManager.HandleWebSocket) // WebSocket endpoint
+	mux.HandleFunc("/ws/status", s.statusHub.HandleWebSocket)    // Stream status push
 
 
 	// Web pages with HTMX routing (with CORS)
@@ -105,7 +209,7 @@ func (s *Server) Router() http.Handler {
 	mux.HandleFunc("/widgets", s.corsWrapper(s.handleWidgets))
 	
 
-	return mux
+	return s.accessHandler(s.tokenHandler(mux))
 }
 
 // cssHandler ensures CSS files are served with correct MIME type
@@ -119,11 +223,66 @@ func (s *Server) cssHandler(next http.Handler) http.Handler {
 	})
 }
 
-// corsHandler adds CORS headers for streaming files
+// hlsContentTypes maps HLS file extensions to their MIME types
+var hlsContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".m4s":  "video/iso.segment",
+	".mp4":  "video/mp4",
+}
+
+// legacyArchiveRoute is where archive files were served before /media/archive/; it now hosts
+// the per-recording watch pages
+const legacyArchiveRoute = "/archive/"
+
+// archivedPlaylistMaxAge is how long (seconds) an archived playlist may be cached; it is kept
+// short because an archive can still be finalized or renamed shortly after the stream ends
+const archivedPlaylistMaxAge = 300
+
+// cacheControlHandler sets Cache-Control for HLS files when server.cache_headers is on: live
+// playlists are never reused without revalidation, live segments are cached briefly (their
+// names repeat between streams) and archived segments are cached for good. The archive folder
+// is also reachable under /live/, so it is recognized there too.
+func (s *Server) cacheControlHandler(archived bool, next http.Handler) http.Handler {
+	streamDefaults := s.config.GetStreamDefaults()
+	archivePrefix := ""
+	if rel, err := filepath.Rel(streamDefaults.OutputDir, streamDefaults.ArchiveDir); err == nil && !strings.HasPrefix(rel, "..") {
+		archivePrefix = filepath.ToSlash(rel) + "/"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config.Server.CacheHeaders {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ext := strings.ToLower(filepath.Ext(r.URL.Path))
+		if _, ok := hlsContentTypes[ext]; ok {
+			isArchive := archived || (archivePrefix != "" && strings.HasPrefix(strings.TrimPrefix(r.URL.Path, "/"), archivePrefix))
+			switch {
+			case isArchive && ext == ".m3u8":
+				w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", archivedPlaylistMaxAge))
+			case isArchive:
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			case ext == ".m3u8":
+				w.Header().Set("Cache-Control", "no-cache")
+			default:
+				// About one segment: long enough to absorb a burst of viewers, short enough
+				// that a new stream's reused segment names aren't served stale
+				w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", s.config.GetHLSConfig().SegmentTime))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsHandler adds CORS headers and MIME types for streaming files
 func (s *Server) corsHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Only set CORS for HLS streaming files, not all static resources
-		if strings.HasSuffix(r.URL.Path, ".m3u8") || strings.HasSuffix(r.URL.Path, ".ts") {
+		if contentType, ok := hlsContentTypes[strings.ToLower(filepath.Ext(r.URL.Path))]; ok {
+			w.Header().Set("Content-Type", contentType)
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
@@ -144,7 +303,7 @@ func (s *Server) corsWrapper(next http.HandlerFunc) http.HandlerFunc {
 		// Only set CORS for API endpoints
 		if strings.HasPrefix(r.URL.Path, "/api/") {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
 		}
 
@@ -157,11 +316,52 @@ func (s *Server) corsWrapper(next http.HandlerFunc) http.HandlerFunc {
 	})
 }
 
+// safePathHandler rejects file requests whose path could escape the served directory
+func (s *Server) safePathHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isSafeRequestPath(r.URL) {
+			log.Printf("🚫 Rejected unsafe path: %s from %s", r.URL.EscapedPath(), r.RemoteAddr)
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isSafeRequestPath checks both the decoded and raw (possibly percent-encoded) path for
+// traversal segments, backslashes and NUL bytes
+func isSafeRequestPath(u *url.URL) bool {
+	raw := u.EscapedPath()
+	decoded, err := url.PathUnescape(raw)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range []string{u.Path, decoded} {
+		if strings.ContainsAny(p, "\\\x00") {
+			return false
+		}
+		for _, segment := range strings.Split(p, "/") {
+			if segment == ".." {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 // hlsTrackingHandler wraps file serving with HLS viewer tracking
 func (s *Server) hlsTrackingHandler(next http.Handler) http.Handler {
 	return s.corsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Track HLS requests
 		if analytics.IsHLSRequest(r) {
+			// Blocked viewers are refused before they count as viewers
+			if s.blocklist.IsBlocked(s.getClientIP(r)) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
 			s.viewerTracker.TrackRequest(r)
 			// Only log playlist requests (.m3u8), not individual segments (.ts)
 			if strings.HasSuffix(r.URL.Path, ".m3u8") {
@@ -170,30 +370,55 @@ func (s *Server) hlsTrackingHandler(next http.Handler) http.Handler {
 					s.getClientIP(r),
 					s.viewerTracker.GetActiveViewerCount())
 			}
+
+			// Count the bytes actually served for bandwidth
+			counter := &byteCountingWriter{ResponseWriter: w}
+			next.ServeHTTP(counter, r)
+			s.viewerTracker.TrackSegmentBytes(r, counter.bytes)
+			return
 		}
 		
 		next.ServeHTTP(w, r)
 	}))
 }
 
-// getClientIP extracts the real client IP (duplicate from analytics, but needed here)
+// byteCountingWriter counts the body bytes written to a response
+type byteCountingWriter struct {
+	http.ResponseWriter
+	bytes int64
+}
+
+func (w *byteCountingWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// getClientIP returns the client IP, believing forwarded headers only from access.trusted_proxies
 func (s *Server) getClientIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-	ip := r.RemoteAddr
-	if colon := strings.LastIndex(ip, ":"); colon != -1 {
-		ip = ip[:colon]
-	}
-	return ip
+	return analytics.ClientIP(r, s.trustedProxies)
 }
 
 // loadTemplates loads HTML templates with your structure
 func (s *Server) loadTemplates() {
+	templates, count, err := parseTemplates()
+	if err != nil {
+		log.Fatalf("Error parsing templates: %v", err)
+		return
+	}
+
+	s.templatesMux.Lock()
+	s.templates = templates
+	s.templatesMux.Unlock()
+	log.Printf("Loaded %d template files", count)
+
+	if s.config.Server.DevMode {
+		log.Println("🛠️ Dev mode enabled - templates will be re-parsed on every request")
+	}
+}
+
+// parseTemplates globs and parses all template files
+func parseTemplates() (*template.Template, int, error) {
 	// Define template directories
 	templatePaths := []string{
 		"www/views/templates/*.html",  // layout, header, footer
@@ -212,22 +437,38 @@ func (s *Server) loadTemplates() {
 	}
 
 	if len(allFiles) == 0 {
-		log.Fatal("No template files found. Please create templates in www/views/")
-		return
+		return nil, 0, fmt.Errorf("no template files found, please create templates in www/views/")
 	}
 
 	// Parse all template files
 	templates, err := template.New("").Funcs(template.FuncMap{
 		"upper": strings.ToUpper,
 	}).ParseFiles(allFiles...)
-
 	if err != nil {
-		log.Fatalf("Error parsing templates: %v", err)
-		return
+		return nil, 0, err
 	}
 
-	s.templates = templates
-	log.Printf("Loaded %d template files", len(allFiles))
+	return templates, len(allFiles), nil
+}
+
+// executeTemplate renders a named template, re-parsing templates first in dev mode
+func (s *Server) executeTemplate(w http.ResponseWriter, name string, data interface{}) error {
+	if s.config.Server.DevMode {
+		// Keep serving the last good templates if the edited ones don't parse
+		if templates, _, err := parseTemplates(); err != nil {
+			log.Printf("Template reload error: %v", err)
+		} else {
+			s.templatesMux.Lock()
+			s.templates = templates
+			s.templatesMux.Unlock()
+		}
+	}
+
+	s.templatesMux.RLock()
+	templates := s.templates
+	s.templatesMux.RUnlock()
+
+	return templates.ExecuteTemplate(w, name, data)
 }
 
 // handleLive serves the live streaming page
@@ -253,14 +494,14 @@ func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
 	// Check if this is an HTMX request for partial content
 	if r.Header.Get("HX-Request") == "true" {
 		// Return only the content part
-		if err := s.templates.ExecuteTemplate(w, "live-view", data); err != nil {
+		if err := s.executeTemplate(w, "live-view", data); err != nil {
 			log.Printf("Template error: %v", err)
 			http.Error(w, "Template error", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Return full layout
-		if err := s.templates.ExecuteTemplate(w, "layout", data); err != nil {
+		if err := s.executeTemplate(w, "layout", data); err != nil {
 			log.Printf("Template error: %v", err)
 			http.Error(w, "Template error", http.StatusInternalServerError)
 			return
@@ -289,14 +530,82 @@ func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
 	// Check if this is an HTMX request for partial content
 	if r.Header.Get("HX-Request") == "true" {
 		// Return only the content part
-		if err := s.templates.ExecuteTemplate(w, "archive-view", data); err != nil {
+		if err := s.executeTemplate(w, "archive-view", data); err != nil {
 			log.Printf("Template error: %v", err)
 			http.Error(w, "Template error", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Return full layout
-		if err := s.templates.ExecuteTemplate(w, "layout", data); err != nil {
+		if err := s.executeTemplate(w, "layout", data); err != nil {
+			log.Printf("Template error: %v", err)
+			http.Error(w, "Template error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// watchPageHandler serves the watch page for /archive/{date-dtag} and hands every other path to next
+func (s *Server) watchPageHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, legacyArchiveRoute), "/")
+		if !util.IsArchiveName(name) || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s.handleWatch(w, r, name)
+	})
+}
+
+// handleWatch serves the player page for a single archived stream
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request, name string) {
+	streamDefaults := s.config.GetStreamDefaults()
+	archiveDir := filepath.Join(streamDefaults.ArchiveDir, name)
+	if _, err := os.Stat(filepath.Join(archiveDir, "output.m3u8")); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	date, _, _ := util.ParseArchiveName(name)
+	metadata, err := config.LoadStreamMetadata(filepath.Join(archiveDir, config.MetadataFileName))
+	if err != nil {
+		// Older or hand-copied archives may lack metadata; the recording still plays
+		metadata = &config.StreamMetadata{Title: name}
+	}
+
+	data := struct {
+		Title       string
+		Summary     string
+		Tags        []string
+		Status      string
+		View        string
+		Name        string
+		Date        string
+		PlaylistURL string
+		DownloadURL string
+	}{
+		Title:       metadata.Title,
+		Summary:     metadata.Summary,
+		Tags:        metadata.Tags,
+		Status:      "archive",
+		View:        "watch-view",
+		Name:        name,
+		Date:        date.Format("January 2, 2006"),
+		PlaylistURL: streamDefaults.ArchiveRoute + name + "/output.m3u8",
+		DownloadURL: "/api/archives/" + name + "/download",
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	// Check if this is an HTMX request for partial content
+	if r.Header.Get("HX-Request") == "true" {
+		if err := s.executeTemplate(w, "watch-view", data); err != nil {
+			log.Printf("Template error: %v", err)
+			http.Error(w, "Template error", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		if err := s.executeTemplate(w, "layout", data); err != nil {
 			log.Printf("Template error: %v", err)
 			http.Error(w, "Template error", http.StatusInternalServerError)
 			return
@@ -314,15 +623,62 @@ func (s *Server) handleStreamData(w http.ResponseWriter, r *http.Request) {
 		"metadata":       metadata,
 		"active_viewers": viewerCount,
 	}
+	if s.wsManager != nil {
+		response["chat_settings"] = s.wsManager.GetChatSettings()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Error encoding JSON: %v", err)
-		http.Error(w, "JSON encoding error", http.StatusInternalServerError)
+		api.WriteAPIError(w, http.StatusInternalServerError, api.ErrCodeInternal, "JSON encoding error")
 		return
 	}
 }
 
+// handleStreamShare serves NIP-19 naddr/nevent links for the current live event, with the configured relays as hints
+func (s *Server) handleStreamShare(w http.ResponseWriter, r *http.Request) {
+	metadata := s.monitor.GetCurrentMetadata()
+
+	pubkey := metadata.Pubkey
+	if pubkey == "" {
+		pubkey = s.config.Nostr.PublicKey
+	}
+
+	if metadata.Dtag == "" || pubkey == "" {
+		api.WriteAPIError(w, http.StatusNotFound, api.ErrCodeNotLive, "No stream event to share")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	relays := s.config.Nostr.WriteRelayList()
+	response := map[string]interface{}{
+		"success": true,
+		"status":  metadata.Status,
+	}
+
+	naddr, err := nostr.EncodeNaddr(30311, pubkey, metadata.Dtag, relays)
+	if err != nil {
+		log.Printf("Error encoding naddr: %v", err)
+	} else {
+		response["naddr"] = naddr
+		response["nostr_uri"] = "nostr:" + naddr
+	}
+
+	// The nevent points at the latest published revision of the event
+	if metadata.LastNostrEvent != "" {
+		if eventID, err := nostr.ExtractEventID(metadata.LastNostrEvent); err == nil && eventID != "" {
+			if nevent, err := nostr.EncodeNevent(eventID, pubkey, 30311, relays); err == nil {
+				response["nevent"] = nevent
+			}
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding share JSON: %v", err)
+	}
+}
+
 // handleHealth serves health check endpoint
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	status := "healthy"
@@ -335,22 +691,111 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"active": s.monitor.IsActive(),
 	}
 
+	// An encoder that connected but hasn't produced video yet shows as connecting, so a
+	// stuck OBS can be told apart from one that never reached the server
+	if status == "offline" && s.rtmpStatus != nil {
+		for _, stream := range s.rtmpStatus.Status().Streams {
+			if stream.ConnectedSince != nil && stream.PublishingSince == nil {
+				response["status"] = "connecting"
+				response["connection"] = map[string]interface{}{
+					"connected_since": stream.ConnectedSince,
+					"remote_addr":     stream.RemoteAddr,
+				}
+				break
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Error encoding health JSON: %v", err)
-		http.Error(w, "JSON encoding error", http.StatusInternalServerError)
+		api.WriteAPIError(w, http.StatusInternalServerError, api.ErrCodeInternal, "JSON encoding error")
+		return
+	}
+}
+
+// handleReady serves the readiness check: 200 once the server can handle requests, whether
+// or not a stream is live (liveness of the stream itself is /api/health)
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	s.templatesMux.RLock()
+	templatesLoaded := s.templates != nil
+	s.templatesMux.RUnlock()
+
+	// NewServer only returns after the Nostr client's initial relay connect attempt
+	relaysConfigured := len(s.config.Nostr.AllRelays())
+	relaysConnected := 0
+	nostrEnabled := s.nostrClient != nil && s.nostrClient.IsEnabled()
+	if nostrEnabled {
+		relaysConnected = len(s.nostrClient.GetConnectedRelays())
+	}
+
+	ready := templatesLoaded && s.config != nil
+	response := map[string]interface{}{
+		"ready": ready,
+		"checks": map[string]interface{}{
+			"templates_loaded":  templatesLoaded,
+			"config_loaded":     s.config != nil,
+			"relays_attempted":  true,
+			"nostr_enabled":     nostrEnabled,
+			"relays_connected":  relaysConnected,
+			"relays_configured": relaysConfigured,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding ready JSON: %v", err)
+	}
+}
+
+// handleStreamHealth serves the input probe result for the connected stream
+func (s *Server) handleStreamHealth(w http.ResponseWriter, r *http.Request) {
+	active := s.monitor.IsActive()
+	input := s.monitor.GetInputHealth()
+
+	status := "offline"
+	switch {
+	case active && input == nil && s.config.RTMP.ValidateInput:
+		status = "checking"
+	case active && input == nil:
+		status = "live" // Input validation disabled
+	case active && len(input.Warnings) > 0:
+		status = "degraded"
+	case active:
+		status = "healthy"
+	}
+
+	response := map[string]interface{}{
+		"status": status,
+		"active": active,
+		"input":  input,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding stream health JSON: %v", err)
+		api.WriteAPIError(w, http.StatusInternalServerError, api.ErrCodeInternal, "JSON encoding error")
 		return
 	}
 }
 
 // handleViewerMetrics serves viewer analytics data
 func (s *Server) handleViewerMetrics(w http.ResponseWriter, r *http.Request) {
-	metrics := s.viewerTracker.GetMetrics()
+	response := struct {
+		analytics.ViewerMetrics
+		FilteredMessages int64 `json:"filtered_messages"` // Chat messages dropped or masked by the chat filter
+	}{
+		ViewerMetrics:    s.viewerTracker.GetMetrics(),
+		FilteredMessages: s.wsManager.FilteredMessageCount(),
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(metrics); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Error encoding viewer metrics JSON: %v", err)
-		http.Error(w, "JSON encoding error", http.StatusInternalServerError)
+		api.WriteAPIError(w, http.StatusInternalServerError, api.ErrCodeInternal, "JSON encoding error")
 		return
 	}
 }
@@ -376,14 +821,14 @@ func (s *Server) handleWidgets(w http.ResponseWriter, r *http.Request) {
 	// Check if this is an HTMX request for partial content
 	if r.Header.Get("HX-Request") == "true" {
 		// Return only the content part
-		if err := s.templates.ExecuteTemplate(w, "widgets-view", data); err != nil {
+		if err := s.executeTemplate(w, "widgets-view", data); err != nil {
 			log.Printf("Template error: %v", err)
 			http.Error(w, "Template error", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Return full layout
-		if err := s.templates.ExecuteTemplate(w, "layout", data); err != nil {
+		if err := s.executeTemplate(w, "layout", data); err != nil {
 			log.Printf("Template error: %v", err)
 			http.Error(w, "Template error", http.StatusInternalServerError)
 			return
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSafePathHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"plain file", "/live/output.m3u8", http.StatusOK},
+		{"archive file", "/archive/1-2-2026-stream/output.m3u8", http.StatusOK},
+		{"dots in a name", "/live/segment..ts", http.StatusOK},
+		{"parent segment", "/live/../config.yml", http.StatusBadRequest},
+		{"nested parent segment", "/archive/1-2-2026-stream/../../config.yml", http.StatusBadRequest},
+		{"encoded slash", "/live/..%2fconfig.yml", http.StatusBadRequest},
+		{"encoded dots", "/live/%2e%2e/config.yml", http.StatusBadRequest},
+		{"fully encoded", "/live/%2e%2e%2f%2e%2e%2fetc%2fpasswd", http.StatusBadRequest},
+		{"backslash", "/live/..%5cconfig.yml", http.StatusBadRequest},
+		{"NUL byte", "/live/output.m3u8%00.ts", http.StatusBadRequest},
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := (&Server{}).safePathHandler(ok)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com"+tt.path, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -21,7 +21,7 @@ window.loadArchive = window.loadArchive || async function() {
     const emptyEl = document.getElementById('archiveEmpty');
     
     try {
-        const response = await fetch('/archive/');
+        const response = await fetch('/media/archive/');
         const html = await response.text();
         
         const parser = new DOMParser();
@@ -42,7 +42,7 @@ window.loadArchive = window.loadArchive || async function() {
             links.map(async (folder) => {
                 try {
                     const folderPath = folder.replace(/\/$/, '');
-                    const metaResponse = await fetch(`/archive/${folderPath}/metadata.json`);
+                    const metaResponse = await fetch(`/media/archive/${folderPath}/metadata.json`);
                     if (!metaResponse.ok) throw new Error('No metadata');
                     
                     const metadata = await metaResponse.json();
@@ -150,6 +150,9 @@ window.openStreamModal = window.openStreamModal || function(folderPath) {
     if (date) date.textContent = new Date(parseInt(stream.starts) * 1000).toLocaleDateString();
     if (summary) summary.textContent = stream.summary || 'No description available';
     
+    const watchLink = document.getElementById('modalWatchLink');
+    if (watchLink) watchLink.href = `/archive/${stream.folderPath}`;
+    
     if (tags && stream.tags) {
         tags.innerHTML = stream.tags.map(tag => 
             `<span class="neon-border text-green-400 px-3 py-1 text-sm rounded font-mono">#${tag}</span>`
@@ -452,7 +452,8 @@ async function connectReadOnly() {
         let validatedPubkey = '';
         
         if (pubkey) {
-            if (pubkey.startsWith('npub') && pubkey.length === 63) {
+            // npub/nprofile are decoded to hex by the server
+            if ((pubkey.startsWith('npub1') && pubkey.length === 63) || pubkey.startsWith('nprofile1')) {
                 validatedPubkey = pubkey;
             } else if (/^[0-9a-fA-F]{64}$/.test(pubkey)) {
                 validatedPubkey = pubkey;
@@ -637,6 +638,7 @@ async function checkExistingSession() {
             window.userSession = result; // Expose full session response (includes is_owner)
             isAuthenticated = true;
             updateLoginButton();
+            startStreamEventSigning();
             console.log('🔑 Existing session found:', result.session.public_key);
             if (userProfile) {
                 console.log('🔑 Profile loaded:', userProfile.name || userProfile.display_name || 'Unknown');
@@ -662,6 +664,48 @@ async function checkExistingSession() {
     }
 }
 
+// Sign stream events queued by the server (nostr.event_signer: browser) with the owner's extension
+let streamSigningTimer = null;
+
+function startStreamEventSigning() {
+    if (streamSigningTimer || !window.userSession?.is_owner || !window.nostr) {
+        return;
+    }
+    if (currentSession?.signing_method !== 'browser_extension') {
+        return;
+    }
+    streamSigningTimer = setInterval(signPendingStreamEvents, 5000);
+    signPendingStreamEvents();
+}
+
+function stopStreamEventSigning() {
+    clearInterval(streamSigningTimer);
+    streamSigningTimer = null;
+}
+
+async function signPendingStreamEvents() {
+    try {
+        const response = await fetch('/api/nostr/pending-events');
+        if (!response.ok) {
+            // Browser signing isn't enabled (or we're no longer the owner)
+            stopStreamEventSigning();
+            return;
+        }
+        const result = await response.json();
+        for (const event of result.events || []) {
+            const signed = await window.nostr.signEvent(event);
+            await fetch('/api/nostr/pending-events', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ event: signed })
+            });
+            console.log('✍️ Signed stream event:', signed.id);
+        }
+    } catch (error) {
+        console.log('❌ Stream event signing failed:', error);
+    }
+}
+
 async function logout() {
     try {
         const response = await fetch('/api/auth/logout', {
@@ -672,6 +716,7 @@ async function logout() {
         const result = await response.json();
         
         if (result.success) {
+            stopStreamEventSigning();
             currentSession = null;
             userProfile = null;
             window.userProfile = null; // Update global reference
@@ -296,6 +296,7 @@ class LiveChatWidget {
                     </div>
                     ${isReply ? '<div class="text-xs text-blue-400 mb-1">↳ Reply</div>' : ''}
                     <div class="message-content text-sm">${this.formatMessageContent(message.content)}</div>
+                    <div class="message-reactions text-xs text-gray-400 mt-1">${this.formatReactions(message.reactions)}</div>
                 </div>
             </div>
         `;
@@ -303,6 +304,13 @@ class LiveChatWidget {
         return messageDiv;
     }
 
+    formatReactions(reactions) {
+        if (!reactions) return '';
+        return Object.entries(reactions)
+            .map(([content, count]) => `<span class="mr-2">${this.formatMessageContent(content === '+' ? '👍' : content)} ${count}</span>`)
+            .join('');
+    }
+
     getUserDisplayName(message) {
         if (message.profile) {
             return message.profile.display_name ||
@@ -466,7 +474,11 @@ class LiveChatWidget {
             this.ws.onmessage = (event) => {
                 try {
                     const message = JSON.parse(event.data);
-                    this.handleNewMessage(message);
+                    if (message.type === 'reaction') {
+                        this.handleReaction(message);
+                    } else {
+                        this.handleNewMessage(message);
+                    }
                 } catch (error) {
                     console.error('❌ Error parsing WebSocket message:', error);
                 }
@@ -542,6 +554,18 @@ class LiveChatWidget {
         }
     }
 
+    handleReaction(reaction) {
+        const message = this.messages.find(m => m.id === reaction.target_id);
+        if (!message) return;
+
+        message.reactions = reaction.reactions;
+
+        const messageEl = document.querySelector(`[data-message-id="${reaction.target_id}"] .message-reactions`);
+        if (messageEl) {
+            messageEl.innerHTML = this.formatReactions(message.reactions);
+        }
+    }
+
     destroy() {
         if (this.ws) {
             this.ws.close();
@@ -3,12 +3,15 @@ window.streamVideo = window.streamVideo || null;
 window.streamHls = window.streamHls || null;
 window.currentStatus = window.currentStatus || 'offline';
 window.updateInterval = window.updateInterval || null;
+window.statusSocket = window.statusSocket || null;
+window.lastViewerCount = window.lastViewerCount || 0;
 
 // Initialize when DOM loads OR when HTMX content is swapped in
 document.addEventListener('DOMContentLoaded', function() {
     window.initializeStream();
     window.startStatusUpdates();
     window.loadHomepagePastStreams();
+    window.loadHostProfile();
 });
 
 // Also initialize when HTMX swaps in new content (for SPA navigation)
@@ -18,6 +21,7 @@ document.addEventListener('htmx:afterSettle', function(evt) {
         window.initializeStream();
         window.startStatusUpdates();
         window.loadHomepagePastStreams();
+        window.loadHostProfile();
     }
 });
 
@@ -32,8 +36,34 @@ window.initializeStream = window.initializeStream || function() {
 window.startStatusUpdates = window.startStatusUpdates || function() {
     if (window.updateInterval) {
         clearInterval(window.updateInterval);
+        window.updateInterval = null;
     }
-    window.updateInterval = setInterval(window.updateStreamData, 10000); // Every 10 seconds
+    if (window.statusSocket && window.statusSocket.readyState <= WebSocket.OPEN) {
+        return; // Already connected
+    }
+
+    // Status changes are pushed over /ws/status; fall back to polling if the socket drops
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const socket = new WebSocket(`${protocol}//${window.location.host}/ws/status`);
+    window.statusSocket = socket;
+
+    socket.onmessage = function(event) {
+        const message = JSON.parse(event.data);
+        if (message.type === 'status') {
+            window.applyStreamData(message.metadata || {}, message.active_viewers || 0);
+        } else if (message.type === 'viewers') {
+            window.lastViewerCount = message.active_viewers || 0;
+            window.updateStatusDisplay(window.currentStatus, window.lastViewerCount);
+        }
+    };
+
+    socket.onclose = function() {
+        window.statusSocket = null;
+        if (!window.updateInterval) {
+            window.updateInterval = setInterval(window.updateStreamData, 10000); // Every 10 seconds
+        }
+        setTimeout(window.startStatusUpdates, 5000); // Try to reconnect
+    };
 }
 
 window.updateStreamData = window.updateStreamData || async function() {
@@ -45,26 +75,64 @@ window.updateStreamData = window.updateStreamData || async function() {
         const metadata = data.metadata || data;
         const viewerCount = data.active_viewers || 0;
         
-        const newStatus = metadata.status?.toLowerCase() || 'offline';
+        window.applyStreamData(metadata, viewerCount);
         
-        // Update status display
-        window.updateStatusDisplay(newStatus, viewerCount);
+    } catch (error) {
+        console.error('Failed to update stream data:', error);
+    }
+}
+
+window.applyStreamData = window.applyStreamData || function(metadata, viewerCount) {
+    const newStatus = metadata.status?.toLowerCase() || 'offline';
+    window.lastViewerCount = viewerCount;
+    
+    // Update status display
+    window.updateStatusDisplay(newStatus, viewerCount);
+    
+    // Update metadata
+    window.updateStreamInfo(metadata);
+    
+    // Handle status changes
+    if (newStatus !== window.currentStatus) {
+        console.log(`Status changed: ${window.currentStatus} -> ${newStatus}`);
+        window.currentStatus = newStatus;
+        
+        if (newStatus === 'live' && metadata.stream_url) {
+            window.loadStream(metadata.stream_url);
+        } else if (newStatus !== 'live') {
+            // Picks up the offline placeholder when one is configured
+            window.loadLiveStream();
+        }
+    }
+}
+
+window.loadHostProfile = window.loadHostProfile || async function() {
+    const container = document.getElementById('hostProfile');
+    if (!container) return;
+    
+    try {
+        const response = await fetch('/api/stream/host-profile');
+        if (!response.ok) return;
+        
+        const data = await response.json();
+        const profile = data.profile || {};
         
-        // Update metadata
-        window.updateStreamInfo(metadata);
+        const avatarEl = document.getElementById('hostAvatar');
+        const nameEl = document.getElementById('hostName');
+        const nip05El = document.getElementById('hostNip05');
         
-        // Handle status changes
-        if (newStatus !== window.currentStatus) {
-            console.log(`Status changed: ${window.currentStatus} -> ${newStatus}`);
-            window.currentStatus = newStatus;
-            
-            if (newStatus === 'live' && metadata.stream_url) {
-                window.loadStream(metadata.stream_url);
-            }
+        if (avatarEl && profile.picture) {
+            avatarEl.src = profile.picture;
+            avatarEl.alt = profile.display_name || profile.name || '';
+        } else if (avatarEl) {
+            avatarEl.classList.add('hidden');
         }
+        if (nameEl) nameEl.textContent = profile.display_name || profile.name || data.npub;
+        if (nip05El) nip05El.textContent = profile.nip05 || '';
         
+        container.classList.remove('hidden');
     } catch (error) {
-        console.error('Failed to update stream data:', error);
+        console.error('Failed to load host profile:', error);
     }
 }
 
@@ -164,7 +232,7 @@ window.loadPastStreams = window.loadPastStreams || async function() {
     if (emptyEl) emptyEl.classList.add('hidden');
     
     try {
-        const response = await fetch('/archive/');
+        const response = await fetch('/media/archive/');
         const html = await response.text();
         
         const parser = new DOMParser();
@@ -185,7 +253,7 @@ window.loadPastStreams = window.loadPastStreams || async function() {
             links.map(async (folder) => {
                 try {
                     const folderPath = folder.replace(/\/$/, '');
-                    const metaResponse = await fetch(`/archive/${folderPath}/metadata.json`);
+                    const metaResponse = await fetch(`/media/archive/${folderPath}/metadata.json`);
                     if (!metaResponse.ok) throw new Error('No metadata');
                     
                     const metadata = await metaResponse.json();
@@ -291,7 +359,7 @@ window.loadHomepagePastStreams = window.loadHomepagePastStreams || async functio
     
     try {
         // Same approach as archive.js - get directory listing
-        const response = await fetch('/archive/');
+        const response = await fetch('/media/archive/');
         const directoryHtml = await response.text();
         
         const parser = new DOMParser();
@@ -313,14 +381,14 @@ window.loadHomepagePastStreams = window.loadHomepagePastStreams || async functio
             recentLinks.map(async (folder) => {
                 try {
                     const folderPath = folder.replace(/\/$/, '');
-                    const metaResponse = await fetch(`/archive/${folderPath}/metadata.json`);
+                    const metaResponse = await fetch(`/media/archive/${folderPath}/metadata.json`);
                     if (!metaResponse.ok) throw new Error('No metadata');
                     
                     const metadata = await metaResponse.json();
                     return {
                         ...metadata,
                         folderPath: folderPath,
-                        recording_url: `/archive/${folderPath}/output.m3u8`
+                        recording_url: `/media/archive/${folderPath}/output.m3u8`
                     };
                 } catch (error) {
                     console.error(`Failed to load metadata for ${folder}:`, error);